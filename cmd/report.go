@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/tonhe/flo/internal/config"
+	"github.com/tonhe/flo/internal/history"
+	"github.com/tonhe/flo/tui/components"
+)
+
+func reportCmd(args []string) {
+	if len(args) < 1 {
+		printReportUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "billing":
+		reportBillingCmd(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown report: %s\n", args[0])
+		printReportUsage()
+		os.Exit(1)
+	}
+}
+
+func printReportUsage() {
+	fmt.Println(`Usage: flo report <report>
+
+Reports:
+  billing    95th percentile, peak and volume per interface`)
+}
+
+// billingRow is one interface's usage in a billing report.
+type billingRow struct {
+	Dashboard string  `json:"dashboard"`
+	Host      string  `json:"host"`
+	Interface string  `json:"interface"`
+	Samples   int     `json:"samples"`
+	Billable  float64 `json:"billable_95th_bps"`
+	In95      float64 `json:"in_95th_bps"`
+	Out95     float64 `json:"out_95th_bps"`
+	InAvg     float64 `json:"in_avg_bps"`
+	OutAvg    float64 `json:"out_avg_bps"`
+	InPeak    float64 `json:"in_peak_bps"`
+	OutPeak   float64 `json:"out_peak_bps"`
+	InBytes   float64 `json:"in_bytes"`
+	OutBytes  float64 `json:"out_bytes"`
+}
+
+func reportBillingCmd(args []string) {
+	fs := flag.NewFlagSet("report billing", flag.ExitOnError)
+	dashName := fs.String("dashboard", "", "Dashboard to report on (default: all)")
+	fromStr := fs.String("from", "", "First day of the period, YYYY-MM-DD (default: start of this month)")
+	toStr := fs.String("to", "", "Last day of the period, YYYY-MM-DD (default: today)")
+	format := fs.String("format", "table", "Output format: table, csv or json")
+
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: flo report billing [--dashboard NAME] [--from DATE] [--to DATE] [--format table|csv|json]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	period := history.Period{
+		From: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local),
+		To:   now,
+	}
+	if *fromStr != "" {
+		d, err := time.ParseInLocation(time.DateOnly, *fromStr, time.Local)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --from date %q\n", *fromStr)
+			os.Exit(1)
+		}
+		period.From = d
+	}
+	if *toStr != "" {
+		d, err := time.ParseInLocation(time.DateOnly, *toStr, time.Local)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --to date %q\n", *toStr)
+			os.Exit(1)
+		}
+		period.To = d.AddDate(0, 0, 1)
+	}
+	if !period.From.Before(period.To) {
+		fmt.Fprintln(os.Stderr, "Error: --from must be before --to")
+		os.Exit(1)
+	}
+	switch *format {
+	case "table", "csv", "json":
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown format %q\n", *format)
+		os.Exit(1)
+	}
+
+	dir, err := config.GetHistoryDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	store := history.NewStore(dir)
+
+	dashboards := []string{*dashName}
+	if *dashName == "" {
+		dashboards, err = store.Dashboards()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading history: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var rows []billingRow
+	for _, dash := range dashboards {
+		series, err := store.Series(dash)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading history: %v\n", err)
+			os.Exit(1)
+		}
+		for _, s := range series {
+			usage, err := store.Usage(dash, s, []history.Period{period})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading %s %s: %v\n", s.Host, s.Interface, err)
+				os.Exit(1)
+			}
+			u := usage[0]
+			if u.Samples == 0 {
+				continue
+			}
+			rows = append(rows, billingRow{
+				Dashboard: dash,
+				Host:      s.Host,
+				Interface: s.Interface,
+				Samples:   u.Samples,
+				Billable:  u.Billable(),
+				In95:      u.In95,
+				Out95:     u.Out95,
+				InAvg:     u.InAvg,
+				OutAvg:    u.OutAvg,
+				InPeak:    u.InPeak,
+				OutPeak:   u.OutPeak,
+				InBytes:   u.InBytes,
+				OutBytes:  u.OutBytes,
+			})
+		}
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if rows == nil {
+			rows = []billingRow{}
+		}
+		if err := enc.Encode(rows); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	case "csv":
+		writeBillingCSV(rows)
+	default:
+		writeBillingTable(rows, period)
+	}
+}
+
+// writeBillingCSV prints the report as CSV, with rates in bits/s and
+// volumes in bytes.
+func writeBillingCSV(rows []billingRow) {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"dashboard", "host", "interface", "samples", "billable_95th_bps",
+		"in_95th_bps", "out_95th_bps", "in_avg_bps", "out_avg_bps",
+		"in_peak_bps", "out_peak_bps", "in_bytes", "out_bytes"})
+	num := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	for _, r := range rows {
+		w.Write([]string{r.Dashboard, r.Host, r.Interface, strconv.Itoa(r.Samples), num(r.Billable),
+			num(r.In95), num(r.Out95), num(r.InAvg), num(r.OutAvg),
+			num(r.InPeak), num(r.OutPeak), num(r.InBytes), num(r.OutBytes)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// writeBillingTable prints the report as an aligned table.
+func writeBillingTable(rows []billingRow, period history.Period) {
+	fmt.Printf("Billing report %s to %s (95th percentile of %d-minute averages, bits/s)\n\n",
+		period.From.Format(time.DateOnly), period.To.Add(-time.Nanosecond).Format(time.DateOnly),
+		int(history.BucketWidth.Minutes()))
+	if len(rows) == 0 {
+		fmt.Println("No stored samples in this period.")
+		return
+	}
+	const line = "%-16s  %-18s  %-24s  %9s  %9s  %9s  %9s  %9s  %10s  %10s\n"
+	fmt.Printf(line, "Dashboard", "Host", "Interface", "95th", "95th In", "95th Out", "Peak In", "Peak Out", "Volume In", "Volume Out")
+	fmt.Printf(line, "---------", "----", "---------", "----", "-------", "--------", "-------", "--------", "---------", "----------")
+	for _, r := range rows {
+		fmt.Printf(line,
+			truncate(r.Dashboard, 16),
+			truncate(r.Host, 18),
+			truncate(r.Interface, 24),
+			components.FormatRate(r.Billable),
+			components.FormatRate(r.In95),
+			components.FormatRate(r.Out95),
+			components.FormatRate(r.InPeak),
+			components.FormatRate(r.OutPeak),
+			formatVolume(r.InBytes),
+			formatVolume(r.OutBytes),
+		)
+	}
+}
+
+// formatVolume formats a byte count with a decimal unit, as traffic volumes
+// are usually billed.
+func formatVolume(bytes float64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB", "PB"}
+	i := 0
+	for bytes >= 1000 && i < len(units)-1 {
+		bytes /= 1000
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%.0f %s", bytes, units[i])
+	}
+	return fmt.Sprintf("%.1f %s", bytes, units[i])
+}
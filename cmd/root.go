@@ -12,6 +12,8 @@ var knownSubcommands = map[string]bool{
 	"identity": true,
 	"discover": true,
 	"config":   true,
+	"report":   true,
+	"simulate": true,
 	"themes":   true,
 	"version":  true,
 	"help":     true,
@@ -35,6 +37,10 @@ func Execute(args []string) {
 		discoverCmd(args[1:])
 	case "config":
 		configCmd(args[1:])
+	case "report":
+		reportCmd(args[1:])
+	case "simulate":
+		simulateCmd(args[1:])
 	case "themes":
 		themesCmd()
 	case "version":
@@ -59,9 +65,13 @@ Usage:
   flo                       Launch TUI monitor
   flo --dashboard NAME      Launch with specific dashboard
   flo --theme NAME          Launch with theme override
+  flo --replay FILE [--speed N]
+                            Play back a recording, N times faster
   flo identity <cmd>        Manage SNMP identities
   flo discover HOST         Discover device interfaces
   flo config <cmd>          Manage configuration
+  flo report billing        Report 95th percentile and volume per interface
+  flo simulate              Run a simulated SNMP device on localhost:1161
   flo themes                List available themes
   flo version               Show version
   flo help                  Show this help
@@ -78,5 +88,18 @@ Discovery:
 Config Commands:
   flo config path                  Show config directory path
   flo config theme NAME            Set default theme
-  flo config identity NAME         Set default identity`)
+  flo config identity NAME         Set default identity
+
+Report Commands:
+  flo report billing [--dashboard NAME] [--from DATE] [--to DATE]
+                     [--format table|csv|json]
+                                   95th percentile, peak and volume per
+                                   interface from the stored history
+
+Simulator:
+  flo simulate [--walk FILE | --interfaces N] [--scenario FILE]
+               [--listen ADDR] [--community STR] [--profile NAME]
+               [--flap-every DUR] [--reboot-every DUR]
+                                   Serve a walk dump or a synthetic switch
+                                   with live counters, flaps and reboots`)
 }
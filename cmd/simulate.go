@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/tonhe/flo/internal/simulate"
+)
+
+func simulateCmd(args []string) {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	listen := fs.String("listen", simulate.DefaultAddr, "UDP address to answer SNMP on")
+	community := fs.String("community", "public", "SNMPv1/v2c community to accept")
+	walkFile := fs.String("walk", "", "snmpwalk -On dump to serve (default: a synthetic switch)")
+	interfaces := fs.Int("interfaces", 8, "Number of interfaces on the synthetic switch")
+	name := fs.String("name", "sim-switch", "sysName of the synthetic switch")
+	scenarioFile := fs.String("scenario", "", "TOML file describing traffic, flaps and reboots")
+	profile := fs.String("profile", "", fmt.Sprintf("Traffic profile: %s (default %s)", profileNames(), simulate.DefaultProfile))
+	utilization := fs.Float64("utilization", 0, fmt.Sprintf("Average utilization, 0 to 1 (default %v)", simulate.DefaultUtilization))
+	flapEvery := fs.String("flap-every", "", "Flap every interface this often, e.g. 5m")
+	flapDown := fs.String("flap-down", "", fmt.Sprintf("How long each flap lasts (default %v)", simulate.DefaultFlapDown))
+	rebootEvery := fs.String("reboot-every", "", "Reboot the device this often, e.g. 1h")
+	rebootDown := fs.String("reboot-down", "", fmt.Sprintf("How long each reboot keeps the agent silent (default %v)", simulate.DefaultRebootDown))
+	seed := fs.Int64("seed", 0, "Vary the traffic noise between simulated devices")
+
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: flo simulate [--walk FILE | --interfaces N] [--scenario FILE] [--listen ADDR] [options]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	scenario := &simulate.Scenario{}
+	if *scenarioFile != "" {
+		var err error
+		scenario, err = simulate.LoadScenario(*scenarioFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading scenario: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	// Flags override the scenario file's defaults.
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "profile":
+			scenario.Profile = simulate.Profile(*profile)
+		case "utilization":
+			scenario.Utilization = *utilization
+		case "flap-every":
+			scenario.FlapEveryStr = *flapEvery
+		case "flap-down":
+			scenario.FlapDownStr = *flapDown
+		case "reboot-every":
+			scenario.RebootEveryStr = *rebootEvery
+		case "reboot-down":
+			scenario.RebootDownStr = *rebootDown
+		case "seed":
+			scenario.Seed = *seed
+		}
+	})
+	if err := scenario.Normalize(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var pdus []gosnmp.SnmpPDU
+	if *walkFile != "" {
+		var err error
+		pdus, err = simulate.LoadWalk(*walkFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading walk: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		if *interfaces < 1 {
+			fmt.Fprintln(os.Stderr, "Error: --interfaces must be at least 1")
+			os.Exit(1)
+		}
+		pdus = simulate.Synthetic(*name, *interfaces)
+	}
+	device, err := simulate.NewDevice(pdus, *scenario, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	conn, err := net.ListenPacket("udp", *listen)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	host, port, _ := net.SplitHostPort(conn.LocalAddr().String())
+	names := device.InterfaceNames()
+	fmt.Printf("Simulating %d interfaces (%s) on %s\n", len(names), summarizeNames(names), conn.LocalAddr())
+	fmt.Printf("Traffic: %s at %.0f%% average utilization", scenario.Profile, scenario.Utilization*100)
+	if scenario.FlapEvery > 0 {
+		fmt.Printf(", flapping every %v for %v", scenario.FlapEvery, scenario.FlapDown)
+	}
+	if scenario.RebootEvery > 0 {
+		fmt.Printf(", rebooting every %v", scenario.RebootEvery)
+	}
+	fmt.Println()
+	fmt.Printf("Poll it with host = %q, port = %s and an SNMPv2c identity with community %q.\n", host, port, *community)
+	fmt.Println("Press Ctrl+C to stop.")
+
+	if err := simulate.NewAgent(device, *community).Serve(conn); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// profileNames lists the traffic profiles for the --profile help.
+func profileNames() string {
+	names := make([]string, len(simulate.Profiles))
+	for i, p := range simulate.Profiles {
+		names[i] = string(p)
+	}
+	return strings.Join(names, ", ")
+}
+
+// summarizeNames shortens a long list of interface names to its ends.
+func summarizeNames(names []string) string {
+	if len(names) <= 4 {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s, %s ... %s", names[0], names[1], names[len(names)-1])
+}
@@ -14,6 +14,8 @@ type Config struct {
 	PollIntervalStr string        `toml:"poll_interval"`
 	MaxHistory      int           `toml:"max_history"`
 	TimeFormat      string        `toml:"time_format"`
+	HistoryDays     int           `toml:"history_days"`   // on-disk history kept, 0 for no limit
+	HistoryMaxMB    int           `toml:"history_max_mb"` // on-disk history size, 0 for no limit
 }
 
 func DefaultConfig() *Config {
@@ -24,6 +26,8 @@ func DefaultConfig() *Config {
 		PollIntervalStr: "10s",
 		MaxHistory:      360,
 		TimeFormat:      "relative",
+		HistoryDays:     90,
+		HistoryMaxMB:    1024,
 	}
 }
 
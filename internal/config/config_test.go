@@ -17,6 +17,9 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.MaxHistory != 360 {
 		t.Errorf("expected max history 360, got %d", cfg.MaxHistory)
 	}
+	if cfg.HistoryDays != 90 || cfg.HistoryMaxMB != 1024 {
+		t.Errorf("expected 90 days and 1024 MB of history, got %d and %d", cfg.HistoryDays, cfg.HistoryMaxMB)
+	}
 }
 
 func TestConfigSaveLoad(t *testing.T) {
@@ -65,6 +65,24 @@ func GetDashboardsDir() (string, error) {
 	return filepath.Join(cfgDir, "dashboards"), nil
 }
 
+// GetHistoryDir returns the directory for the on-disk interface history.
+func GetHistoryDir() (string, error) {
+	dataDir, err := GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "history"), nil
+}
+
+// GetRecordingsDir returns the directory for recorded poll sessions.
+func GetRecordingsDir() (string, error) {
+	dataDir, err := GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "recordings"), nil
+}
+
 // GetIdentityStorePath returns the path to the encrypted identity store.
 func GetIdentityStorePath() (string, error) {
 	cfgDir, err := GetConfigDir()
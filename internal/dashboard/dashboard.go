@@ -9,20 +9,158 @@ type Dashboard struct {
 	IntervalStr     string        `toml:"interval"`
 	Interval        time.Duration `toml:"-"`
 	MaxHistory      int           `toml:"max_history"`
+	Columns         []string      `toml:"columns,omitempty"`
 	Groups          []Group       `toml:"groups"`
+	Aggregates      []Aggregate   `toml:"aggregates,omitempty"`
 }
 
+// Optional dashboard columns that can be listed in Dashboard.Columns to be
+// shown alongside the default In/Out/Util columns.
+const (
+	ColumnErrors   = "errors"
+	ColumnDiscards = "discards"
+	ColumnPPS      = "pps"
+	ColumnFlaps    = "flaps"
+)
+
 // Group represents a named collection of monitoring targets.
 type Group struct {
 	Name    string   `toml:"name"`
 	Targets []Target `toml:"targets"`
+	PollOptions
 }
 
 // Target represents a single SNMP device to monitor.
 type Target struct {
-	Host       string   `toml:"host"`
-	Label      string   `toml:"label"`
-	Identity   string   `toml:"identity"`
-	Port       int      `toml:"port"`
-	Interfaces []string `toml:"interfaces"`
+	Host       string      `toml:"host"`
+	Label      string      `toml:"label"`
+	Identity   string      `toml:"identity"`
+	Port       int         `toml:"port"`
+	Interfaces []string    `toml:"interfaces"`
+	OIDs       []CustomOID `toml:"oids,omitempty"`
+	PollOptions
+}
+
+// Aggregate functions.
+const (
+	AggregateSum = "sum"
+	AggregateAvg = "avg"
+	AggregateMax = "max"
+)
+
+// Aggregate is a virtual dashboard row combining the rates of interfaces
+// across targets, e.g. the total of several Internet uplinks.
+type Aggregate struct {
+	Label    string            `toml:"label"`
+	Function string            `toml:"function,omitempty"` // AggregateSum (default), AggregateAvg or AggregateMax
+	Capacity uint64            `toml:"capacity,omitzero"`  // Mbps; 0 means derived from the members' speeds
+	Members  []AggregateMember `toml:"members"`
+}
+
+// AggregateMember names an interface of one of the dashboard's targets.
+type AggregateMember struct {
+	Target    string `toml:"target"` // host or label
+	Interface string `toml:"interface"`
+}
+
+// Kinds of custom OID.
+const (
+	OIDKindGauge   = "gauge"   // shown as read
+	OIDKindCounter = "counter" // converted to a per-second rate
+)
+
+// CustomOID is an arbitrary scalar OID polled alongside a target's
+// interfaces and shown as its own row, e.g. a firewall's session count.
+type CustomOID struct {
+	Label string  `toml:"label"`
+	OID   string  `toml:"oid"`
+	Type  string  `toml:"type"` // OIDKindGauge or OIDKindCounter
+	Unit  string  `toml:"unit,omitempty"`
+	Scale float64 `toml:"scale,omitzero"` // multiplier applied to each reading; 0 means 1
+}
+
+// Defaults for poll settings that neither a target, its group nor the
+// dashboard specify.
+const (
+	DefaultTimeout = 5 * time.Second
+	DefaultRetries = 2
+)
+
+// PollOptions override how the targets of a group, or a single target, are
+// polled. Unset fields inherit from the group and then the dashboard.
+// Retries is a pointer so that an explicit zero can be told apart from
+// unset.
+type PollOptions struct {
+	IntervalStr    string        `toml:"interval,omitempty"`
+	Interval       time.Duration `toml:"-"`
+	TimeoutStr     string        `toml:"timeout,omitempty"`
+	Timeout        time.Duration `toml:"-"`
+	Retries        *int          `toml:"retries,omitempty"`
+	MaxRepetitions int           `toml:"max_repetitions,omitzero"`
+}
+
+// PollSettings is the fully resolved polling configuration of one target.
+// A MaxRepetitions of 0 lets the poller choose.
+type PollSettings struct {
+	Interval       time.Duration
+	Timeout        time.Duration
+	Retries        int
+	MaxRepetitions int
+}
+
+// PollSettings resolves the poll settings for a target in group g: target
+// overrides win over group overrides, which win over the dashboard interval
+// and the package defaults.
+func (d *Dashboard) PollSettings(g Group, t Target) PollSettings {
+	s := PollSettings{
+		Interval: d.Interval,
+		Timeout:  DefaultTimeout,
+		Retries:  DefaultRetries,
+	}
+	for _, o := range []PollOptions{g.PollOptions, t.PollOptions} {
+		if o.Interval > 0 {
+			s.Interval = o.Interval
+		}
+		if o.Timeout > 0 {
+			s.Timeout = o.Timeout
+		}
+		if o.Retries != nil && *o.Retries >= 0 {
+			s.Retries = *o.Retries
+		}
+		if o.MaxRepetitions > 0 {
+			s.MaxRepetitions = o.MaxRepetitions
+		}
+	}
+	return s
+}
+
+// Inherit fills the options left unset with those of parent, so that a
+// target keeps its effective settings when moved out of its group.
+func (o PollOptions) Inherit(parent PollOptions) PollOptions {
+	if o.Interval == 0 {
+		o.Interval = parent.Interval
+	}
+	if o.Timeout == 0 {
+		o.Timeout = parent.Timeout
+	}
+	if o.Retries == nil {
+		o.Retries = parent.Retries
+	}
+	if o.MaxRepetitions == 0 {
+		o.MaxRepetitions = parent.MaxRepetitions
+	}
+	return o
+}
+
+// FlatTargets returns the targets of every group in order, each taking over
+// its group's poll overrides, as the editors flatten groups on save.
+func (d *Dashboard) FlatTargets() []Target {
+	var targets []Target
+	for _, g := range d.Groups {
+		for _, t := range g.Targets {
+			t.PollOptions = t.PollOptions.Inherit(g.PollOptions)
+			targets = append(targets, t)
+		}
+	}
+	return targets
 }
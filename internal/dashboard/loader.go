@@ -1,8 +1,10 @@
 package dashboard
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 // LoadDashboard reads a TOML file at path and returns a populated Dashboard.
 // It applies sensible defaults for missing fields: 10s interval, 360 max_history,
 // port 161, and inherits default_identity for targets without an explicit identity.
+// Interval and timeout overrides on groups and targets must be valid durations.
 func LoadDashboard(path string) (*Dashboard, error) {
 	var dash Dashboard
 	if _, err := toml.DecodeFile(path, &dash); err != nil {
@@ -30,7 +33,18 @@ func LoadDashboard(path string) (*Dashboard, error) {
 		dash.MaxHistory = 360
 	}
 	for i := range dash.Groups {
+		if err := dash.Groups[i].PollOptions.parse(); err != nil {
+			return nil, fmt.Errorf("group %q: %w", dash.Groups[i].Name, err)
+		}
 		for j := range dash.Groups[i].Targets {
+			if err := dash.Groups[i].Targets[j].PollOptions.parse(); err != nil {
+				return nil, fmt.Errorf("target %q: %w", dash.Groups[i].Targets[j].Host, err)
+			}
+			for k := range dash.Groups[i].Targets[j].OIDs {
+				if err := dash.Groups[i].Targets[j].OIDs[k].normalize(); err != nil {
+					return nil, fmt.Errorf("target %q: %w", dash.Groups[i].Targets[j].Host, err)
+				}
+			}
 			if dash.Groups[i].Targets[j].Port == 0 {
 				dash.Groups[i].Targets[j].Port = 161
 			}
@@ -39,13 +53,110 @@ func LoadDashboard(path string) (*Dashboard, error) {
 			}
 		}
 	}
+	for i := range dash.Aggregates {
+		if err := dash.Aggregates[i].normalize(); err != nil {
+			return nil, err
+		}
+	}
 	return &dash, nil
 }
 
+// parse converts the interval and timeout strings into durations and
+// validates the retry count.
+func (o *PollOptions) parse() error {
+	if o.IntervalStr != "" {
+		d, err := time.ParseDuration(o.IntervalStr)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid interval %q", o.IntervalStr)
+		}
+		o.Interval = d
+	}
+	if o.TimeoutStr != "" {
+		d, err := time.ParseDuration(o.TimeoutStr)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout %q", o.TimeoutStr)
+		}
+		o.Timeout = d
+	}
+	if o.Retries != nil && *o.Retries < 0 {
+		return fmt.Errorf("invalid retries %d", *o.Retries)
+	}
+	return nil
+}
+
+// normalize validates a custom OID and fills in its defaults: gauge type,
+// the OID as label, and no leading dot.
+func (c *CustomOID) normalize() error {
+	c.OID = strings.TrimPrefix(strings.TrimSpace(c.OID), ".")
+	if c.OID == "" {
+		return fmt.Errorf("custom OID %q has no oid", c.Label)
+	}
+	for _, part := range strings.Split(c.OID, ".") {
+		if _, err := strconv.ParseUint(part, 10, 32); err != nil {
+			return fmt.Errorf("invalid oid %q", c.OID)
+		}
+	}
+	switch c.Type {
+	case "":
+		c.Type = OIDKindGauge
+	case OIDKindGauge, OIDKindCounter:
+	default:
+		return fmt.Errorf("oid %q: unknown type %q", c.OID, c.Type)
+	}
+	if c.Label == "" {
+		c.Label = c.OID
+	}
+	return nil
+}
+
+// normalize validates an aggregate and defaults its function to a sum.
+// Members need not match a configured target: they are reported as
+// unavailable, so that removing a target does not make the file unloadable.
+func (a *Aggregate) normalize() error {
+	if a.Label == "" {
+		return fmt.Errorf("aggregate has no label")
+	}
+	switch a.Function {
+	case "":
+		a.Function = AggregateSum
+	case AggregateSum, AggregateAvg, AggregateMax:
+	default:
+		return fmt.Errorf("aggregate %q: unknown function %q", a.Label, a.Function)
+	}
+	if len(a.Members) == 0 {
+		return fmt.Errorf("aggregate %q has no members", a.Label)
+	}
+	for _, m := range a.Members {
+		if m.Target == "" || m.Interface == "" {
+			return fmt.Errorf("aggregate %q: member needs a target and an interface", a.Label)
+		}
+	}
+	return nil
+}
+
+// format converts the interval and timeout durations back into strings,
+// leaving unset overrides empty.
+func (o *PollOptions) format() {
+	o.IntervalStr, o.TimeoutStr = "", ""
+	if o.Interval > 0 {
+		o.IntervalStr = o.Interval.String()
+	}
+	if o.Timeout > 0 {
+		o.TimeoutStr = o.Timeout.String()
+	}
+}
+
 // SaveDashboard writes a Dashboard to a TOML file at path.
-// It serialises the Interval duration into IntervalStr before encoding.
+// It serialises the Interval duration into IntervalStr before encoding, along
+// with any group and target poll overrides.
 func SaveDashboard(dash *Dashboard, path string) error {
 	dash.IntervalStr = dash.Interval.String()
+	for i := range dash.Groups {
+		dash.Groups[i].PollOptions.format()
+		for j := range dash.Groups[i].Targets {
+			dash.Groups[i].Targets[j].PollOptions.format()
+		}
+	}
 	f, err := os.Create(path)
 	if err != nil {
 		return err
@@ -12,6 +12,7 @@ name = "Test Dashboard"
 default_identity = "test-v2c"
 interval = "10s"
 max_history = 360
+columns = ["errors", "discards"]
 
 [[groups]]
 name = "Core"
@@ -24,13 +25,28 @@ interfaces = ["Gi0/0", "Gi0/1"]
 
 [[groups]]
 name = "Branch"
+interval = "60s"
+timeout = "15s"
 
 [[groups.targets]]
 host = "10.1.1.1"
 label = "branch-1"
 identity = "branch-ro"
 port = 1161
+retries = 0
+max_repetitions = 10
 interfaces = ["Eth1"]
+
+[[groups.targets.oids]]
+label = "FW sessions"
+oid = ".1.3.6.1.4.1.9.9.491.1.1.1.6.0"
+unit = "sessions"
+
+[[groups.targets.oids]]
+label = "DHCP offers"
+oid = "1.3.6.1.4.1.9.9.101.1.1.1.2.0"
+type = "counter"
+scale = 0.5
 `
 
 func TestLoadDashboard(t *testing.T) {
@@ -48,6 +64,9 @@ func TestLoadDashboard(t *testing.T) {
 	if dash.Interval != 10*time.Second {
 		t.Errorf("expected interval 10s, got %v", dash.Interval)
 	}
+	if len(dash.Columns) != 2 || dash.Columns[0] != ColumnErrors {
+		t.Errorf("expected columns [errors discards], got %v", dash.Columns)
+	}
 	if len(dash.Groups) != 2 {
 		t.Fatalf("expected 2 groups, got %d", len(dash.Groups))
 	}
@@ -59,6 +78,195 @@ func TestLoadDashboard(t *testing.T) {
 	}
 }
 
+func TestLoadDashboardCustomOIDs(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.toml")
+	os.WriteFile(path, []byte(testDashboardTOML), 0644)
+
+	dash, err := LoadDashboard(path)
+	if err != nil {
+		t.Fatalf("LoadDashboard() error: %v", err)
+	}
+	oids := dash.Groups[1].Targets[0].OIDs
+	if len(oids) != 2 {
+		t.Fatalf("expected 2 custom OIDs, got %d", len(oids))
+	}
+	if oids[0].OID != "1.3.6.1.4.1.9.9.491.1.1.1.6.0" {
+		t.Errorf("expected leading dot trimmed, got %q", oids[0].OID)
+	}
+	if oids[0].Type != OIDKindGauge {
+		t.Errorf("expected default type gauge, got %q", oids[0].Type)
+	}
+	if oids[1].Type != OIDKindCounter || oids[1].Scale != 0.5 {
+		t.Errorf("unexpected counter OID: %+v", oids[1])
+	}
+
+	bad := filepath.Join(tmp, "bad.toml")
+	os.WriteFile(bad, []byte(`
+name = "Bad"
+
+[[groups]]
+name = "Core"
+
+[[groups.targets]]
+host = "10.0.1.1"
+interfaces = []
+
+[[groups.targets.oids]]
+oid = "1.3.6.1.2.1.1.3.0"
+type = "derive"
+`), 0644)
+	if _, err := LoadDashboard(bad); err == nil {
+		t.Error("expected an error for an unknown custom OID type")
+	}
+}
+
+func TestLoadDashboardAggregates(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.toml")
+	os.WriteFile(path, []byte(testDashboardTOML+`
+[[aggregates]]
+label = "Total Internet"
+capacity = 2000
+members = [
+  { target = "rtr-1", interface = "Gi0/0" },
+  { target = "10.1.1.1", interface = "Eth1" },
+]
+
+[[aggregates]]
+label = "Peak uplink"
+function = "max"
+members = [{ target = "rtr-1", interface = "Gi0/1" }]
+`), 0644)
+
+	dash, err := LoadDashboard(path)
+	if err != nil {
+		t.Fatalf("LoadDashboard() error: %v", err)
+	}
+	if len(dash.Aggregates) != 2 {
+		t.Fatalf("expected 2 aggregates, got %d", len(dash.Aggregates))
+	}
+	total := dash.Aggregates[0]
+	if total.Function != AggregateSum || total.Capacity != 2000 || len(total.Members) != 2 {
+		t.Errorf("unexpected aggregate %+v", total)
+	}
+	if total.Members[1] != (AggregateMember{Target: "10.1.1.1", Interface: "Eth1"}) {
+		t.Errorf("unexpected member %+v", total.Members[1])
+	}
+	if dash.Aggregates[1].Function != AggregateMax {
+		t.Errorf("expected max, got %q", dash.Aggregates[1].Function)
+	}
+
+	for name, agg := range map[string]string{
+		"unknown function": `label = "x"
+function = "median"
+members = [{ target = "rtr-1", interface = "Gi0/0" }]`,
+		"no members": `label = "x"`,
+		"no label":   `members = [{ target = "rtr-1", interface = "Gi0/0" }]`,
+	} {
+		bad := filepath.Join(tmp, "bad.toml")
+		os.WriteFile(bad, []byte(testDashboardTOML+"\n[[aggregates]]\n"+agg+"\n"), 0644)
+		if _, err := LoadDashboard(bad); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestPollSettings(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "test.toml")
+	os.WriteFile(path, []byte(testDashboardTOML), 0644)
+
+	dash, err := LoadDashboard(path)
+	if err != nil {
+		t.Fatalf("LoadDashboard() error: %v", err)
+	}
+
+	core := dash.PollSettings(dash.Groups[0], dash.Groups[0].Targets[0])
+	want := PollSettings{Interval: 10 * time.Second, Timeout: DefaultTimeout, Retries: DefaultRetries}
+	if core != want {
+		t.Errorf("expected dashboard defaults %+v, got %+v", want, core)
+	}
+
+	branch := dash.PollSettings(dash.Groups[1], dash.Groups[1].Targets[0])
+	want = PollSettings{Interval: 60 * time.Second, Timeout: 15 * time.Second, Retries: 0, MaxRepetitions: 10}
+	if branch != want {
+		t.Errorf("expected overrides %+v, got %+v", want, branch)
+	}
+
+	// Overrides survive a save and reload.
+	if err := SaveDashboard(dash, path); err != nil {
+		t.Fatalf("SaveDashboard() error: %v", err)
+	}
+	reloaded, err := LoadDashboard(path)
+	if err != nil {
+		t.Fatalf("reload error: %v", err)
+	}
+	if got := reloaded.PollSettings(reloaded.Groups[1], reloaded.Groups[1].Targets[0]); got != want {
+		t.Errorf("expected overrides %+v after reload, got %+v", want, got)
+	}
+}
+
+func TestLoadDashboardInvalidOverride(t *testing.T) {
+	tmp := t.TempDir()
+	for name, option := range map[string]string{
+		"timeout": `timeout = "five seconds"`,
+		"retries": `retries = -1`,
+	} {
+		path := filepath.Join(tmp, name+".toml")
+		os.WriteFile(path, []byte(`
+name = "Bad"
+
+[[groups]]
+name = "Core"
+
+[[groups.targets]]
+host = "10.0.1.1"
+`+option+`
+interfaces = ["Gi0/0"]
+`), 0644)
+
+		if _, err := LoadDashboard(path); err == nil {
+			t.Errorf("expected an error for invalid %s", name)
+		}
+	}
+}
+
+func TestPollOptionsInherit(t *testing.T) {
+	zero := 0
+	group := PollOptions{Interval: time.Minute, Retries: &zero}
+	target := PollOptions{Interval: 2 * time.Second}
+
+	got := target.Inherit(group)
+	if got.Interval != 2*time.Second {
+		t.Errorf("target interval should win, got %v", got.Interval)
+	}
+	if got.Retries == nil || *got.Retries != 0 {
+		t.Errorf("expected group retries to be inherited, got %v", got.Retries)
+	}
+}
+
+func TestFlatTargets(t *testing.T) {
+	dash := &Dashboard{Groups: []Group{
+		{Name: "Core", PollOptions: PollOptions{Interval: time.Minute}, Targets: []Target{
+			{Host: "10.0.0.1"},
+			{Host: "10.0.0.2", PollOptions: PollOptions{Interval: 5 * time.Second}},
+		}},
+		{Name: "Edge", Targets: []Target{{Host: "10.0.1.1"}}},
+	}}
+	targets := dash.FlatTargets()
+	if len(targets) != 3 || targets[2].Host != "10.0.1.1" {
+		t.Fatalf("expected the 3 targets in order, got %+v", targets)
+	}
+	if targets[0].Interval != time.Minute || targets[1].Interval != 5*time.Second || targets[2].Interval != 0 {
+		t.Errorf("expected targets to take over their group's interval, got %v, %v, %v",
+			targets[0].Interval, targets[1].Interval, targets[2].Interval)
+	}
+	if dash.Groups[0].Targets[0].Interval != 0 {
+		t.Error("the dashboard's own targets should be left unchanged")
+	}
+}
+
 func TestSaveDashboard(t *testing.T) {
 	tmp := t.TempDir()
 	path := filepath.Join(tmp, "out.toml")
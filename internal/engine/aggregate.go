@@ -0,0 +1,181 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tonhe/flo/internal/dashboard"
+)
+
+// aggregatePartial is the status of an aggregate row some of whose members
+// are down or unavailable.
+const aggregatePartial = "partial"
+
+// rateFields selects each per-second value of a RateSample that aggregates
+// combine.
+var rateFields = []func(*RateSample) *float64{
+	func(r *RateSample) *float64 { return &r.InRate },
+	func(r *RateSample) *float64 { return &r.OutRate },
+	func(r *RateSample) *float64 { return &r.InPPS.Unicast },
+	func(r *RateSample) *float64 { return &r.InPPS.Multicast },
+	func(r *RateSample) *float64 { return &r.InPPS.Broadcast },
+	func(r *RateSample) *float64 { return &r.OutPPS.Unicast },
+	func(r *RateSample) *float64 { return &r.OutPPS.Multicast },
+	func(r *RateSample) *float64 { return &r.OutPPS.Broadcast },
+	func(r *RateSample) *float64 { return &r.InErrors },
+	func(r *RateSample) *float64 { return &r.OutErrors },
+	func(r *RateSample) *float64 { return &r.InDiscards },
+	func(r *RateSample) *float64 { return &r.OutDiscards },
+}
+
+// newAggregateStats returns empty stats for the dashboard's aggregate rows.
+func (p *Poller) newAggregateStats() []InterfaceStats {
+	stats := make([]InterfaceStats, len(p.dash.Aggregates))
+	for i, agg := range p.dash.Aggregates {
+		stats[i] = InterfaceStats{
+			Name:        agg.Label,
+			Description: fmt.Sprintf("%s of %d interfaces", agg.Function, len(agg.Members)),
+			History:     NewRingBuffer[RateSample](p.dash.MaxHistory),
+			Rollups:     NewRollups(),
+		}
+	}
+	return stats
+}
+
+// commitAggregatesLocked recomputes the aggregate rows from the latest rates
+// of their member interfaces and adds a point to their history. Members that
+// cannot be found, have not been polled yet or whose target failed its last
+// poll are left out, and mark the row as partial.
+// Must be called while holding the write lock on p.mu.
+func (p *Poller) commitAggregatesLocked(now time.Time) {
+	for i, agg := range p.dash.Aggregates {
+		row := &p.aggregates[i]
+		var members []*InterfaceStats
+		found, up := 0, 0
+		for _, m := range agg.Members {
+			iface, ts := p.aggregateMemberLocked(m)
+			if iface == nil {
+				continue
+			}
+			found++
+			if iface.LastPoll.IsZero() || iface.Status == "" || ts.PollError != nil || ts.Health == TargetUnreachable {
+				continue
+			}
+			members = append(members, iface)
+			if iface.Status == "up" {
+				up++
+			}
+		}
+
+		row.NotFound = found == 0
+		if len(members) == 0 {
+			row.Status = ""
+			continue
+		}
+		switch {
+		case up == len(agg.Members):
+			row.Status = "up"
+		case up == 0 && len(members) == len(agg.Members):
+			row.Status = "down"
+		default:
+			row.Status = aggregatePartial
+		}
+		row.Description = fmt.Sprintf("%s of %d/%d interfaces", agg.Function, len(members), len(agg.Members))
+
+		rate := combineRates(agg.Function, members)
+		rate.Timestamp = now
+		row.Speed = agg.Capacity
+		if row.Speed == 0 {
+			row.Speed = combineSpeeds(agg.Function, members)
+		}
+		row.InRate, row.OutRate = rate.InRate, rate.OutRate
+		row.InPPS, row.OutPPS = rate.InPPS, rate.OutPPS
+		row.InAvgSize, row.OutAvgSize = rate.InAvgSize, rate.OutAvgSize
+		row.InErrors, row.OutErrors = rate.InErrors, rate.OutErrors
+		row.InDiscards, row.OutDiscards = rate.InDiscards, rate.OutDiscards
+		row.InErrorPct, row.OutErrorPct = rate.InErrorPct, rate.OutErrorPct
+		row.Utilization = CalculateUtilization(rate.InRate, rate.OutRate, row.Speed)
+		row.LastPoll = now
+		row.addRate(rate)
+	}
+}
+
+// aggregateMemberLocked finds the interface an aggregate member refers to,
+// among the configured interfaces and LAG members of the first target whose
+// host or label matches. It returns nil if there is none.
+// Must be called while holding at least a read lock on p.mu.
+func (p *Poller) aggregateMemberLocked(m dashboard.AggregateMember) (*InterfaceStats, *TargetStats) {
+	for _, group := range p.dash.Groups {
+		for _, target := range group.Targets {
+			if target.Host != m.Target && target.Label != m.Target {
+				continue
+			}
+			ts, ok := p.data[target.Host]
+			if !ok {
+				return nil, nil
+			}
+			for _, iface := range polledInterfaces(ts) {
+				if iface.Name == m.Interface && !iface.NotFound {
+					return iface, ts
+				}
+			}
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+// combineRates applies an aggregate function to the current rates of the
+// members. Average packet sizes and error percentages are derived from the
+// combined rates rather than combined themselves.
+func combineRates(function string, members []*InterfaceStats) RateSample {
+	rates := make([]RateSample, len(members))
+	for i, m := range members {
+		rates[i] = RateSample{
+			InRate: m.InRate, OutRate: m.OutRate,
+			InPPS: m.InPPS, OutPPS: m.OutPPS,
+			InErrors: m.InErrors, OutErrors: m.OutErrors,
+			InDiscards: m.InDiscards, OutDiscards: m.OutDiscards,
+		}
+	}
+	var out RateSample
+	values := make([]float64, len(rates))
+	for _, field := range rateFields {
+		for i := range rates {
+			values[i] = *field(&rates[i])
+		}
+		*field(&out) = combine(function, values)
+	}
+	out.deriveRatios()
+	return out
+}
+
+// combineSpeeds returns the capacity an aggregate's utilization is measured
+// against when none is configured: the members' combined speed for a sum,
+// and the average or largest member speed otherwise.
+func combineSpeeds(function string, members []*InterfaceStats) uint64 {
+	speeds := make([]float64, len(members))
+	for i, m := range members {
+		speeds[i] = float64(m.Speed)
+	}
+	return uint64(combine(function, speeds))
+}
+
+// combine applies an aggregate function to a set of values.
+func combine(function string, values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var total, peak float64
+	for _, v := range values {
+		total += v
+		peak = max(peak, v)
+	}
+	switch function {
+	case dashboard.AggregateAvg:
+		return total / float64(len(values))
+	case dashboard.AggregateMax:
+		return peak
+	}
+	return total
+}
@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tonhe/flo/internal/dashboard"
+)
+
+func TestCombine(t *testing.T) {
+	values := []float64{1, 4, 7}
+	tests := map[string]float64{
+		dashboard.AggregateSum: 12,
+		dashboard.AggregateAvg: 4,
+		dashboard.AggregateMax: 7,
+	}
+	for fn, want := range tests {
+		if got := combine(fn, values); got != want {
+			t.Errorf("combine(%s) = %v, want %v", fn, got, want)
+		}
+	}
+	if got := combine(dashboard.AggregateAvg, nil); got != 0 {
+		t.Errorf("combine of nothing = %v, want 0", got)
+	}
+}
+
+func TestCommitAggregates(t *testing.T) {
+	p := newTestPoller(t, "Gi0/1", "Gi0/2", "Gi0/3")
+	p.dash.Aggregates = []dashboard.Aggregate{
+		{Label: "Uplinks", Function: dashboard.AggregateSum, Members: []dashboard.AggregateMember{
+			{Target: "sw1", Interface: "Gi0/1"},
+			{Target: "10.0.0.1", Interface: "Gi0/2"},
+		}},
+		{Label: "Busiest", Function: dashboard.AggregateMax, Capacity: 100, Members: []dashboard.AggregateMember{
+			{Target: "sw1", Interface: "Gi0/2"},
+			{Target: "sw1", Interface: "Gi0/3"},
+		}},
+		{Label: "Elsewhere", Function: dashboard.AggregateSum, Members: []dashboard.AggregateMember{
+			{Target: "rtr9", Interface: "Gi0/1"},
+		}},
+	}
+	p.aggregates = p.newAggregateStats()
+
+	now := time.Now()
+	ts := p.data["10.0.0.1"]
+	for i, rate := range []float64{100e6, 300e6, 50e6} {
+		iface := &ts.Interfaces[i]
+		iface.Speed = 1000
+		iface.Status = "up"
+		iface.InRate, iface.OutRate = rate, rate/2
+		iface.LastPoll = now
+	}
+	ts.Interfaces[2].Status = "down"
+	p.commitAggregatesLocked(now)
+
+	snap := p.Snapshot()
+	if len(snap.Aggregates) != 3 {
+		t.Fatalf("expected 3 aggregate rows, got %d", len(snap.Aggregates))
+	}
+	up := snap.Aggregates[0]
+	if up.Status != "up" || up.InRate != 400e6 || up.OutRate != 200e6 || up.Speed != 2000 {
+		t.Errorf("unexpected sum row %+v", up)
+	}
+	if up.Utilization != 20 || up.History.Len() != 1 {
+		t.Errorf("expected 20%% of the combined speed and one history point, got %.1f%% and %d",
+			up.Utilization, up.History.Len())
+	}
+	busiest := snap.Aggregates[1]
+	if busiest.Status != aggregatePartial || busiest.InRate != 300e6 || busiest.Speed != 100 {
+		t.Errorf("unexpected max row %+v", busiest)
+	}
+	if !snap.Aggregates[2].NotFound {
+		t.Error("an aggregate without any matching member should be not found")
+	}
+}
+
+func TestCommitAggregatesFailedTarget(t *testing.T) {
+	p := newTestPoller(t, "Gi0/1")
+	p.dash.Groups[0].Targets = append(p.dash.Groups[0].Targets, dashboard.Target{Host: "10.0.0.2", Label: "sw2", Interfaces: []string{"Gi0/1"}})
+	p.dash.Aggregates = []dashboard.Aggregate{
+		{Label: "Uplinks", Function: dashboard.AggregateSum, Members: []dashboard.AggregateMember{
+			{Target: "sw1", Interface: "Gi0/1"},
+			{Target: "sw2", Interface: "Gi0/1"},
+		}},
+	}
+	p.initTargetStats()
+	p.aggregates = p.newAggregateStats()
+
+	now := time.Now()
+	for _, host := range []string{"10.0.0.1", "10.0.0.2"} {
+		iface := &p.data[host].Interfaces[0]
+		iface.Status = "up"
+		iface.InRate = 100e6
+		iface.LastPoll = now
+	}
+	// sw2 failed its last poll but is not yet unreachable: its last rate
+	// is stale.
+	sw2 := p.data["10.0.0.2"]
+	sw2.PollError = errors.New("timeout")
+	sw2.Health = TargetDegraded
+	p.commitAggregatesLocked(now)
+
+	row := p.Snapshot().Aggregates[0]
+	if row.Status != aggregatePartial || row.InRate != 100e6 {
+		t.Errorf("expected a partial row without the failed target, got %q at %.0f", row.Status, row.InRate)
+	}
+}
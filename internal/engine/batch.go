@@ -0,0 +1,443 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// Counter widths for interface octet and packet counters.
+const (
+	counters64 = 64 // ifXTable high-capacity counters
+	counters32 = 32 // ifTable counters, used for SNMPv1 and older agents
+)
+
+// hcCounterColumns are the 64-bit ifXTable octet and packet counters.
+var hcCounterColumns = []string{
+	OIDifHCInOctets,
+	OIDifHCOutOctets,
+	OIDifHCInUcastPkts,
+	OIDifHCOutUcastPkts,
+	OIDifHCInMulticastPkts,
+	OIDifHCOutMulticastPkts,
+	OIDifHCInBroadcastPkts,
+	OIDifHCOutBroadcastPkts,
+}
+
+// hcOptionalColumns are ifXTable columns polled alongside the 64-bit counters
+// that not every agent implements. A missing value is ignored rather than
+// failing the interface.
+var hcOptionalColumns = []string{
+	OIDifCounterDiscontinuityTime,
+}
+
+// legacyCounterColumns are the 32-bit ifTable equivalents. ifTable has no
+// separate multicast and broadcast counters, only the combined non-unicast
+// count.
+var legacyCounterColumns = []string{
+	OIDifInOctets,
+	OIDifOutOctets,
+	OIDifInUcastPkts,
+	OIDifOutUcastPkts,
+	OIDifInNUcastPkts,
+	OIDifOutNUcastPkts,
+}
+
+// commonColumns are polled regardless of counter width. ifDescr is polled so
+// the poller can notice when an ifIndex no longer belongs to the interface it
+// was resolved to; it is used rather than ifName because every agent has
+// ifTable, including SNMPv1 agents with no ifXTable.
+var commonColumns = []string{
+	OIDifDescr,
+	OIDifOperStatus,
+	OIDifInErrors,
+	OIDifOutErrors,
+	OIDifInDiscards,
+	OIDifOutDiscards,
+}
+
+// commonOptionalColumns are polled regardless of counter width and, like
+// hcOptionalColumns, ignored when missing.
+var commonOptionalColumns = []string{
+	OIDifLastChange,
+}
+
+// allInterfaceColumns is every column that may appear in a poll response.
+var allInterfaceColumns = concatColumns(hcCounterColumns, hcOptionalColumns, legacyCounterColumns, commonColumns, commonOptionalColumns)
+
+// interfaceColumns returns the per-interface OIDs collected on every poll
+// cycle for the given counter width. All columns for one interface are
+// always requested in the same PDU so that the sample shares a single
+// timestamp.
+func interfaceColumns(bits int) []string {
+	if bits == counters32 {
+		return concatColumns(legacyCounterColumns, commonColumns, commonOptionalColumns)
+	}
+	return concatColumns(hcCounterColumns, hcOptionalColumns, commonColumns, commonOptionalColumns)
+}
+
+func concatColumns(sets ...[]string) []string {
+	var cols []string
+	for _, set := range sets {
+		cols = append(cols, set...)
+	}
+	return cols
+}
+
+// bulkWalkFraction is the share of a device's interface table that must be
+// monitored before the poller walks whole columns with GETBULK instead of
+// issuing targeted GETs.
+const bulkWalkFraction = 0.75
+
+// minBulkWalkInterfaces avoids walking tiny tables where a single GET is
+// always cheaper.
+const minBulkWalkInterfaces = 8
+
+// maxBulkWalkRequests caps a column walk so a misbehaving agent that returns
+// non-increasing OIDs cannot loop forever.
+const maxBulkWalkRequests = 256
+
+var (
+	// ErrInterfaceUnresolved is recorded for interfaces whose name could not
+	// be mapped to an ifIndex on the device.
+	ErrInterfaceUnresolved = errors.New("interface not resolved")
+
+	// ErrNoSuchInstance is recorded when the agent has no value for one of
+	// an interface's polled columns.
+	ErrNoSuchInstance = errors.New("no such instance")
+)
+
+// getInterfaceSamples fetches counters and status for the given interfaces,
+// returned in the same order as indexes. Interfaces are packed into as few
+// GET PDUs as the target allows; when most of the interface table is
+// monitored the columns are walked with GETBULK instead. The per-target PDU
+// size is learned from tooBig responses and remembered across cycles.
+//
+// Interfaces are polled with 64-bit counters unless the target is SNMPv1 or
+// the interface has previously been found to lack them. An interface whose
+// high-capacity counters come back missing is re-polled with the 32-bit
+// ifTable counters in the same cycle, and remembered as 32-bit once that
+// succeeds.
+func (p *Poller) getInterfaceSamples(client *gosnmp.GoSNMP, host string, indexes []int) []interfaceSample {
+	samples := make([]interfaceSample, len(indexes))
+	state := p.targetState(host)
+
+	var wanted []int
+	uniform := true
+	for i, ifIndex := range indexes {
+		if ifIndex == 0 {
+			samples[i].err = ErrInterfaceUnresolved
+			continue
+		}
+		samples[i].counters.Bits = state.counterBits(ifIndex, client.Version)
+		if len(wanted) > 0 && samples[i].counters.Bits != samples[wanted[0]].counters.Bits {
+			uniform = false
+		}
+		wanted = append(wanted, i)
+	}
+	if len(wanted) == 0 {
+		return samples
+	}
+
+	if uniform && useBulkWalk(client.Version, len(wanted), state.tableSize) {
+		p.walkInterfaceSamples(client, state, indexes, wanted, samples)
+	} else {
+		p.getInterfaceSamplesBatched(client, state, indexes, wanted, samples)
+	}
+
+	var retry []int
+	for _, pos := range wanted {
+		if samples[pos].missingHC {
+			samples[pos] = interfaceSample{counters: CounterSample{Bits: counters32}}
+			retry = append(retry, pos)
+		}
+	}
+	if len(retry) > 0 {
+		p.getInterfaceSamplesBatched(client, state, indexes, retry, samples)
+		for _, pos := range retry {
+			if samples[pos].err == nil {
+				state.legacyCounters[indexes[pos]] = true
+			}
+		}
+	}
+	return samples
+}
+
+// counterBits returns the counter width to poll for an interface.
+func (st *targetState) counterBits(ifIndex int, version gosnmp.SnmpVersion) int {
+	if version == gosnmp.Version1 || st.legacyCounters[ifIndex] {
+		return counters32
+	}
+	return counters64
+}
+
+// useBulkWalk decides whether walking the interface columns is preferable to
+// targeted GETs. SNMPv1 has no GETBULK, so it always uses GETs.
+func useBulkWalk(version gosnmp.SnmpVersion, monitored, tableSize int) bool {
+	if version == gosnmp.Version1 || tableSize == 0 || monitored < minBulkWalkInterfaces {
+		return false
+	}
+	return float64(monitored) >= float64(tableSize)*bulkWalkFraction
+}
+
+// getInterfaceSamplesBatched issues GETs for the wanted interfaces, packing
+// as many interfaces' columns into each PDU as the target's learned PDU
+// size allows. A tooBig response halves the PDU size and retries; an SNMPv1
+// noSuchName (which fails the whole PDU when any one OID is missing) retries
+// the batch one interface at a time. A transport error (e.g. timeout) fails
+// the remaining interfaces immediately rather than waiting out one timeout
+// per batch. Each sample's counter width must be set before calling.
+func (p *Poller) getInterfaceSamplesBatched(client *gosnmp.GoSNMP, state *targetState, indexes, wanted []int, samples []interfaceSample) {
+	for start := 0; start < len(wanted); {
+		var oids []string
+		end := start
+		for end < len(wanted) {
+			pos := wanted[end]
+			cols := interfaceColumns(samples[pos].counters.Bits)
+			if end > start && len(oids)+len(cols) > state.oidsPerPDU {
+				break
+			}
+			for _, col := range cols {
+				oids = append(oids, fmt.Sprintf("%s.%d", col, indexes[pos]))
+			}
+			end++
+		}
+		batch := wanted[start:end]
+
+		result, err := client.Get(oids)
+		if err != nil {
+			for _, pos := range wanted[start:] {
+				samples[pos].err = err
+			}
+			return
+		}
+		if result.Error == gosnmp.TooBig && len(batch) > 1 {
+			state.oidsPerPDU /= 2
+			continue
+		}
+		if result.Error == gosnmp.NoSuchName && len(batch) > 1 {
+			for _, pos := range batch {
+				p.getInterfaceSamplesBatched(client, state, indexes, []int{pos}, samples)
+			}
+			start = end
+			continue
+		}
+		if result.Error != gosnmp.NoError {
+			for _, pos := range batch {
+				if result.Error == gosnmp.NoSuchName {
+					samples[pos].err = ErrNoSuchInstance
+				} else {
+					samples[pos].err = fmt.Errorf("agent returned %s", result.Error)
+				}
+			}
+			start = end
+			continue
+		}
+
+		now := time.Now()
+		byIndex := make(map[int]*interfaceSample, len(batch))
+		for _, pos := range batch {
+			samples[pos].counters.Timestamp = now
+			byIndex[indexes[pos]] = &samples[pos]
+		}
+		for _, v := range result.Variables {
+			col, ifIndex, ok := splitColumnOID(v.Name, allInterfaceColumns)
+			if !ok {
+				continue
+			}
+			if s, ok := byIndex[ifIndex]; ok {
+				s.apply(col, v)
+			}
+		}
+		start = end
+	}
+}
+
+// walkInterfaceSamples walks all interface columns in parallel with GETBULK
+// and picks out the wanted interfaces. All wanted interfaces must share the
+// same counter width. Unless the target has a configured max-repetitions,
+// each request asks for as many rows as fit in the learned PDU size.
+func (p *Poller) walkInterfaceSamples(client *gosnmp.GoSNMP, state *targetState, indexes, wanted []int, samples []interfaceSample) {
+	cols := interfaceColumns(samples[wanted[0]].counters.Bits)
+	reps := state.maxRepetitions
+	if reps == 0 {
+		reps = state.oidsPerPDU / len(cols)
+	}
+	if reps < 1 {
+		reps = 1
+	}
+
+	rows, err := bulkWalkColumns(client, cols, uint32(reps))
+	if err != nil {
+		for _, pos := range wanted {
+			samples[pos].err = err
+		}
+		return
+	}
+
+	now := time.Now()
+	for _, pos := range wanted {
+		s := &samples[pos]
+		s.counters.Timestamp = now
+		vars, ok := rows[indexes[pos]]
+		if !ok {
+			s.err = ErrNoSuchInstance
+			continue
+		}
+		for _, col := range cols {
+			v, ok := vars[col]
+			if !ok {
+				v = gosnmp.SnmpPDU{Type: gosnmp.NoSuchInstance}
+			}
+			s.apply(col, v)
+		}
+	}
+}
+
+// bulkWalkColumns walks several table columns side by side using GETBULK
+// requests that carry one varbind per unfinished column. It returns the rows
+// keyed by ifIndex and then by column OID.
+func bulkWalkColumns(client *gosnmp.GoSNMP, columns []string, reps uint32) (map[int]map[string]gosnmp.SnmpPDU, error) {
+	rows := make(map[int]map[string]gosnmp.SnmpPDU)
+	next := make([]string, len(columns))
+	copy(next, columns)
+	active := make([]string, len(columns))
+	copy(active, columns)
+
+	for req := 0; len(active) > 0 && req < maxBulkWalkRequests; req++ {
+		result, err := client.GetBulk(next, 0, reps)
+		if err != nil {
+			return nil, err
+		}
+		if result.Error != gosnmp.NoError {
+			return nil, fmt.Errorf("agent returned %s", result.Error)
+		}
+		if len(result.Variables) == 0 {
+			break
+		}
+
+		done := make(map[string]bool)
+		for i, v := range result.Variables {
+			col := active[i%len(active)]
+			if done[col] {
+				continue
+			}
+			gotCol, ifIndex, ok := splitColumnOID(v.Name, []string{col})
+			if !ok || gotCol != col || v.Type == gosnmp.EndOfMibView {
+				done[col] = true
+				continue
+			}
+			if rows[ifIndex] == nil {
+				rows[ifIndex] = make(map[string]gosnmp.SnmpPDU)
+			}
+			rows[ifIndex][col] = v
+			next[i%len(active)] = strings.TrimPrefix(v.Name, ".")
+		}
+
+		var stillActive, stillNext []string
+		for i, col := range active {
+			if !done[col] {
+				stillActive = append(stillActive, col)
+				stillNext = append(stillNext, next[i])
+			}
+		}
+		active, next = stillActive, stillNext
+	}
+	return rows, nil
+}
+
+// splitColumnOID matches an OID against the given table columns and returns
+// the column and its single-component index suffix.
+func splitColumnOID(oid string, columns []string) (string, int, bool) {
+	oid = strings.TrimPrefix(oid, ".")
+	for _, col := range columns {
+		if !strings.HasPrefix(oid, col+".") {
+			continue
+		}
+		idx, err := strconv.Atoi(oid[len(col)+1:])
+		if err != nil {
+			return "", 0, false
+		}
+		return col, idx, true
+	}
+	return "", 0, false
+}
+
+// apply decodes a single polled varbind into the sample. A missing 64-bit
+// counter is flagged separately so the interface can fall back to 32-bit
+// counters; a missing optional column is ignored.
+func (s *interfaceSample) apply(column string, v gosnmp.SnmpPDU) {
+	switch v.Type {
+	case gosnmp.NoSuchInstance, gosnmp.NoSuchObject, gosnmp.EndOfMibView, gosnmp.Null:
+		if slices.Contains(hcOptionalColumns, column) || slices.Contains(commonOptionalColumns, column) {
+			return
+		}
+		s.err = ErrNoSuchInstance
+		for _, col := range hcCounterColumns {
+			if col == column {
+				s.missingHC = true
+			}
+		}
+		return
+	}
+	val := gosnmp.ToBigInt(v.Value).Uint64()
+	switch column {
+	case OIDifDescr:
+		if b, ok := v.Value.([]byte); ok {
+			s.descr = string(b)
+		}
+	case OIDifOperStatus:
+		s.status = operStatusName(gosnmp.ToBigInt(v.Value).Int64())
+	case OIDifHCInOctets, OIDifInOctets:
+		s.counters.InOctets = val
+	case OIDifHCOutOctets, OIDifOutOctets:
+		s.counters.OutOctets = val
+	case OIDifHCInUcastPkts, OIDifInUcastPkts:
+		s.counters.InUcastPkts = val
+	case OIDifHCOutUcastPkts, OIDifOutUcastPkts:
+		s.counters.OutUcastPkts = val
+	case OIDifHCInMulticastPkts, OIDifInNUcastPkts:
+		// ifInNUcastPkts lumps multicast and broadcast together; it is
+		// reported as multicast since it cannot be split.
+		s.counters.InMcastPkts = val
+	case OIDifHCOutMulticastPkts, OIDifOutNUcastPkts:
+		s.counters.OutMcastPkts = val
+	case OIDifHCInBroadcastPkts:
+		s.counters.InBcastPkts = val
+	case OIDifHCOutBroadcastPkts:
+		s.counters.OutBcastPkts = val
+	case OIDifInErrors:
+		s.counters.InErrors = val
+	case OIDifOutErrors:
+		s.counters.OutErrors = val
+	case OIDifInDiscards:
+		s.counters.InDiscards = val
+	case OIDifOutDiscards:
+		s.counters.OutDiscards = val
+	case OIDifCounterDiscontinuityTime:
+		s.counters.Discontinuity = uint32(val)
+	case OIDifLastChange:
+		s.lastChange = uint32(val)
+		s.hasLastChange = true
+	}
+}
+
+// operStatusName maps an ifOperStatus value to the status strings used in
+// InterfaceStats.
+func operStatusName(v int64) string {
+	switch v {
+	case 1:
+		return "up"
+	case 2:
+		return "down"
+	case 3:
+		return "testing"
+	default:
+		return "unknown"
+	}
+}
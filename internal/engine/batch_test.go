@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+func TestSplitColumnOID(t *testing.T) {
+	col, idx, ok := splitColumnOID("."+OIDifHCOutOctets+".12", allInterfaceColumns)
+	if !ok {
+		t.Fatal("expected OID to match a column")
+	}
+	if col != OIDifHCOutOctets || idx != 12 {
+		t.Errorf("expected (%s, 12), got (%s, %d)", OIDifHCOutOctets, col, idx)
+	}
+
+	if _, _, ok := splitColumnOID(OIDifName+".3", allInterfaceColumns); ok {
+		t.Error("OID outside the polled columns should not match")
+	}
+	if _, _, ok := splitColumnOID(OIDifHCInOctets+".1.2", allInterfaceColumns); ok {
+		t.Error("multi-component index should not match")
+	}
+}
+
+func TestUseBulkWalk(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   gosnmp.SnmpVersion
+		monitored int
+		table     int
+		want      bool
+	}{
+		{"most of table", gosnmp.Version2c, 48, 52, true},
+		{"few ports", gosnmp.Version2c, 4, 52, false},
+		{"small table", gosnmp.Version2c, 4, 4, false},
+		{"unknown table size", gosnmp.Version2c, 48, 0, false},
+		{"v1 has no getbulk", gosnmp.Version1, 48, 52, false},
+	}
+	for _, tt := range tests {
+		if got := useBulkWalk(tt.version, tt.monitored, tt.table); got != tt.want {
+			t.Errorf("%s: useBulkWalk() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestInterfaceSampleApply(t *testing.T) {
+	var s interfaceSample
+	s.apply(OIDifHCInOctets, gosnmp.SnmpPDU{Type: gosnmp.Counter64, Value: uint64(1000)})
+	s.apply(OIDifHCOutOctets, gosnmp.SnmpPDU{Type: gosnmp.Counter64, Value: uint64(2000)})
+	s.apply(OIDifOperStatus, gosnmp.SnmpPDU{Type: gosnmp.Integer, Value: 2})
+	if s.err != nil {
+		t.Fatalf("unexpected error: %v", s.err)
+	}
+	if s.counters.InOctets != 1000 || s.counters.OutOctets != 2000 {
+		t.Errorf("unexpected counters: %+v", s.counters)
+	}
+	if s.status != "down" {
+		t.Errorf("expected status down, got %q", s.status)
+	}
+
+	s.apply(OIDifCounterDiscontinuityTime, gosnmp.SnmpPDU{Type: gosnmp.NoSuchObject})
+	if s.err != nil {
+		t.Errorf("missing optional column should be ignored, got %v", s.err)
+	}
+	s.apply(OIDifLastChange, gosnmp.SnmpPDU{Type: gosnmp.NoSuchInstance})
+	if s.err != nil || s.hasLastChange {
+		t.Errorf("missing ifLastChange should be ignored, got %v", s.err)
+	}
+	s.apply(OIDifLastChange, gosnmp.SnmpPDU{Type: gosnmp.TimeTicks, Value: uint32(4200)})
+	if !s.hasLastChange || s.lastChange != 4200 {
+		t.Errorf("expected ifLastChange 4200, got %d", s.lastChange)
+	}
+
+	s.apply(OIDifHCInOctets, gosnmp.SnmpPDU{Type: gosnmp.NoSuchInstance})
+	if s.err != ErrNoSuchInstance {
+		t.Errorf("expected ErrNoSuchInstance, got %v", s.err)
+	}
+	if !s.missingHC {
+		t.Error("missing 64-bit counter should request a 32-bit fallback")
+	}
+}
+
+func TestInterfaceColumnsByWidth(t *testing.T) {
+	for _, col := range interfaceColumns(counters32) {
+		for _, hc := range hcCounterColumns {
+			if col == hc {
+				t.Errorf("32-bit column set should not include %s", hc)
+			}
+		}
+	}
+	if cols := interfaceColumns(counters64); cols[0] != OIDifHCInOctets {
+		t.Errorf("64-bit column set should start with ifHCInOctets, got %s", cols[0])
+	}
+}
+
+func TestTargetStateCounterBits(t *testing.T) {
+	st := &targetState{legacyCounters: map[int]bool{7: true}}
+	if bits := st.counterBits(1, gosnmp.Version2c); bits != counters64 {
+		t.Errorf("expected 64-bit counters for v2c, got %d", bits)
+	}
+	if bits := st.counterBits(7, gosnmp.Version2c); bits != counters32 {
+		t.Errorf("expected 32-bit counters for a legacy interface, got %d", bits)
+	}
+	if bits := st.counterBits(1, gosnmp.Version1); bits != counters32 {
+		t.Errorf("expected 32-bit counters for SNMPv1, got %d", bits)
+	}
+}
@@ -0,0 +1,374 @@
+package engine
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// rediscoverInterval is how long a target that reported none of the OIDs
+// a plan looks for waits before its tables are walked again.
+const rediscoverInterval = 30 * time.Minute
+
+// discovery records when a per-target plan of OIDs to poll was learned.
+type discovery struct {
+	learnedAt time.Time
+	stale     bool // a learned instance has disappeared
+}
+
+// due reports whether a learned plan should be relearned: once the retry
+// interval has passed after an instance disappeared, and periodically while
+// the device reported nothing.
+func (d discovery) due(now time.Time, empty bool) bool {
+	switch {
+	case d.stale:
+		return now.Sub(d.learnedAt) >= resolveRetryInterval
+	case empty:
+		return now.Sub(d.learnedAt) >= rediscoverInterval
+	}
+	return false
+}
+
+// learnedPlan is a per-target plan of OIDs to poll, learned by discovery.
+type learnedPlan interface {
+	comparable
+	empty() bool
+	due(now time.Time, empty bool) bool
+}
+
+// needsDiscovery reports whether a plan must be (re)learned: on the first
+// poll, after an agent restart, and when its discovery is due.
+func needsDiscovery[P learnedPlan](plan P, now time.Time, restarted bool) bool {
+	var none P
+	return plan == none || restarted || plan.due(now, plan.empty())
+}
+
+// devicePlan records which device health OIDs a target answers. It is
+// learned by walking the relevant tables once, so each poll cycle only
+// needs a GET of the instances found.
+type devicePlan struct {
+	discovery
+	cpu     []string    // per-processor load OIDs, averaged
+	memory  []usagePlan // RAM areas or memory pools, summed
+	memPct  []string    // memory utilization OIDs in percent, averaged
+	storage []usagePlan // disks and flash file systems
+}
+
+// usagePlan describes how to read the used and total bytes of one memory or
+// storage area. The total is read from size, or computed as used plus free
+// when the MIB reports free space instead.
+type usagePlan struct {
+	descr string
+	used  string
+	size  string
+	free  string
+	units uint64 // bytes per unit
+}
+
+// empty reports whether the device answered none of the health OIDs.
+func (d *devicePlan) empty() bool {
+	return len(d.cpu) == 0 && len(d.memory) == 0 && len(d.memPct) == 0 && len(d.storage) == 0
+}
+
+// oids returns every OID the plan polls.
+func (d *devicePlan) oids() []string {
+	names := slices.Clone(d.cpu)
+	names = append(names, d.memPct...)
+	for _, u := range slices.Concat(d.memory, d.storage) {
+		names = append(names, u.used)
+		if u.size != "" {
+			names = append(names, u.size)
+		}
+		if u.free != "" {
+			names = append(names, u.free)
+		}
+	}
+	return names
+}
+
+// discoverDevice walks the device health tables and plans what to poll.
+// HOST-RESOURCES-MIB is preferred; the Cisco and Juniper tables are only
+// walked for what it does not cover.
+func discoverDevice(client *gosnmp.GoSNMP) (*devicePlan, error) {
+	tables := make(map[string]map[string]gosnmp.SnmpPDU)
+	walk := func(columns ...string) error {
+		return walkColumns(client, tables, columns...)
+	}
+
+	if err := walk(OIDhrProcessorLoad, OIDhrStorageType, OIDhrStorageDescr, OIDhrStorageAllocationUnits); err != nil {
+		return nil, err
+	}
+	plan := planDevice(tables)
+	if len(plan.cpu) == 0 || len(plan.memory) == 0 {
+		if err := walk(OIDcpmCPUTotal1minRev, OIDciscoMemoryPoolUsed); err != nil {
+			return nil, err
+		}
+		plan = planDevice(tables)
+	}
+	if len(plan.cpu) == 0 || len(plan.memory) == 0 {
+		if err := walk(OIDjnxOperatingCPU, OIDjnxOperatingBuffer); err != nil {
+			return nil, err
+		}
+		plan = planDevice(tables)
+	}
+	return plan, nil
+}
+
+// walkColumns walks table columns into tables, keyed by column OID.
+func walkColumns(client *gosnmp.GoSNMP, tables map[string]map[string]gosnmp.SnmpPDU, columns ...string) error {
+	for _, col := range columns {
+		rows, err := walkColumn(client, col)
+		if err != nil {
+			return err
+		}
+		tables[col] = rows
+	}
+	return nil
+}
+
+// walkColumn walks a table column and returns its rows keyed by instance
+// suffix. Unlike walkOID it reports errors, so a timeout is not mistaken
+// for a device without the table.
+func walkColumn(client *gosnmp.GoSNMP, column string) (map[string]gosnmp.SnmpPDU, error) {
+	rows := make(map[string]gosnmp.SnmpPDU)
+	walk := client.BulkWalk
+	if client.Version == gosnmp.Version1 {
+		walk = client.Walk
+	}
+	err := walk(column, func(pdu gosnmp.SnmpPDU) error {
+		if suffix, ok := strings.CutPrefix(strings.TrimPrefix(pdu.Name, "."), column+"."); ok {
+			rows[suffix] = pdu
+		}
+		return nil
+	})
+	return rows, err
+}
+
+// planDevice builds a poll plan from walked table columns. CPU and memory
+// each come from the first MIB that has them: HOST-RESOURCES-MIB, then the
+// Cisco process and memory pool MIBs, then the Juniper operating table.
+func planDevice(tables map[string]map[string]gosnmp.SnmpPDU) *devicePlan {
+	plan := &devicePlan{}
+
+	for _, idx := range sortedInstances(tables[OIDhrProcessorLoad]) {
+		plan.cpu = append(plan.cpu, OIDhrProcessorLoad+"."+idx)
+	}
+	types := tables[OIDhrStorageType]
+	for _, idx := range sortedInstances(types) {
+		u := usagePlan{
+			descr: pduString(tables[OIDhrStorageDescr][idx]),
+			used:  OIDhrStorageUsed + "." + idx,
+			size:  OIDhrStorageSize + "." + idx,
+			units: gosnmp.ToBigInt(tables[OIDhrStorageAllocationUnits][idx].Value).Uint64(),
+		}
+		if u.units == 0 {
+			continue
+		}
+		switch strings.TrimPrefix(pduString(types[idx]), ".") {
+		case OIDhrStorageRam:
+			plan.memory = append(plan.memory, u)
+		case OIDhrStorageFixedDisk, OIDhrStorageFlashMemory:
+			plan.storage = append(plan.storage, u)
+		}
+	}
+
+	if len(plan.cpu) == 0 {
+		for _, idx := range sortedInstances(tables[OIDcpmCPUTotal1minRev]) {
+			plan.cpu = append(plan.cpu, OIDcpmCPUTotal1minRev+"."+idx)
+		}
+	}
+	// Pool 1 is the processor pool; the others are I/O buffers.
+	if _, ok := tables[OIDciscoMemoryPoolUsed]["1"]; ok && len(plan.memory) == 0 {
+		plan.memory = append(plan.memory, usagePlan{
+			descr: "Processor",
+			used:  OIDciscoMemoryPoolUsed + ".1",
+			free:  OIDciscoMemoryPoolFree + ".1",
+			units: 1,
+		})
+	}
+
+	// The Juniper operating table lists every chassis component; only
+	// routing engines and line cards report a memory utilization.
+	if needCPU, needMem := len(plan.cpu) == 0, len(plan.memory) == 0; needCPU || needMem {
+		buffers := tables[OIDjnxOperatingBuffer]
+		for _, idx := range sortedInstances(buffers) {
+			if gosnmp.ToBigInt(buffers[idx].Value).Sign() <= 0 {
+				continue
+			}
+			if _, ok := tables[OIDjnxOperatingCPU][idx]; ok && needCPU {
+				plan.cpu = append(plan.cpu, OIDjnxOperatingCPU+"."+idx)
+			}
+			if needMem {
+				plan.memPct = append(plan.memPct, OIDjnxOperatingBuffer+"."+idx)
+			}
+		}
+	}
+	return plan
+}
+
+// sortedInstances returns the instance suffixes of a walked column in
+// numeric OID order.
+func sortedInstances(rows map[string]gosnmp.SnmpPDU) []string {
+	keys := make([]string, 0, len(rows))
+	for k := range rows {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, compareOIDs)
+	return keys
+}
+
+// compareOIDs orders dotted OID strings by their numeric components.
+func compareOIDs(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, _ := strconv.Atoi(as[i])
+		y, _ := strconv.Atoi(bs[i])
+		if x != y {
+			return x - y
+		}
+	}
+	return len(as) - len(bs)
+}
+
+// pduString returns a string-valued varbind's value.
+func pduString(v gosnmp.SnmpPDU) string {
+	switch val := v.Value.(type) {
+	case []byte:
+		return string(val)
+	case string:
+		return val
+	}
+	return ""
+}
+
+// evaluate computes device stats from the readings of the plan's OIDs.
+// missing is true when a learned instance no longer exists on the device.
+// Other errors are reported if no value at all could be read.
+func (d *devicePlan) evaluate(readings map[string]metricReading) (stats DeviceStats, missing bool, err error) {
+	read := func(oid string) (float64, bool) {
+		r, ok := readings[oid]
+		switch {
+		case !ok:
+			return 0, false
+		case errors.Is(r.err, ErrNoSuchInstance):
+			missing = true
+			return 0, false
+		case r.err != nil:
+			err = r.err
+			return 0, false
+		}
+		return r.value, true
+	}
+	average := func(oids []string) (float64, bool) {
+		var sum float64
+		n := 0
+		for _, oid := range oids {
+			if v, ok := read(oid); ok {
+				sum += v
+				n++
+			}
+		}
+		if n == 0 {
+			return 0, false
+		}
+		return sum / float64(n), true
+	}
+	usage := func(u usagePlan) (used, total uint64, ok bool) {
+		usedVal, ok := read(u.used)
+		if !ok {
+			return 0, 0, false
+		}
+		used = uint64(usedVal) * u.units
+		if u.free != "" {
+			free, ok := read(u.free)
+			if !ok {
+				return 0, 0, false
+			}
+			return used, used + uint64(free)*u.units, true
+		}
+		size, ok := read(u.size)
+		if !ok || size <= 0 {
+			return 0, 0, false
+		}
+		return used, uint64(size) * u.units, true
+	}
+
+	stats.CPU, stats.HasCPU = average(d.cpu)
+	for _, u := range d.memory {
+		if used, total, ok := usage(u); ok {
+			stats.MemUsed += used
+			stats.MemTotal += total
+		}
+	}
+	if stats.MemTotal > 0 {
+		stats.HasMemory = true
+		stats.Memory = float64(stats.MemUsed) / float64(stats.MemTotal) * 100
+	} else {
+		stats.Memory, stats.HasMemory = average(d.memPct)
+	}
+	for _, u := range d.storage {
+		if used, total, ok := usage(u); ok {
+			stats.Storage = append(stats.Storage, StorageStats{Descr: u.descr, Used: used, Size: total})
+		}
+	}
+
+	if stats.Available() {
+		err = nil
+	}
+	return stats, missing, err
+}
+
+// pollDevice reads the target's CPU, memory and storage. The OIDs to read
+// are learned on the first poll and again after an agent restart, since
+// hrStorage and entity indexes are not stable across reboots. Errors are
+// returned in the stats' PollError.
+func pollDevice(client *gosnmp.GoSNMP, st *targetState, restarted bool) DeviceStats {
+	now := time.Now()
+	if needsDiscovery(st.device, now, restarted) {
+		plan, err := discoverDevice(client)
+		if err != nil {
+			return DeviceStats{PollError: err}
+		}
+		plan.learnedAt = now
+		st.device = plan
+	}
+	if st.device.empty() {
+		return DeviceStats{}
+	}
+
+	names := st.device.oids()
+	readings := getReadings(client, st, names)
+	byOID := make(map[string]metricReading, len(names))
+	for i, name := range names {
+		byOID[name] = readings[i]
+	}
+	stats, missing, err := st.device.evaluate(byOID)
+	if missing {
+		st.device.stale = true
+	}
+	stats.PollError = err
+	return stats
+}
+
+// commitDeviceLocked applies a device health reading to the target's
+// stats. A failed reading keeps the previous values.
+// Must be called while holding the write lock on p.mu.
+func (p *Poller) commitDeviceLocked(ts *TargetStats, reading DeviceStats, now time.Time) {
+	d := &ts.Device
+	if reading.PollError != nil {
+		d.PollError = reading.PollError
+		p.errorCount++
+		return
+	}
+	history := d.History
+	*d = reading
+	d.History = history
+	d.LastPoll = now
+	if d.HasCPU || d.HasMemory {
+		d.History.Add(DeviceSample{Timestamp: now, CPU: d.CPU, Memory: d.Memory})
+	}
+}
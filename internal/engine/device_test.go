@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+func TestPlanDevice(t *testing.T) {
+	hr := map[string]map[string]gosnmp.SnmpPDU{
+		OIDhrProcessorLoad: {
+			"196609": {Value: 10},
+			"196608": {Value: 20},
+		},
+		OIDhrStorageType: {
+			"1":  {Type: gosnmp.ObjectIdentifier, Value: "." + OIDhrStorageRam},
+			"3":  {Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.2.1.25.2.1.3"},
+			"10": {Type: gosnmp.ObjectIdentifier, Value: "." + OIDhrStorageFixedDisk},
+			"31": {Type: gosnmp.ObjectIdentifier, Value: "." + OIDhrStorageFixedDisk},
+		},
+		OIDhrStorageDescr: {
+			"1":  {Value: []byte("Physical memory")},
+			"10": {Value: []byte("/")},
+			"31": {Value: []byte("/var")},
+		},
+		OIDhrStorageAllocationUnits: {
+			"1":  {Value: 1024},
+			"3":  {Value: 1024},
+			"10": {Value: 4096},
+			"31": {Value: 4096},
+		},
+	}
+	plan := planDevice(hr)
+	if want := []string{OIDhrProcessorLoad + ".196608", OIDhrProcessorLoad + ".196609"}; !slices.Equal(plan.cpu, want) {
+		t.Errorf("cpu OIDs = %v, want %v", plan.cpu, want)
+	}
+	if len(plan.memory) != 1 || plan.memory[0].used != OIDhrStorageUsed+".1" || plan.memory[0].units != 1024 {
+		t.Errorf("expected RAM from hrStorage index 1, got %+v", plan.memory)
+	}
+	if len(plan.storage) != 2 || plan.storage[0].descr != "/" || plan.storage[1].descr != "/var" {
+		t.Errorf("expected fixed disks in index order, got %+v", plan.storage)
+	}
+
+	cisco := map[string]map[string]gosnmp.SnmpPDU{
+		OIDcpmCPUTotal1minRev:  {"1": {Value: uint(5)}},
+		OIDciscoMemoryPoolUsed: {"1": {Value: uint(100)}, "2": {Value: uint(10)}},
+	}
+	plan = planDevice(cisco)
+	if len(plan.cpu) != 1 || len(plan.memory) != 1 || plan.memory[0].free != OIDciscoMemoryPoolFree+".1" {
+		t.Errorf("expected Cisco CPU and processor pool, got %+v", plan)
+	}
+
+	juniper := map[string]map[string]gosnmp.SnmpPDU{
+		OIDjnxOperatingCPU:    {"4.1.1.0": {Value: 0}, "9.1.0.0": {Value: 7}},
+		OIDjnxOperatingBuffer: {"4.1.1.0": {Value: 0}, "9.1.0.0": {Value: 40}},
+	}
+	plan = planDevice(juniper)
+	if !slices.Equal(plan.cpu, []string{OIDjnxOperatingCPU + ".9.1.0.0"}) || !slices.Equal(plan.memPct, []string{OIDjnxOperatingBuffer + ".9.1.0.0"}) {
+		t.Errorf("expected only the routing engine row, got cpu %v memory %v", plan.cpu, plan.memPct)
+	}
+
+	if plan := planDevice(nil); !plan.empty() {
+		t.Errorf("expected an empty plan for a device without the MIBs, got %+v", plan)
+	}
+}
+
+func TestDevicePlanEvaluate(t *testing.T) {
+	plan := &devicePlan{
+		cpu:    []string{"cpu.1", "cpu.2"},
+		memory: []usagePlan{{used: "mem.used", size: "mem.size", units: 1024}},
+		storage: []usagePlan{
+			{descr: "/", used: "disk.used", size: "disk.size", units: 4096},
+		},
+	}
+	readings := map[string]metricReading{
+		"cpu.1":     {value: 10},
+		"cpu.2":     {value: 30},
+		"mem.used":  {value: 512},
+		"mem.size":  {value: 2048},
+		"disk.used": {value: 900},
+		"disk.size": {value: 1000},
+	}
+	stats, missing, err := plan.evaluate(readings)
+	if err != nil || missing {
+		t.Fatalf("unexpected missing=%v err=%v", missing, err)
+	}
+	if !stats.HasCPU || stats.CPU != 20 {
+		t.Errorf("expected CPU averaged to 20%%, got %v", stats.CPU)
+	}
+	if !stats.HasMemory || stats.Memory != 25 || stats.MemTotal != 2048*1024 {
+		t.Errorf("expected 25%% of 2 MiB memory, got %v%% of %d", stats.Memory, stats.MemTotal)
+	}
+	if len(stats.Storage) != 1 || stats.Storage[0].Percent() != 90 {
+		t.Errorf("expected / at 90%%, got %+v", stats.Storage)
+	}
+
+	readings["disk.size"] = metricReading{err: ErrNoSuchInstance}
+	stats, missing, _ = plan.evaluate(readings)
+	if !missing || len(stats.Storage) != 0 {
+		t.Errorf("a vanished instance should be reported missing, got missing=%v storage=%v", missing, stats.Storage)
+	}
+}
+
+func TestDevicePlanNeedsDiscovery(t *testing.T) {
+	now := time.Now()
+	var none *devicePlan
+	if !needsDiscovery(none, now, false) {
+		t.Error("an unknown device needs discovery")
+	}
+	plan := &devicePlan{discovery: discovery{learnedAt: now}, cpu: []string{"cpu.1"}}
+	if needsDiscovery(plan, now, false) {
+		t.Error("a fresh plan should be reused")
+	}
+	if !needsDiscovery(plan, now, true) {
+		t.Error("an agent restart should trigger rediscovery")
+	}
+	plan.stale = true
+	if needsDiscovery(plan, now, false) || !needsDiscovery(plan, now.Add(resolveRetryInterval), false) {
+		t.Error("a stale plan should be relearned after the retry interval")
+	}
+	empty := &devicePlan{discovery: discovery{learnedAt: now}}
+	if needsDiscovery(empty, now.Add(resolveRetryInterval), false) || !needsDiscovery(empty, now.Add(rediscoverInterval), false) {
+		t.Error("a device without health OIDs should be checked again after the rediscover interval")
+	}
+}
@@ -20,6 +20,7 @@ type DiscoveredInterface struct {
 	Alias       string
 	Speed       uint64
 	Status      string
+	Members     []int // member ifIndexes of a link aggregate
 }
 
 // DiscoverInterfaces walks a device's interface table and returns all
@@ -222,6 +223,15 @@ func DiscoverDetailedInterfaces(host string, port int, id *identity.Identity, pr
 		}
 	})
 
+	progress("Reading link aggregation members...")
+	if lags, err := discoverLAGMembers(client); err == nil {
+		for agg, members := range lags {
+			if iface, ok := interfaces[agg]; ok {
+				iface.Members = members
+			}
+		}
+	}
+
 	// Phase 3: IP addresses
 	progress("Reading IP addresses...")
 	ipToIfIndex := make(map[string]int)
@@ -368,9 +378,14 @@ func formatMAC(b []byte) string {
 }
 
 // walkOID performs an SNMP BulkWalk on the given OID and calls handler for
-// each PDU, extracting the ifIndex from the last OID component.
+// each PDU, extracting the ifIndex from the last OID component. SNMPv1 has
+// no GETBULK, so v1 clients fall back to a GETNEXT walk.
 func walkOID(client *gosnmp.GoSNMP, oid string, handler func(int, string)) {
-	_ = client.BulkWalk(oid, func(pdu gosnmp.SnmpPDU) error {
+	walk := client.BulkWalk
+	if client.Version == gosnmp.Version1 {
+		walk = client.Walk
+	}
+	_ = walk(oid, func(pdu gosnmp.SnmpPDU) error {
 		parts := strings.Split(pdu.Name, ".")
 		if len(parts) == 0 {
 			return nil
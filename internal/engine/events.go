@@ -0,0 +1,23 @@
+package engine
+
+import "time"
+
+// maxEvents bounds how many recent state changes a poller keeps.
+const maxEvents = 200
+
+// recordEventLocked appends a state change to the poller's event log and
+// the on-disk history. Must be called while holding the write lock on p.mu.
+func (p *Poller) recordEventLocked(ts *TargetStats, kind, subject, from, to, detail string, now time.Time) {
+	e := Event{
+		Time:    now,
+		Kind:    kind,
+		Host:    ts.Host,
+		Target:  ts.Label,
+		Subject: subject,
+		From:    from,
+		To:      to,
+		Detail:  detail,
+	}
+	p.events.Add(e)
+	p.persistEventLocked(e)
+}
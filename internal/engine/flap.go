@@ -0,0 +1,87 @@
+package engine
+
+import "time"
+
+// FlapWindow is the sliding window over which interface status changes are
+// counted.
+const FlapWindow = 10 * time.Minute
+
+// flapThreshold is the number of status changes within FlapWindow from
+// which an interface is considered flapping.
+const flapThreshold = 3
+
+// flapState tracks the operational status transitions of one ifIndex. It is
+// kept per ifIndex rather than per row so that a port shown both as a
+// configured interface and as a LAG member is only counted once.
+type flapState struct {
+	status        string
+	lastChange    uint32 // ifLastChange at the previous poll
+	hasLastChange bool
+	changes       []time.Time // transitions within FlapWindow, oldest first
+}
+
+// observe records a polled status and returns the previous status and the
+// number of transitions since the previous poll. A changed ifLastChange
+// with an unchanged status means the port went down and came back (or the
+// reverse) between polls, which counts as two transitions. ifLastChange
+// restarts with the agent, so it is not compared across a restart.
+func (f *flapState) observe(sample interfaceSample, restarted bool, now time.Time) (from string, transitions int) {
+	from = f.status
+	switch {
+	case from == "":
+	case sample.status != from:
+		transitions = 1
+	case !restarted && sample.hasLastChange && f.hasLastChange && sample.lastChange != f.lastChange:
+		transitions = 2
+	}
+	for range transitions {
+		f.changes = append(f.changes, now)
+	}
+	f.status = sample.status
+	f.lastChange, f.hasLastChange = sample.lastChange, sample.hasLastChange
+
+	cutoff := now.Add(-FlapWindow)
+	i := 0
+	for i < len(f.changes) && !f.changes[i].After(cutoff) {
+		i++
+	}
+	f.changes = f.changes[i:]
+	return from, transitions
+}
+
+// commitFlapsLocked updates an interface's flap count and last change time
+// from a polled sample, and records status changes as events and reports
+// them to subscribers. A bounce between polls is recorded with the same
+// status on both sides. A port polled twice in a cycle, as a configured
+// interface and as a LAG member, sees no change the second time, so its
+// events are recorded once.
+// Must be called while holding the write lock on p.mu.
+func (p *Poller) commitFlapsLocked(ts *TargetStats, iface *InterfaceStats, sample interfaceSample, restarted bool, now time.Time) {
+	st := p.targetStateLocked(ts.Host)
+	f := st.flaps[iface.IfIndex]
+	if f == nil {
+		f = &flapState{}
+		st.flaps[iface.IfIndex] = f
+	}
+	from, transitions := f.observe(sample, restarted, now)
+	var detail string
+	if transitions == 2 {
+		detail = "bounced between polls"
+	}
+	if transitions > 0 {
+		p.recordEventLocked(ts, EventInterfaceStatus, iface.Name, from, sample.status, detail, now)
+		e := targetEvent(InterfaceStatusEvent, ts, now)
+		e.Interface, e.From, e.To, e.Detail = iface.Name, from, sample.status, detail
+		p.publishLocked(e)
+	}
+
+	iface.Flaps = len(f.changes)
+	iface.Flapping = iface.Flaps >= flapThreshold
+	switch {
+	case sample.hasLastChange && sample.lastChange > 0 && !st.uptimeAt.IsZero() && sample.lastChange <= st.uptime:
+		ago := time.Duration(st.uptime-sample.lastChange) * 10 * time.Millisecond
+		iface.LastChange = st.uptimeAt.Add(-ago)
+	case transitions > 0:
+		iface.LastChange = now
+	}
+}
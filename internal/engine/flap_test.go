@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFlapStateObserve(t *testing.T) {
+	var f flapState
+	now := time.Now()
+	sample := func(status string, lastChange uint32) interfaceSample {
+		return interfaceSample{status: status, lastChange: lastChange, hasLastChange: true}
+	}
+
+	if _, n := f.observe(sample("up", 100), false, now); n != 0 {
+		t.Errorf("first observation should not count as a transition, got %d", n)
+	}
+	if from, n := f.observe(sample("down", 200), false, now.Add(time.Minute)); from != "up" || n != 1 {
+		t.Errorf("status change: got from %q, %d transitions", from, n)
+	}
+	if _, n := f.observe(sample("down", 300), false, now.Add(2*time.Minute)); n != 2 {
+		t.Errorf("ifLastChange moving under the same status is a bounce, got %d transitions", n)
+	}
+	if _, n := f.observe(sample("down", 300), false, now.Add(3*time.Minute)); n != 0 {
+		t.Errorf("unchanged sample should not count, got %d", n)
+	}
+	if len(f.changes) != 3 {
+		t.Errorf("expected 3 changes in the window, got %d", len(f.changes))
+	}
+
+	if _, n := f.observe(sample("down", 5), true, now.Add(4*time.Minute)); n != 0 {
+		t.Errorf("ifLastChange should not be compared across a restart, got %d", n)
+	}
+	if _, n := f.observe(sample("down", 5), false, now.Add(FlapWindow+90*time.Second)); n != 0 || len(f.changes) != 2 {
+		t.Errorf("expected the oldest change to leave the window, got %d changes", len(f.changes))
+	}
+	if f.observe(sample("down", 5), false, now.Add(FlapWindow+3*time.Minute)); len(f.changes) != 0 {
+		t.Errorf("expected all changes to leave the window, got %d", len(f.changes))
+	}
+}
+
+func TestCommitFlaps(t *testing.T) {
+	p := newTestPoller(t)
+	ts := p.data["10.0.0.1"]
+	iface := &InterfaceStats{IfIndex: 3, Name: "Gi0/3"}
+	member := &InterfaceStats{IfIndex: 3, Name: "Gi0/3"}
+	now := time.Now()
+
+	for i, status := range []string{"up", "down", "up", "down"} {
+		sample := interfaceSample{status: status}
+		at := now.Add(time.Duration(i) * time.Minute)
+		p.commitFlapsLocked(ts, iface, sample, false, at)
+		p.commitFlapsLocked(ts, member, sample, false, at)
+	}
+	if n := p.events.Len(); n != 3 {
+		t.Errorf("a port polled twice per cycle should record each change once, got %d events", n)
+	}
+	if iface.Flaps != 3 || !iface.Flapping || member.Flaps != 3 {
+		t.Errorf("expected both rows flapping with 3 changes, got %d and %d", iface.Flaps, member.Flaps)
+	}
+	if !iface.LastChange.Equal(now.Add(3 * time.Minute)) {
+		t.Errorf("without ifLastChange the last change is the poll time, got %v", iface.LastChange)
+	}
+
+	// ifLastChange dates the change from sysUpTime.
+	st := p.targetStateLocked("10.0.0.1")
+	st.observeUptime(100000, now)
+	p.commitFlapsLocked(ts, iface, interfaceSample{status: "down", lastChange: 94000, hasLastChange: true}, false, now)
+	if want := now.Add(-time.Minute); !iface.LastChange.Equal(want) {
+		t.Errorf("LastChange = %v, want %v", iface.LastChange, want)
+	}
+}
@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// unreachableAfter is the number of consecutive failed polls after which a
+// target is declared unreachable and polled with backoff.
+const unreachableAfter = 3
+
+// maxBackoff caps the delay between probes of an unreachable target.
+const maxBackoff = 5 * time.Minute
+
+// probeTimeout bounds the single sysUpTime request sent to an unreachable
+// target to see whether it has come back.
+const probeTimeout = 2 * time.Second
+
+// unreachable reports whether the target is currently being backed off.
+func (st *targetState) unreachable() bool {
+	return st.failures >= unreachableAfter
+}
+
+// readUptime reads the target's sysUpTime. While the target is unreachable
+// this is a single fast probe with no retries, so a dead device costs one
+// short timeout per attempt instead of the client's full retry budget.
+func readUptime(client *gosnmp.GoSNMP, st *targetState) (uint32, bool, error) {
+	if !st.unreachable() {
+		return getSysUpTime(client)
+	}
+	timeout, retries := client.Timeout, client.Retries
+	client.Timeout, client.Retries = min(timeout, probeTimeout), 0
+	defer func() {
+		client.Timeout, client.Retries = timeout, retries
+	}()
+	return getSysUpTime(client)
+}
+
+// recordFailureLocked updates a target's health after a poll that could not
+// reach it. After unreachableAfter consecutive failures the target is marked
+// unreachable, which is reported to subscribers, and waits an exponentially
+// growing number of its poll intervals between probes. Must be called while
+// holding the write lock on p.mu.
+func (p *Poller) recordFailureLocked(ts *TargetStats, st *targetState, now time.Time) {
+	if st.failures == 0 {
+		st.failingSince = now
+	}
+	st.failures++
+	if !st.unreachable() {
+		ts.Health = TargetDegraded
+		return
+	}
+	if st.failures == unreachableAfter {
+		e := targetEvent(TargetUnreachableEvent, ts, now)
+		if ts.PollError != nil {
+			e.Detail = ts.PollError.Error()
+		}
+		p.publishLocked(e)
+	}
+
+	interval := p.settings[ts.Host].Interval
+	st.backoff = nextBackoff(st.backoff, int(maxBackoff/interval))
+	st.nextPoll = now.Add(time.Duration(st.backoff) * interval)
+	ts.Health = TargetUnreachable
+	ts.UnreachableSince = st.failingSince
+	ts.NextAttempt = st.nextPoll
+}
+
+// recordSuccessLocked clears a target's failure history after a poll that
+// reached it, reporting the recovery of an unreachable target to
+// subscribers. The target is healthy unless some of its interfaces, custom
+// OIDs or device health OIDs failed.
+// Must be called while holding the write lock on p.mu.
+func (p *Poller) recordSuccessLocked(ts *TargetStats, st *targetState) {
+	if st.unreachable() {
+		e := targetEvent(TargetRecoveredEvent, ts, ts.LastPoll)
+		e.Detail = "unreachable since " + ts.UnreachableSince.Format(time.RFC3339)
+		p.publishLocked(e)
+	}
+	st.failures = 0
+	st.backoff = 0
+	ts.UnreachableSince = time.Time{}
+	ts.NextAttempt = time.Time{}
+
+	ts.Health = TargetHealthy
+	for _, iface := range ts.Interfaces {
+		if iface.PollError != nil {
+			ts.Health = TargetDegraded
+		}
+	}
+	for _, m := range ts.Metrics {
+		if m.PollError != nil {
+			ts.Health = TargetDegraded
+		}
+	}
+	if ts.Device.PollError != nil {
+		ts.Health = TargetDegraded
+	}
+}
+
+// nextBackoff returns the number of poll intervals until the next probe of
+// an unreachable target, doubling from one interval up to limit.
+func nextBackoff(prev, limit int) int {
+	next := prev * 2
+	if prev == 0 {
+		next = 1
+	}
+	if next > limit {
+		next = max(limit, 1)
+	}
+	return next
+}
@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tonhe/flo/internal/dashboard"
+)
+
+func TestNextBackoff(t *testing.T) {
+	var got []int
+	b := 0
+	for i := 0; i < 6; i++ {
+		b = nextBackoff(b, 10)
+		got = append(got, b)
+	}
+	want := []int{1, 2, 4, 8, 10, 10}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("backoff sequence = %v, want %v", got, want)
+		}
+	}
+	if b := nextBackoff(0, 0); b != 1 {
+		t.Errorf("backoff must be at least one interval, got %d", b)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	now := time.Now()
+	tick := time.Second
+	if !due(time.Time{}, now, tick) {
+		t.Error("a target that was never polled is due")
+	}
+	if !due(now.Add(100*time.Millisecond), now, tick) {
+		t.Error("a target due within half a tick should be polled now")
+	}
+	if due(now.Add(2*time.Second), now, tick) {
+		t.Error("a target due in two ticks should wait")
+	}
+
+	if got := nextPollTime(now, now.Add(50*time.Millisecond), 10*time.Second); !got.Equal(now.Add(10 * time.Second)) {
+		t.Errorf("schedule should keep a fixed cadence, got %v", got.Sub(now))
+	}
+	late := now.Add(time.Minute)
+	if got := nextPollTime(now, late, 10*time.Second); !got.Equal(late.Add(10 * time.Second)) {
+		t.Errorf("a schedule that fell behind should restart from now, got %v", got.Sub(late))
+	}
+}
+
+func TestSchedulerTick(t *testing.T) {
+	settings := map[string]dashboard.PollSettings{
+		"core":      {Interval: 2 * time.Second},
+		"satellite": {Interval: 60 * time.Second},
+		"branch":    {Interval: 5 * time.Second},
+	}
+	if tick := schedulerTick(settings, 10*time.Second); tick != time.Second {
+		t.Errorf("expected a 1s tick, got %v", tick)
+	}
+	if tick := schedulerTick(nil, 10*time.Second); tick != 10*time.Second {
+		t.Errorf("expected the dashboard interval with no targets, got %v", tick)
+	}
+	fine := map[string]dashboard.PollSettings{"a": {Interval: 1001 * time.Millisecond}, "b": {Interval: time.Second}}
+	if tick := schedulerTick(fine, time.Second); tick != minSchedulerTick {
+		t.Errorf("expected the tick to be floored at %v, got %v", minSchedulerTick, tick)
+	}
+}
+
+func TestTargetHealthTransitions(t *testing.T) {
+	p := newTestPoller(t, "Gi0/1")
+	ts := p.data["10.0.0.1"]
+	st := p.targetStateLocked("10.0.0.1")
+	start := time.Now()
+
+	p.recordFailureLocked(ts, st, start)
+	if ts.Health != TargetDegraded {
+		t.Fatalf("expected degraded after one failure, got %s", ts.Health)
+	}
+	for i := 1; i < unreachableAfter; i++ {
+		p.recordFailureLocked(ts, st, start.Add(time.Duration(i)*time.Second))
+	}
+	if ts.Health != TargetUnreachable {
+		t.Fatalf("expected unreachable after %d failures, got %s", unreachableAfter, ts.Health)
+	}
+	if !ts.UnreachableSince.Equal(start) {
+		t.Errorf("expected outage to start at the first failure, got %v", ts.UnreachableSince)
+	}
+	last := start.Add(time.Duration(unreachableAfter-1) * time.Second)
+	if !st.unreachable() || !ts.NextAttempt.Equal(last.Add(time.Second)) {
+		t.Errorf("first backoff should probe one interval later, got %v", ts.NextAttempt.Sub(last))
+	}
+
+	p.recordFailureLocked(ts, st, last)
+	if !ts.NextAttempt.Equal(last.Add(2 * time.Second)) {
+		t.Errorf("second backoff should wait two intervals, got %v", ts.NextAttempt.Sub(last))
+	}
+
+	p.recordSuccessLocked(ts, st)
+	if ts.Health != TargetHealthy || !ts.UnreachableSince.IsZero() || st.unreachable() {
+		t.Errorf("expected full recovery, got health %s since %v", ts.Health, ts.UnreachableSince)
+	}
+
+	ts.Interfaces[0].PollError = errors.New("timeout")
+	p.recordSuccessLocked(ts, st)
+	if ts.Health != TargetDegraded {
+		t.Errorf("expected degraded with a failing interface, got %s", ts.Health)
+	}
+}
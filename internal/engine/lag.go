@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// ifTypeLAG is the IANAifType of an IEEE 802.3ad link aggregate.
+const ifTypeLAG = 161
+
+// lagImbalanceMinRate is the bundle rate in bits per second below which
+// member imbalance is not reported; at low rates a few flows decide the
+// hashing and the figure is meaningless.
+const lagImbalanceMinRate = 1e6
+
+// discoverLAGMembers returns the member ifIndexes of each link aggregate,
+// keyed by the aggregate's ifIndex. IEEE8023-LAG-MIB is preferred since it
+// only lists ports attached to a bundle; the ifStackTable is walked instead
+// when the agent does not implement it.
+func discoverLAGMembers(client *gosnmp.GoSNMP) (map[int][]int, error) {
+	tables := make(map[string]map[string]gosnmp.SnmpPDU)
+	if err := walkColumns(client, tables, OIDdot3adAggPortAttachedAggID); err != nil {
+		return nil, err
+	}
+	if len(tables[OIDdot3adAggPortAttachedAggID]) == 0 {
+		if err := walkColumns(client, tables, OIDifType); err != nil {
+			return nil, err
+		}
+		hasLAG := false
+		for _, pdu := range tables[OIDifType] {
+			if pduInt(pdu) == ifTypeLAG {
+				hasLAG = true
+				break
+			}
+		}
+		if !hasLAG {
+			return nil, nil
+		}
+		if err := walkColumns(client, tables, OIDifStackStatus); err != nil {
+			return nil, err
+		}
+	}
+	return lagMembers(tables), nil
+}
+
+// lagMembers builds the aggregate to member mapping from walked columns.
+// dot3adAggPortAttachedAggID is indexed by port and holds the aggregate
+// (0 when detached). ifStackStatus is indexed by higher and lower layer;
+// only active rows whose higher layer is an aggregate are used, since the
+// stack also relates VLAN sub-interfaces and tunnels to their parents.
+func lagMembers(tables map[string]map[string]gosnmp.SnmpPDU) map[int][]int {
+	members := make(map[int][]int)
+	attached := tables[OIDdot3adAggPortAttachedAggID]
+	for _, idx := range sortedInstances(attached) {
+		port, err := strconv.Atoi(idx)
+		agg := pduInt(attached[idx])
+		if err != nil || agg == 0 || agg == port {
+			continue
+		}
+		members[agg] = append(members[agg], port)
+	}
+	if len(attached) > 0 {
+		return members
+	}
+
+	types := tables[OIDifType]
+	stack := tables[OIDifStackStatus]
+	for _, idx := range sortedInstances(stack) {
+		higher, lower, _ := strings.Cut(idx, ".")
+		agg, err1 := strconv.Atoi(higher)
+		port, err2 := strconv.Atoi(lower)
+		if err1 != nil || err2 != nil || agg == 0 || port == 0 || pduInt(stack[idx]) != 1 {
+			continue
+		}
+		if pduInt(types[higher]) == ifTypeLAG {
+			members[agg] = append(members[agg], port)
+		}
+	}
+	return members
+}
+
+// Imbalance reports how unevenly a link aggregate's traffic is spread over
+// its up members, as the percentage by which the busiest member exceeds an
+// even share, taking the worse of the two directions. ok is false for
+// interfaces with fewer than two up members or too little traffic to judge.
+func (s *InterfaceStats) Imbalance() (pct float64, ok bool) {
+	for _, rate := range []func(InterfaceStats) float64{
+		func(m InterfaceStats) float64 { return m.InRate },
+		func(m InterfaceStats) float64 { return m.OutRate },
+	} {
+		var total, busiest float64
+		n := 0
+		for _, m := range s.Members {
+			if m.Status != "up" {
+				continue
+			}
+			r := rate(m)
+			total += r
+			busiest = max(busiest, r)
+			n++
+		}
+		if n < 2 || total < lagImbalanceMinRate {
+			continue
+		}
+		pct = max(pct, (busiest/(total/float64(n))-1)*100)
+		ok = true
+	}
+	return pct, ok
+}
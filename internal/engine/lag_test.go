@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+func TestLAGMembers(t *testing.T) {
+	lacp := map[string]map[string]gosnmp.SnmpPDU{
+		OIDdot3adAggPortAttachedAggID: {
+			"1":  {Value: 100},
+			"2":  {Value: 100},
+			"3":  {Value: 0},
+			"10": {Value: 101},
+		},
+	}
+	got := lagMembers(lacp)
+	if !slices.Equal(got[100], []int{1, 2}) || !slices.Equal(got[101], []int{10}) || len(got) != 2 {
+		t.Errorf("unexpected LACP members %v", got)
+	}
+
+	// ifStackTable: Po1 (100) over ports 1 and 2, a VLAN sub-interface
+	// (200) over port 3, and an inactive row.
+	stack := map[string]map[string]gosnmp.SnmpPDU{
+		OIDifType: {"1": {Value: 6}, "2": {Value: 6}, "3": {Value: 6}, "100": {Value: ifTypeLAG}, "200": {Value: 135}},
+		OIDifStackStatus: {
+			"0.100":   {Value: 1},
+			"100.1":   {Value: 1},
+			"100.2":   {Value: 1},
+			"100.4":   {Value: 2},
+			"200.3":   {Value: 1},
+			"1.0":     {Value: 1},
+			"100.0.1": {Value: 1},
+		},
+	}
+	got = lagMembers(stack)
+	if !slices.Equal(got[100], []int{1, 2}) || len(got) != 1 {
+		t.Errorf("unexpected stack members %v", got)
+	}
+}
+
+func TestImbalance(t *testing.T) {
+	lag := InterfaceStats{Members: []InterfaceStats{
+		{Status: "up", InRate: 7e8, OutRate: 5e8},
+		{Status: "up", InRate: 3e8, OutRate: 5e8},
+		{Status: "down"},
+	}}
+	pct, ok := lag.Imbalance()
+	if !ok || pct < 39.9 || pct > 40.1 {
+		t.Errorf("expected 40%% imbalance, got %v (ok=%v)", pct, ok)
+	}
+
+	lag.Members[0].InRate, lag.Members[1].InRate = 1e3, 0
+	lag.Members[0].OutRate, lag.Members[1].OutRate = 1e3, 0
+	if _, ok := lag.Imbalance(); ok {
+		t.Error("an idle bundle should not report an imbalance")
+	}
+	lag.Members = lag.Members[:1]
+	if _, ok := lag.Imbalance(); ok {
+		t.Error("a single member cannot be imbalanced")
+	}
+}
+
+func TestApplyResolvedLAGMembers(t *testing.T) {
+	p := newTestPoller(t, "Po1", "Gi0/1")
+	resolved := map[string]DiscoveredInterface{
+		"Po1":   {IfIndex: 100, Name: "Po1", Members: []int{1, 2}},
+		"Gi0/1": {IfIndex: 1, Name: "Gi0/1"},
+		"Gi0/2": {IfIndex: 2, Name: "Gi0/2"},
+	}
+	p.applyResolved("10.0.0.1", resolved)
+	ts := p.data["10.0.0.1"]
+	po := &ts.Interfaces[0]
+	if len(po.Members) != 2 || po.Members[0].Name != "Gi0/1" || po.Members[1].IfIndex != 2 {
+		t.Fatalf("unexpected members %+v", po.Members)
+	}
+	if got := p.interfaceIndexes("10.0.0.1"); !slices.Equal(got, []int{100, 1, 1, 2}) {
+		t.Errorf("expected configured interfaces then members, got %v", got)
+	}
+
+	// Gi0/1 is polled twice, as a configured interface and as a member;
+	// both rows must see the same rate.
+	sample := func(octets uint64) interfaceSample {
+		return interfaceSample{status: "up", counters: CounterSample{InOctets: octets, Bits: counters64, Timestamp: time.Unix(int64(octets/1000), 0)}}
+	}
+	indexes := p.interfaceIndexes("10.0.0.1")
+	p.commitTarget(p.dash.Groups[0].Targets[0], targetPoll{indexes: indexes, samples: []interfaceSample{sample(0), sample(0), sample(0), sample(0)}})
+	p.commitTarget(p.dash.Groups[0].Targets[0], targetPoll{indexes: indexes, samples: []interfaceSample{sample(10000), sample(10000), sample(10000), sample(10000)}})
+	if ts.Interfaces[1].InRate == 0 || ts.Interfaces[1].InRate != po.Members[0].InRate {
+		t.Errorf("duplicate port rates differ: %v and %v", ts.Interfaces[1].InRate, po.Members[0].InRate)
+	}
+
+	// Gi0/2 leaves the bundle; its baseline goes, Gi0/1's stays.
+	resolved["Po1"] = DiscoveredInterface{IfIndex: 100, Name: "Po1", Members: []int{1}}
+	p.applyResolved("10.0.0.1", resolved)
+	if len(po.Members) != 1 || po.Members[0].History.Len() == 0 {
+		t.Errorf("remaining member should keep its history, got %+v", po.Members)
+	}
+	if _, ok := p.prevCounters["10.0.0.1"][2]; ok {
+		t.Error("departed member should lose its baseline")
+	}
+	if _, ok := p.prevCounters["10.0.0.1"][1]; !ok {
+		t.Error("configured port should keep its baseline")
+	}
+}
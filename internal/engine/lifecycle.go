@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// errStopping is returned to a poll that tries to open a session while the
+// engine is shutting down.
+var errStopping = errors.New("engine stopping")
+
+// infoLocked returns summary information about the engine.
+// Must be called while holding at least a read lock on p.mu.
+func (p *Poller) infoLocked() EngineInfo {
+	info := EngineInfo{
+		Name:       p.dash.Name,
+		LastPoll:   p.lastPoll,
+		PollCount:  p.pollCount,
+		ErrorCount: p.errorCount,
+	}
+	info.State, info.Reason = p.stateLocked()
+	return info
+}
+
+// stateLocked returns the engine's lifecycle state and, when it is degraded
+// or in error, why. Once the first poll cycle has completed, the state
+// follows the health of the targets: in error when none answered its last
+// poll, degraded when some did not, or some of their interfaces, custom
+// OIDs or device health OIDs failed, or the dashboard file failed to
+// reload. Must be called while holding at least a read lock on p.mu.
+func (p *Poller) stateLocked() (EngineState, string) {
+	if p.phase != EngineRunning {
+		return p.phase, ""
+	}
+
+	var failing []*TargetStats
+	total, degraded := 0, 0
+	for _, group := range p.dash.Groups {
+		for _, target := range group.Targets {
+			ts, ok := p.data[target.Host]
+			if !ok {
+				continue
+			}
+			total++
+			switch {
+			case ts.Health == TargetUnreachable || ts.PollError != nil:
+				failing = append(failing, ts)
+			case ts.Health == TargetDegraded:
+				degraded++
+			}
+		}
+	}
+
+	if total > 0 && len(failing) == total {
+		if total == 1 {
+			return EngineError, targetProblem(failing[0])
+		}
+		return EngineError, fmt.Sprintf("none of %d targets answering; %s", total, targetProblem(failing[0]))
+	}
+	var reasons []string
+	switch len(failing) {
+	case 0:
+	case 1:
+		reasons = append(reasons, targetProblem(failing[0]))
+	default:
+		reasons = append(reasons, fmt.Sprintf("%d of %d targets not answering", len(failing), total))
+	}
+	if degraded > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d of %d targets with errors", degraded, total))
+	}
+	if p.configErr != nil {
+		reasons = append(reasons, "config: "+p.configErr.Error())
+	}
+	if len(reasons) > 0 {
+		return EngineDegraded, strings.Join(reasons, "; ")
+	}
+	return EngineRunning, ""
+}
+
+// targetProblem describes why a target is failing.
+func targetProblem(ts *TargetStats) string {
+	name := ts.Label
+	if name == "" {
+		name = ts.Host
+	}
+	if ts.PollError != nil {
+		return fmt.Sprintf("%s: %v", name, ts.PollError)
+	}
+	return name + " unreachable"
+}
+
+// Stop signals the polling loop to exit and waits until it has. Every SNMP
+// session is closed, which cuts short any poll in flight, and the loop
+// exits once those polls have returned. If ctx ends first, Stop returns its
+// error and the shutdown completes in the background. Stop may be called
+// more than once; each call waits for the same shutdown.
+func (p *Poller) Stop(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		p.mu.Lock()
+		p.phase = EngineStopping
+		p.notify()
+		p.mu.Unlock()
+		close(p.stopCh)
+	})
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shutdown closes all SNMP sessions, waits for the polls in flight to
+// return, ends any recording and marks the engine stopped.
+func (p *Poller) shutdown() {
+	p.cleanup()
+	p.polls.Wait()
+	p.cleanup()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_ = p.stopRecordingLocked()
+	p.phase = EngineStopped
+	p.notify()
+}
+
+// cleanup closes all SNMP connections.
+func (p *Poller) cleanup() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for host, client := range p.clients {
+		if client.Conn != nil {
+			client.Conn.Close()
+		}
+		delete(p.clients, host)
+	}
+	for host := range p.retired {
+		p.closeRetiredLocked(host)
+	}
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tonhe/flo/internal/dashboard"
+)
+
+func TestEngineState(t *testing.T) {
+	p := newTestPoller(t, "Gi0/1")
+	if got := p.Info().State; got != EngineStarting {
+		t.Errorf("expected starting before the first cycle, got %v", got)
+	}
+
+	p.phase = EngineRunning
+	ts := p.data["10.0.0.1"]
+	ts.Health = TargetHealthy
+	if info := p.Info(); info.State != EngineRunning || info.Reason != "" {
+		t.Errorf("expected running with no reason, got %v %q", info.State, info.Reason)
+	}
+
+	ts.PollError = errors.New("request timeout")
+	if info := p.Info(); info.State != EngineError || info.Reason != "sw1: request timeout" {
+		t.Errorf("expected error when no target answers, got %v %q", info.State, info.Reason)
+	}
+
+	if err := p.Reload(reloadedDashboard(p, p.dash.Groups[0].Targets[0], dashboard.Target{Host: "10.0.0.2"})); err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+	p.data["10.0.0.2"].Health = TargetDegraded
+	p.configErr = errors.New("test.toml: bad")
+	info := p.Info()
+	if info.State != EngineDegraded {
+		t.Errorf("expected degraded when some targets fail, got %v", info.State)
+	}
+	for _, want := range []string{"sw1: request timeout", "1 of 2 targets with errors", "config: test.toml: bad"} {
+		if !strings.Contains(info.Reason, want) {
+			t.Errorf("expected reason to mention %q, got %q", want, info.Reason)
+		}
+	}
+}
+
+func TestPollerStop(t *testing.T) {
+	p, err := NewPoller(&dashboard.Dashboard{Name: "empty", Interval: time.Second, MaxHistory: 10}, nil)
+	if err != nil {
+		t.Fatalf("NewPoller() error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.Stop(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected Stop to give up with its context, got %v", err)
+	}
+
+	p, _ = NewPoller(&dashboard.Dashboard{Name: "empty", Interval: time.Second, MaxHistory: 10}, nil)
+	go p.Run()
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error: %v", err)
+	}
+	if got := p.Info().State; got != EngineStopped {
+		t.Errorf("expected stopped after Stop returns, got %v", got)
+	}
+	if err := p.Stop(ctx); err != nil {
+		t.Errorf("a second Stop should return at once, got %v", err)
+	}
+}
+
+func TestManagerStopAll(t *testing.T) {
+	m := NewManager()
+	for _, name := range []string{"a", "b"} {
+		if err := m.Start(&dashboard.Dashboard{Name: name, Interval: time.Second, MaxHistory: 10}, nil); err != nil {
+			t.Fatalf("Start() error: %v", err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.StopAll(ctx); err != nil {
+		t.Fatalf("StopAll() error: %v", err)
+	}
+	if got := m.ListEngines(); len(got) != 0 {
+		t.Errorf("expected no engines after StopAll, got %v", got)
+	}
+}
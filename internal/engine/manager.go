@@ -1,26 +1,51 @@
 package engine
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
 	"sync"
 
 	"github.com/tonhe/flo/internal/dashboard"
+	"github.com/tonhe/flo/internal/history"
 	"github.com/tonhe/flo/internal/identity"
 )
 
 // Manager coordinates multiple Pollers, one per dashboard.
 type Manager struct {
-	mu      sync.RWMutex
-	engines map[string]*Poller
+	mu        sync.RWMutex
+	engines   map[string]*Poller
+	store     *history.Store
+	events    *eventHub
+	watchStop chan struct{} // closed to stop watching dashboard files
 }
 
 // NewManager creates an empty Manager.
 func NewManager() *Manager {
 	return &Manager{
 		engines: make(map[string]*Poller),
+		events:  newEventHub(),
 	}
 }
 
+// SetStore makes engines started from now on persist interface rates to
+// store.
+func (m *Manager) SetStore(store *history.Store) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store = store
+}
+
+// Store returns the on-disk history engines write to, or nil if none.
+func (m *Manager) Store() *history.Store {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.store
+}
+
 // Start creates and launches a Poller for the given dashboard.
 func (m *Manager) Start(dash *dashboard.Dashboard, provider identity.Provider) error {
 	m.mu.Lock()
@@ -34,27 +59,99 @@ func (m *Manager) Start(dash *dashboard.Dashboard, provider identity.Provider) e
 	if err != nil {
 		return err
 	}
+	p.SetStore(m.store)
+	p.hub = m.events
 
 	m.engines[dash.Name] = p
 	go p.Run()
 	return nil
 }
 
-// Stop halts the Poller for the named dashboard and removes it.
-func (m *Manager) Stop(name string) error {
+// Update applies a modified definition of a running dashboard to its
+// engine without restarting it, so that everything still configured keeps
+// its history and counter baselines. See Poller.Reload for what changes.
+func (m *Manager) Update(dash *dashboard.Dashboard) error {
+	p := m.engine(dash.Name)
+	if p == nil {
+		return fmt.Errorf("engine %q not found", dash.Name)
+	}
+	return p.Reload(dash)
+}
+
+// Replay starts an engine playing back the recording at path, speed times
+// faster than it was recorded, and returns its name. See NewReplay.
+func (m *Manager) Replay(path string, speed float64) (string, error) {
+	p, err := NewReplay(path, speed)
+	if err != nil {
+		return "", err
+	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	name := p.dash.Name
+	if _, exists := m.engines[name]; exists {
+		p.replay.in.Close()
+		return "", fmt.Errorf("engine %q already running", name)
+	}
+	p.hub = m.events
 
-	p, ok := m.engines[name]
-	if !ok {
+	m.engines[name] = p
+	go p.Run()
+	return name, nil
+}
+
+// Record starts recording the named engine to a new file at path. See
+// Poller.StartRecording.
+func (m *Manager) Record(name, path string) error {
+	p := m.engine(name)
+	if p == nil {
 		return fmt.Errorf("engine %q not found", name)
 	}
-
-	p.Stop()
-	delete(m.engines, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if err := p.StartRecording(f, filepath.Base(path)); err != nil {
+		os.Remove(path)
+		return err
+	}
 	return nil
 }
 
+// StopRecording ends the named engine's recording.
+func (m *Manager) StopRecording(name string) error {
+	p := m.engine(name)
+	if p == nil {
+		return fmt.Errorf("engine %q not found", name)
+	}
+	return p.StopRecording()
+}
+
+// Stop halts the Poller for the named dashboard and removes it, waiting
+// for it to shut down until ctx ends. The engine is listed, as stopping,
+// until then. If ctx ends first, its error is returned and the engine is
+// removed anyway, finishing its shutdown in the background.
+func (m *Manager) Stop(ctx context.Context, name string) error {
+	p := m.engine(name)
+	if p == nil {
+		return fmt.Errorf("engine %q not found", name)
+	}
+	err := p.Stop(ctx)
+	m.remove(name, p)
+	return err
+}
+
+// remove deletes the named engine if it is still p.
+func (m *Manager) remove(name string, p *Poller) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.engines[name] == p {
+		delete(m.engines, name)
+	}
+}
+
 // GetSnapshot returns a point-in-time snapshot for the named dashboard.
 func (m *Manager) GetSnapshot(name string) (*DashboardSnapshot, error) {
 	m.mu.RLock()
@@ -92,16 +189,17 @@ func (m *Manager) TryListEngines() []EngineInfo {
 	return infos
 }
 
-// Subscribe returns a channel that receives events for the named dashboard.
-func (m *Manager) Subscribe(name string) (<-chan EngineEvent, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	p, ok := m.engines[name]
-	if !ok {
-		return nil, fmt.Errorf("engine %q not found", name)
-	}
-	return p.Subscribe(), nil
+// Subscribe returns a subscription to the events of every engine, current
+// and future, that filter selects, with room for buffer undelivered events
+// (DefaultEventBuffer if buffer is 0).
+//
+// Delivery never blocks an engine: events are sent in the order each
+// engine produces them, and an event that finds the subscription's buffer
+// full is dropped and counted in Dropped. Subscribers that cannot afford to
+// miss a PollCompletedEvent can still read the latest state with
+// TryGetSnapshot. Call Unsubscribe when done.
+func (m *Manager) Subscribe(filter EventFilter, buffer int) *Subscription {
+	return m.events.subscribe(filter, buffer)
 }
 
 // ListEngines returns summary info for all running engines.
@@ -116,13 +214,33 @@ func (m *Manager) ListEngines() []EngineInfo {
 	return infos
 }
 
-// StopAll halts and removes all running engines.
-func (m *Manager) StopAll() {
+// StopAll halts and removes all running engines, and stops watching the
+// dashboard files. Engines shut down in parallel, and StopAll waits for
+// them like Stop.
+func (m *Manager) StopAll(ctx context.Context) error {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	for name, p := range m.engines {
-		p.Stop()
-		delete(m.engines, name)
+	if m.watchStop != nil {
+		close(m.watchStop)
+		m.watchStop = nil
+	}
+	engines := maps.Clone(m.engines)
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	var errs []error
+	for name, p := range engines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.Stop(ctx); err != nil {
+				errMu.Lock()
+				errs = append(errs, fmt.Errorf("engine %q: %w", name, err))
+				errMu.Unlock()
+			}
+			m.remove(name, p)
+		}()
 	}
+	wg.Wait()
+	return errors.Join(errs...)
 }
@@ -0,0 +1,178 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/tonhe/flo/internal/dashboard"
+)
+
+// metricReading is the decoded result of polling one custom OID. Counters
+// keep their raw value and width so a rate can be computed against the
+// previous reading; everything else is read as a plain number.
+type metricReading struct {
+	value float64
+	raw   uint64
+	bits  int // 32 or 64 for SNMP counter types, 0 otherwise
+	at    time.Time
+	err   error
+}
+
+// getMetricReadings GETs the target's custom OIDs.
+func getMetricReadings(client *gosnmp.GoSNMP, state *targetState, oids []dashboard.CustomOID) []metricReading {
+	names := make([]string, len(oids))
+	for i, oid := range oids {
+		names[i] = oid.OID
+	}
+	return getReadings(client, state, names)
+}
+
+// getReadings GETs scalar OIDs, packed into as few PDUs as the target's
+// learned PDU size allows. As with interfaces, a tooBig response halves the
+// PDU size and an SNMPv1 noSuchName retries the batch one OID at a time.
+func getReadings(client *gosnmp.GoSNMP, state *targetState, oids []string) []metricReading {
+	readings := make([]metricReading, len(oids))
+	var fetch func(positions []int)
+	fetch = func(positions []int) {
+		for start := 0; start < len(positions); {
+			end := min(start+state.oidsPerPDU, len(positions))
+			batch := positions[start:end]
+			names := make([]string, len(batch))
+			for i, pos := range batch {
+				names[i] = oids[pos]
+			}
+
+			result, err := client.Get(names)
+			if err != nil {
+				for _, pos := range positions[start:] {
+					readings[pos].err = err
+				}
+				return
+			}
+			if result.Error == gosnmp.TooBig && len(batch) > 1 {
+				state.oidsPerPDU = max(state.oidsPerPDU/2, 1)
+				continue
+			}
+			if result.Error == gosnmp.NoSuchName && len(batch) > 1 {
+				for _, pos := range batch {
+					fetch([]int{pos})
+				}
+				start = end
+				continue
+			}
+			now := time.Now()
+			for i, pos := range batch {
+				switch {
+				case result.Error == gosnmp.NoSuchName:
+					readings[pos].err = ErrNoSuchInstance
+				case result.Error != gosnmp.NoError:
+					readings[pos].err = fmt.Errorf("agent returned %s", result.Error)
+				case i >= len(result.Variables):
+					readings[pos].err = ErrNoSuchInstance
+				default:
+					readings[pos] = decodeMetric(result.Variables[i])
+				}
+				readings[pos].at = now
+			}
+			start = end
+		}
+	}
+
+	positions := make([]int, len(oids))
+	for i := range positions {
+		positions[i] = i
+	}
+	fetch(positions)
+	return readings
+}
+
+// decodeMetric converts a varbind into a metric reading. Numeric strings are
+// accepted since many MIBs report gauges such as temperatures as text.
+func decodeMetric(v gosnmp.SnmpPDU) metricReading {
+	switch v.Type {
+	case gosnmp.Counter32:
+		raw := gosnmp.ToBigInt(v.Value).Uint64()
+		return metricReading{value: float64(raw), raw: raw, bits: counters32}
+	case gosnmp.Counter64:
+		raw := gosnmp.ToBigInt(v.Value).Uint64()
+		return metricReading{value: float64(raw), raw: raw, bits: counters64}
+	case gosnmp.Integer, gosnmp.Gauge32, gosnmp.Uinteger32, gosnmp.TimeTicks:
+		n := gosnmp.ToBigInt(v.Value)
+		return metricReading{value: float64(n.Int64()), raw: n.Uint64()}
+	case gosnmp.OpaqueFloat:
+		if f, ok := v.Value.(float32); ok {
+			return metricReading{value: float64(f)}
+		}
+	case gosnmp.OpaqueDouble:
+		if f, ok := v.Value.(float64); ok {
+			return metricReading{value: f}
+		}
+	case gosnmp.OctetString:
+		if b, ok := v.Value.([]byte); ok {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(string(b)), 64); err == nil {
+				return metricReading{value: f}
+			}
+			return metricReading{err: fmt.Errorf("non-numeric value %q", b)}
+		}
+	case gosnmp.NoSuchInstance, gosnmp.NoSuchObject, gosnmp.EndOfMibView, gosnmp.Null:
+		return metricReading{err: ErrNoSuchInstance}
+	}
+	return metricReading{err: fmt.Errorf("unsupported value type %s", v.Type)}
+}
+
+// metricValue turns a reading into the value recorded for a custom OID. A
+// gauge is its scaled reading. A counter is the scaled per-second rate since
+// the previous reading; ok is false when there is no usable previous
+// reading, e.g. on the first poll or after the agent restarted.
+func metricValue(oid dashboard.CustomOID, prev, curr metricReading, restarted bool) (float64, bool) {
+	scale := oid.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	if oid.Type != dashboard.OIDKindCounter {
+		return curr.value * scale, true
+	}
+
+	if restarted || prev.at.IsZero() || prev.err != nil || prev.bits != curr.bits {
+		return 0, false
+	}
+	elapsed := curr.at.Sub(prev.at).Seconds()
+	if elapsed <= 0 {
+		return 0, false
+	}
+	delta, ok := counterDelta(prev.raw, curr.raw, curr.bits)
+	if !ok {
+		return 0, false
+	}
+	return float64(delta) / elapsed * scale, true
+}
+
+// commitMetricsLocked applies custom OID readings to the target's stats.
+// Must be called while holding the write lock on p.mu.
+func (p *Poller) commitMetricsLocked(ts *TargetStats, st *targetState, oids []dashboard.CustomOID, readings []metricReading, restarted bool, now time.Time) {
+	if len(st.metricPrev) != len(oids) {
+		st.metricPrev = make([]metricReading, len(oids))
+	}
+	for i := range ts.Metrics {
+		if i >= len(readings) || i >= len(oids) {
+			break
+		}
+		m := &ts.Metrics[i]
+		r := readings[i]
+		if r.err != nil {
+			m.PollError = r.err
+			p.errorCount++
+			continue
+		}
+		if value, ok := metricValue(oids[i], st.metricPrev[i], r, restarted); ok {
+			m.Value = value
+			m.History.Add(MetricSample{Timestamp: r.at, Value: value})
+		}
+		st.metricPrev[i] = r
+		m.PollError = nil
+		m.LastPoll = now
+	}
+}
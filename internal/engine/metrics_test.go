@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/tonhe/flo/internal/dashboard"
+)
+
+func TestDecodeMetric(t *testing.T) {
+	tests := []struct {
+		name  string
+		pdu   gosnmp.SnmpPDU
+		value float64
+		bits  int
+	}{
+		{"gauge", gosnmp.SnmpPDU{Type: gosnmp.Gauge32, Value: uint(42)}, 42, 0},
+		{"negative integer", gosnmp.SnmpPDU{Type: gosnmp.Integer, Value: -5}, -5, 0},
+		{"counter32", gosnmp.SnmpPDU{Type: gosnmp.Counter32, Value: uint(7)}, 7, counters32},
+		{"counter64", gosnmp.SnmpPDU{Type: gosnmp.Counter64, Value: uint64(9)}, 9, counters64},
+		{"numeric string", gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte(" 36.5 ")}, 36.5, 0},
+	}
+	for _, tt := range tests {
+		r := decodeMetric(tt.pdu)
+		if r.err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, r.err)
+			continue
+		}
+		if r.value != tt.value || r.bits != tt.bits {
+			t.Errorf("%s: got value %v bits %d, want %v bits %d", tt.name, r.value, r.bits, tt.value, tt.bits)
+		}
+	}
+
+	if r := decodeMetric(gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte("n/a")}); r.err == nil {
+		t.Error("expected an error for a non-numeric string")
+	}
+	if r := decodeMetric(gosnmp.SnmpPDU{Type: gosnmp.NoSuchObject}); r.err != ErrNoSuchInstance {
+		t.Errorf("expected ErrNoSuchInstance, got %v", r.err)
+	}
+}
+
+func TestMetricValue(t *testing.T) {
+	now := time.Now()
+	gauge := dashboard.CustomOID{Type: dashboard.OIDKindGauge, Scale: 0.1}
+	if v, ok := metricValue(gauge, metricReading{}, metricReading{value: 250, at: now}, false); !ok || v != 25 {
+		t.Errorf("expected scaled gauge 25, got %v (ok=%v)", v, ok)
+	}
+
+	counter := dashboard.CustomOID{Type: dashboard.OIDKindCounter}
+	prev := metricReading{raw: math.MaxUint32 - 99, bits: counters32, at: now.Add(-10 * time.Second)}
+	curr := metricReading{raw: 900, bits: counters32, at: now}
+	if v, ok := metricValue(counter, prev, curr, false); !ok || v != 100 {
+		t.Errorf("expected 100/s across a 32-bit wrap, got %v (ok=%v)", v, ok)
+	}
+	if _, ok := metricValue(counter, metricReading{}, curr, false); ok {
+		t.Error("a counter needs a previous reading")
+	}
+	if _, ok := metricValue(counter, prev, curr, true); ok {
+		t.Error("a counter must be re-baselined after an agent restart")
+	}
+}
@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/tonhe/flo/internal/history"
+)
+
+// eventLookback is how far back the event log is read when a poller starts.
+const eventLookback = 7 * 24 * time.Hour
+
+// persistRateLocked appends the rate an interface got this cycle to the
+// on-disk history, if the poller has one. It reports whether the interface
+// had a rate to write; one re-baselined after a counter reset has none.
+// Write errors only cost the sample its place in the long-term history, so
+// they are not treated as poll errors.
+// Must be called while holding the write lock on p.mu.
+func (p *Poller) persistRateLocked(host string, iface *InterfaceStats, last CounterSample, sample interfaceSample) bool {
+	if p.store == nil || sample.err != nil || last.Timestamp.IsZero() {
+		return false
+	}
+	rate, ok := iface.History.Last()
+	if !ok || !rate.Timestamp.Equal(sample.counters.Timestamp) {
+		return false
+	}
+	_ = p.store.Append(p.dash.Name, history.Series{Host: host, Interface: iface.Name},
+		toHistorySample(rate, rate.Timestamp.Sub(last.Timestamp)))
+	return true
+}
+
+// persistEventLocked appends an event to the on-disk event log, if the
+// poller has one. Must be called while holding the write lock on p.mu.
+func (p *Poller) persistEventLocked(e Event) {
+	if p.store == nil {
+		return
+	}
+	_ = p.store.AppendEvent(p.dash.Name, history.Event{
+		Time:    e.Time,
+		Kind:    e.Kind,
+		Host:    e.Host,
+		Target:  e.Target,
+		Subject: e.Subject,
+		From:    e.From,
+		To:      e.To,
+		Detail:  e.Detail,
+	})
+}
+
+// restoreRates fills an interface's empty history from the stored samples:
+// its raw history from those over the span it covers at the given poll
+// interval, and its rollups from those over the span of the coarsest tier,
+// so that its graphs carry on across a restart. The store is read a day at
+// a time. Must be called while holding the write lock on p.mu, or before
+// the stats are published.
+func (p *Poller) restoreRates(host string, iface *InterfaceStats, interval time.Duration, now time.Time) {
+	if p.store == nil || iface.History.Len() > 0 {
+		return
+	}
+	series := history.Series{Host: host, Interface: iface.Name}
+	recent := now.Add(-time.Duration(p.dash.MaxHistory+1) * interval)
+	from := now.Add(-rollupSpan())
+	var raw []RateSample
+	for day := from.UTC().Truncate(24 * time.Hour); day.Before(now); day = day.Add(24 * time.Hour) {
+		start, end := day, day.Add(24*time.Hour)
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(now) {
+			end = now
+		}
+		samples, err := p.store.Read(p.dash.Name, series, start, end)
+		if err != nil {
+			break
+		}
+		for _, s := range samples {
+			r := fromHistorySample(s)
+			if iface.Rollups != nil {
+				iface.Rollups.Add(r)
+			}
+			if !s.Time.Before(recent) {
+				raw = append(raw, r)
+			}
+		}
+	}
+	for _, r := range raw[max(len(raw)-p.dash.MaxHistory, 0):] {
+		iface.History.Add(r)
+	}
+}
+
+// restoreEvents returns the most recent stored events of the dashboard, up
+// to maxEvents of them, oldest first.
+func (p *Poller) restoreEvents(now time.Time) []Event {
+	if p.store == nil {
+		return nil
+	}
+	stored, err := p.store.Events(p.dash.Name, now.Add(-eventLookback), now)
+	if err != nil {
+		return nil
+	}
+	stored = stored[max(len(stored)-maxEvents, 0):]
+	events := make([]Event, len(stored))
+	for i, e := range stored {
+		events[i] = Event{
+			Time:    e.Time,
+			Kind:    e.Kind,
+			Host:    e.Host,
+			Target:  e.Target,
+			Subject: e.Subject,
+			From:    e.From,
+			To:      e.To,
+			Detail:  e.Detail,
+		}
+	}
+	return events
+}
+
+// toHistorySample converts a rate into a stored sample covering interval.
+func toHistorySample(r RateSample, interval time.Duration) history.Sample {
+	return history.Sample{
+		Time:        r.Timestamp,
+		Interval:    interval,
+		InRate:      r.InRate,
+		OutRate:     r.OutRate,
+		InPkts:      history.Packets(r.InPPS),
+		OutPkts:     history.Packets(r.OutPPS),
+		InErrors:    r.InErrors,
+		OutErrors:   r.OutErrors,
+		InDiscards:  r.InDiscards,
+		OutDiscards: r.OutDiscards,
+	}
+}
+
+// fromHistorySample converts a stored sample back into a rate.
+func fromHistorySample(s history.Sample) RateSample {
+	r := RateSample{
+		Timestamp:   s.Time,
+		InRate:      s.InRate,
+		OutRate:     s.OutRate,
+		InPPS:       PacketRates(s.InPkts),
+		OutPPS:      PacketRates(s.OutPkts),
+		InErrors:    s.InErrors,
+		OutErrors:   s.OutErrors,
+		InDiscards:  s.InDiscards,
+		OutDiscards: s.OutDiscards,
+	}
+	r.deriveRatios()
+	return r
+}
+
+// SetStore makes the poller persist interface rates and events to store,
+// and restore them when it starts. It must be called before Run.
+func (p *Poller) SetStore(store *history.Store) {
+	p.store = store
+}
@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tonhe/flo/internal/history"
+)
+
+func TestPersistRate(t *testing.T) {
+	p := newTestPoller(t)
+	store := history.NewStore(t.TempDir())
+	p.SetStore(store)
+
+	now := time.Now()
+	last := CounterSample{Timestamp: now.Add(-10 * time.Second)}
+	iface := &InterfaceStats{Name: "Gi0/1", History: NewRingBuffer[RateSample](10)}
+	iface.History.Add(RateSample{Timestamp: now, InRate: 1000, OutRate: 2000})
+	sample := interfaceSample{counters: CounterSample{Timestamp: now}}
+
+	if !p.persistRateLocked("10.0.0.1", iface, last, sample) {
+		t.Fatal("expected the rate to be persisted")
+	}
+	if p.persistRateLocked("10.0.0.1", iface, CounterSample{}, sample) {
+		t.Error("an interface without a baseline has no rate to persist")
+	}
+	if p.persistRateLocked("10.0.0.1", iface, last, interfaceSample{counters: CounterSample{Timestamp: now.Add(time.Second)}}) {
+		t.Error("a sample that added no rate should not be persisted")
+	}
+	if p.persistRateLocked("10.0.0.1", iface, last, interfaceSample{err: errors.New("timeout")}) {
+		t.Error("a failed sample should not be persisted")
+	}
+
+	samples, err := store.Read("test", history.Series{Host: "10.0.0.1", Interface: "Gi0/1"}, now.Add(-time.Minute), now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if len(samples) != 1 || samples[0].Interval != 10*time.Second || samples[0].OutRate != 2000 {
+		t.Errorf("unexpected stored samples %+v", samples)
+	}
+}
+
+func TestRestoreHistory(t *testing.T) {
+	p := newTestPoller(t, "Gi0/1")
+	store := history.NewStore(t.TempDir())
+	p.SetStore(store)
+
+	now := time.Now()
+	series := history.Series{Host: "10.0.0.1", Interface: "Gi0/1"}
+	for i := range 15 {
+		store.Append("test", series, history.Sample{
+			Time:     now.Add(time.Duration(i-15) * time.Second),
+			Interval: time.Second,
+			InRate:   float64(i) * 8000,
+			InPkts:   history.Packets{Unicast: 10},
+		})
+	}
+	for _, age := range []time.Duration{20 * 24 * time.Hour, 3 * 24 * time.Hour} {
+		store.Append("test", series, history.Sample{Time: now.Add(-age), Interval: time.Second, InRate: 500})
+	}
+	ts := p.data["10.0.0.1"]
+	p.recordEventLocked(ts, EventInterfaceStatus, "Gi0/1", "up", "down", "", now.Add(-time.Minute))
+
+	p.initTargetStats()
+	iface := p.data["10.0.0.1"].Interfaces[0]
+	samples := iface.History.All()
+	if len(samples) != 10 {
+		t.Fatalf("expected the last 10 samples to be restored, got %d", len(samples))
+	}
+	if last := samples[len(samples)-1]; last.InRate != 14*8000 || last.InAvgSize != 1400 {
+		t.Errorf("unexpected restored sample %+v", last)
+	}
+	month := iface.RateRange(now.Add(-30*24*time.Hour), now, 0)
+	if len(month) < 3 || !month[0].Start.Equal(now.Add(-20*24*time.Hour).Truncate(time.Minute)) || month[0].InRate.Avg != 500 {
+		t.Errorf("expected the rollups restored from the last 30 days, got %+v", month)
+	}
+	if p.events.Len() != 2 {
+		t.Errorf("expected the stored event to be restored alongside the recorded one, got %d", p.events.Len())
+	}
+	if e, ok := p.events.Last(); !ok || e.Subject != "Gi0/1" || e.To != "down" {
+		t.Errorf("unexpected restored event %+v", e)
+	}
+}
+
+func TestRestorePartialHistory(t *testing.T) {
+	p := newTestPoller(t, "Gi0/1")
+	store := history.NewStore(t.TempDir())
+	p.SetStore(store)
+
+	// A day of 5-minute samples ending an hour ago, then the last 3 polls:
+	// too few to fill the raw history, which reaches back 10 seconds.
+	now := time.Now()
+	series := history.Series{Host: "10.0.0.1", Interface: "Gi0/1"}
+	for i := range 12 * 23 {
+		store.Append("test", series, history.Sample{Time: now.Add(-time.Hour - time.Duration(i)*5*time.Minute), Interval: 5 * time.Minute, InRate: 1000})
+	}
+	for i := range 3 {
+		store.Append("test", series, history.Sample{Time: now.Add(-time.Duration(i+1) * time.Second), Interval: time.Second, InRate: 2000})
+	}
+
+	p.initTargetStats()
+	iface := p.data["10.0.0.1"].Interfaces[0]
+	if n := iface.History.Len(); n != 3 {
+		t.Fatalf("expected the 3 recent samples in the raw history, got %d", n)
+	}
+	day := iface.RateRange(now.Add(-24*time.Hour), now, 0)
+	if len(day) < 12*23 || day[0].Width != time.Minute {
+		t.Errorf("expected the day charted from the 1-minute rollups, got %d buckets", len(day))
+	}
+}
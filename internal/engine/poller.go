@@ -1,7 +1,9 @@
 package engine
 
 import (
-	"fmt"
+	"errors"
+	"maps"
+	"slices"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -9,153 +11,622 @@ import (
 
 	"github.com/gosnmp/gosnmp"
 	"github.com/tonhe/flo/internal/dashboard"
+	"github.com/tonhe/flo/internal/history"
 	"github.com/tonhe/flo/internal/identity"
 )
 
+// maxConcurrentTargets bounds how many targets are polled in parallel. Each
+// in-flight target holds one UDP socket and at most one outstanding request.
+const maxConcurrentTargets = 16
+
+// resolveRetryInterval is how often a target's interface table is re-walked
+// while some of its configured interfaces cannot be found, or while a
+// resolved ifIndex has stopped answering.
+const resolveRetryInterval = 5 * time.Minute
+
+// minSchedulerTick bounds how often the polling loop wakes up to look for
+// targets that are due, however finely their intervals differ.
+const minSchedulerTick = 250 * time.Millisecond
+
 // Poller runs a polling loop for a single dashboard, collecting SNMP metrics
-// from all configured targets at the dashboard's configured interval.
+// from all configured targets. Each target is polled at its own interval,
+// which defaults to the dashboard's but may be overridden per group or
+// target. Targets are polled concurrently by a bounded worker pool; each
+// target commits its results independently so a slow or unreachable device
+// never delays updates for the others.
 type Poller struct {
 	mu           sync.RWMutex
 	dash         *dashboard.Dashboard
+	settings     map[string]dashboard.PollSettings
+	tick         time.Duration
 	provider     identity.Provider
 	clients      map[string]*gosnmp.GoSNMP
+	retired      map[string]*gosnmp.GoSNMP // dropped while in use, closed after the poll
 	data         map[string]*TargetStats
 	prevCounters map[string]map[int]CounterSample
-	subscribers  []chan EngineEvent
+	states       map[string]*targetState
+	inFlight     map[string]bool
+	workers      chan struct{}
+	hub          *eventHub // subscribers to typed events; nil if none
+	events       *RingBuffer[Event]
+	aggregates   []InterfaceStats // computed rows, in dashboard order
+	store        *history.Store   // on-disk rate history; nil if not kept
+	configErr    error            // why the dashboard file last failed to reload
+	rec          *recorder        // recording being written; nil if none
+	replay       *replayer        // recording played back instead of polling; nil if live
 	stopCh       chan struct{}
+	stopOnce     sync.Once
+	done         chan struct{}  // closed when Run returns
+	polls        sync.WaitGroup // poll cycles in flight
+	phase        EngineState    // starting, running, stopping or stopped
+	retick       chan struct{}  // signalled when tick changes
 	pollCount    int
 	errorCount   int
 	lastPoll     time.Time
 	cachedSnap   atomic.Pointer[DashboardSnapshot]
+	cachedInfo   atomic.Pointer[EngineInfo]
 }
 
 // NewPoller creates a Poller for the given dashboard and identity provider.
 func NewPoller(dash *dashboard.Dashboard, provider identity.Provider) (*Poller, error) {
 	p := &Poller{
 		dash:         dash,
+		settings:     make(map[string]dashboard.PollSettings),
 		provider:     provider,
 		clients:      make(map[string]*gosnmp.GoSNMP),
+		retired:      make(map[string]*gosnmp.GoSNMP),
 		data:         make(map[string]*TargetStats),
 		prevCounters: make(map[string]map[int]CounterSample),
+		states:       make(map[string]*targetState),
+		inFlight:     make(map[string]bool),
+		workers:      make(chan struct{}, maxConcurrentTargets),
+		events:       NewRingBuffer[Event](maxEvents),
 		stopCh:       make(chan struct{}),
+		done:         make(chan struct{}),
+		phase:        EngineStarting,
+		retick:       make(chan struct{}, 1),
 	}
+	for _, group := range dash.Groups {
+		for _, target := range group.Targets {
+			p.settings[target.Host] = dash.PollSettings(group, target)
+		}
+	}
+	p.tick = schedulerTick(p.settings, dash.Interval)
+	p.aggregates = p.newAggregateStats()
 	return p, nil
 }
 
+// schedulerTick returns the polling loop's wake-up period: the greatest
+// common divisor of all target intervals, so every target can be polled on
+// time, but no finer than minSchedulerTick.
+func schedulerTick(settings map[string]dashboard.PollSettings, fallback time.Duration) time.Duration {
+	var tick time.Duration
+	for _, s := range settings {
+		a, b := tick, s.Interval
+		for b != 0 {
+			a, b = b, a%b
+		}
+		tick = a
+	}
+	if tick == 0 {
+		tick = fallback
+	}
+	return max(tick, minSchedulerTick)
+}
+
 // initTargetStats pre-populates empty stats for all configured targets and
 // interfaces without performing any SNMP calls. This allows the UI to render
-// immediately while the first poll runs asynchronously.
+// immediately while the first poll runs asynchronously. Interface history
+// and recent events are restored from the on-disk history, if kept.
 func (p *Poller) initTargetStats() {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+	now := time.Now()
+	data := make(map[string]*TargetStats)
 	for _, group := range p.dash.Groups {
 		for _, target := range group.Targets {
-			ts := &TargetStats{
-				Host:  target.Host,
-				Label: target.Label,
-			}
-			for _, ifName := range target.Interfaces {
-				ts.Interfaces = append(ts.Interfaces, InterfaceStats{
-					Name:    ifName,
-					Status:  "",
-					History: NewRingBuffer[RateSample](p.dash.MaxHistory),
-				})
+			ts := p.newTargetStats(target)
+			interval := p.pollSettings(target.Host).Interval
+			for i := range ts.Interfaces {
+				p.restoreRates(target.Host, &ts.Interfaces[i], interval, now)
 			}
-			p.data[target.Host] = ts
+			data[target.Host] = ts
 		}
 	}
+	events := p.restoreEvents(now)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	maps.Copy(p.data, data)
+	for _, e := range events {
+		p.events.Add(e)
+	}
 	p.notify()
 }
 
-// Run starts the polling loop. It blocks until Stop is called.
-// It pre-populates empty stats so the UI can render immediately,
-// then kicks off the first poll asynchronously.
+// newTargetStats returns empty stats for a target's configured interfaces
+// and custom OIDs.
+func (p *Poller) newTargetStats(target dashboard.Target) *TargetStats {
+	ts := &TargetStats{
+		Host:   target.Host,
+		Label:  target.Label,
+		Device: DeviceStats{History: NewRingBuffer[DeviceSample](p.dash.MaxHistory)},
+	}
+	for _, ifName := range target.Interfaces {
+		ts.Interfaces = append(ts.Interfaces, InterfaceStats{
+			Name:    ifName,
+			History: NewRingBuffer[RateSample](p.dash.MaxHistory),
+			Rollups: NewRollups(),
+		})
+	}
+	for _, oid := range target.OIDs {
+		ts.Metrics = append(ts.Metrics, MetricStats{
+			Label:   oid.Label,
+			OID:     oid.OID,
+			Kind:    oid.Type,
+			Unit:    oid.Unit,
+			History: NewRingBuffer[MetricSample](p.dash.MaxHistory),
+		})
+	}
+	return ts
+}
+
+// Run starts the polling loop. It blocks until Stop is called and the
+// engine has shut down. It pre-populates empty stats so the UI can render
+// immediately, then kicks off the first poll asynchronously.
 func (p *Poller) Run() {
+	defer close(p.done)
 	p.initTargetStats()
+	if p.replay != nil {
+		p.runReplay()
+		return
+	}
 
-	ticker := time.NewTicker(p.dash.Interval)
+	ticker := time.NewTicker(p.tick)
 	defer ticker.Stop()
 
-	go p.poll()
+	p.startPoll()
 
 	for {
 		select {
 		case <-ticker.C:
-			p.poll()
+			p.startPoll()
+		case <-p.retick:
+			p.mu.RLock()
+			ticker.Reset(p.tick)
+			p.mu.RUnlock()
 		case <-p.stopCh:
-			p.cleanup()
+			p.shutdown()
 			return
 		}
 	}
 }
 
-// poll executes a single poll cycle across all targets.
+// startPoll runs a poll cycle in the background, tracked so that shutdown
+// can wait for it.
+func (p *Poller) startPoll() {
+	p.polls.Add(1)
+	go func() {
+		defer p.polls.Done()
+		p.poll()
+	}()
+}
+
+// poll executes a single poll cycle across the targets that are due. Targets
+// are handed to a bounded pool of workers; a target whose previous poll is
+// still in flight (e.g. waiting out an SNMP timeout) is skipped for this
+// cycle rather than queued behind itself. Unreachable targets are due only
+// once their backoff has elapsed.
 func (p *Poller) poll() {
+	var targets []dashboard.Target
+	now := time.Now()
 	p.mu.Lock()
-	defer p.mu.Unlock()
-
 	for _, group := range p.dash.Groups {
 		for _, target := range group.Targets {
-			p.pollTarget(group.Name, target)
+			if p.inFlight[target.Host] {
+				continue
+			}
+			st := p.targetStateLocked(target.Host)
+			if !due(st.nextPoll, now, p.tick) {
+				continue
+			}
+			st.nextPoll = nextPollTime(st.nextPoll, now, p.settings[target.Host].Interval)
+			p.inFlight[target.Host] = true
+			targets = append(targets, target)
 		}
 	}
+	if len(p.settings) == 0 && p.phase == EngineStarting {
+		// Nothing to poll: the engine is as started as it will get.
+		p.phase = EngineRunning
+		p.notify()
+	}
+	p.mu.Unlock()
+	if len(targets) == 0 {
+		return
+	}
 
+	var wg sync.WaitGroup
+	for _, target := range targets {
+		wg.Add(1)
+		p.workers <- struct{}{}
+		go func(target dashboard.Target) {
+			defer func() {
+				<-p.workers
+				p.mu.Lock()
+				delete(p.inFlight, target.Host)
+				p.closeRetiredLocked(target.Host)
+				p.mu.Unlock()
+				wg.Done()
+			}()
+			p.pollTarget(target)
+		}(target)
+	}
+	wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.completeCycleLocked()
+}
+
+// completeCycleLocked counts a completed poll cycle, computes the aggregate
+// rows and reports the cycle to subscribers, and to the recording if any.
+// Must be called while holding the write lock on p.mu.
+func (p *Poller) completeCycleLocked() {
 	p.pollCount++
-	p.lastPoll = time.Now()
+	p.lastPoll = p.now()
+	if p.phase == EngineStarting {
+		p.phase = EngineRunning
+	}
+	p.commitAggregatesLocked(p.lastPoll)
+	if p.rec != nil {
+		p.rec.write(recordEntry{Kind: entryCycle, Time: p.lastPoll})
+		p.rec.flush()
+	}
 	p.notify()
+	p.publishLocked(EngineEvent{Type: PollCompletedEvent, Time: p.lastPoll, Snapshot: p.cachedSnap.Load()})
+}
+
+// due reports whether a target scheduled for next should be polled on the
+// tick at now. Half a tick of slack absorbs timer jitter, so a target is not
+// pushed back a whole tick for waking up a few milliseconds early.
+func due(next, now time.Time, tick time.Duration) bool {
+	return !now.Add(tick / 2).Before(next)
+}
+
+// nextPollTime schedules the poll after one due at prev. Polls stay on a
+// fixed cadence unless the schedule has fallen more than an interval behind,
+// in which case it restarts from now instead of firing a burst of catch-up
+// polls.
+func nextPollTime(prev, now time.Time, interval time.Duration) time.Time {
+	if prev.IsZero() || now.Sub(prev) > interval {
+		return now.Add(interval)
+	}
+	return prev.Add(interval)
+}
+
+// targetState holds per-target polling state that is learned from the
+// device and kept across cycles but never exposed in snapshots. It is only
+// touched by the worker currently polling the target, except for the fields
+// marked as guarded by p.mu, which a reload may change at any time.
+type targetState struct {
+	oidsPerPDU     int                // largest GET the agent has accepted
+	tableSize      int                // number of rows in the device's interface table
+	legacyCounters map[int]bool       // ifIndexes that only have 32-bit counters
+	uptime         uint32             // last sysUpTime, in hundredths of a second
+	uptimeAt       time.Time          // when uptime was read; zero until first read
+	resolvedAt     time.Time          // last successful interface resolution
+	resolveNeeded  bool               // re-resolve on the next cycle; guarded by p.mu
+	descr          map[int]string     // ifDescr of each monitored ifIndex at resolution
+	failures       int                // consecutive failed polls
+	failingSince   time.Time          // when the current run of failures began
+	backoff        int                // intervals between probes while unreachable
+	nextPoll       time.Time          // when the target is next due
+	maxRepetitions int                // configured GETBULK max-repetitions; 0 = auto
+	metricPrev     []metricReading    // previous reading of each custom OID
+	device         *devicePlan        // device health OIDs; nil until discovered
+	sensors        *sensorPlan        // entity sensors; nil until discovered
+	sensorsAt      time.Time          // when sensors were last read
+	flaps          map[int]*flapState // status transitions by ifIndex
+	forget         []int              // ifIndexes a reload dropped; guarded by p.mu
+}
+
+// observeUptime records a sysUpTime reading and reports whether the agent
+// has restarted since the previous one.
+func (st *targetState) observeUptime(uptime uint32, at time.Time) bool {
+	restarted := !st.uptimeAt.IsZero() && AgentRestarted(st.uptime, uptime, at.Sub(st.uptimeAt))
+	st.uptime, st.uptimeAt = uptime, at
+	return restarted
+}
+
+// targetState returns the polling state for host, creating it on first use.
+func (p *Poller) targetState(host string) *targetState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.targetStateLocked(host)
+}
+
+// beginPoll returns the polling state of the target at host to the worker
+// about to poll it, once it has forgotten the ifIndexes a reload dropped.
+func (p *Poller) beginPoll(host string) *targetState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st := p.targetStateLocked(host)
+	for _, ifIndex := range st.forget {
+		delete(st.legacyCounters, ifIndex)
+	}
+	st.forget = nil
+	return st
+}
+
+// targetStateLocked is targetState for callers already holding the write
+// lock on p.mu.
+func (p *Poller) targetStateLocked(host string) *targetState {
+	st, ok := p.states[host]
+	if !ok {
+		st = &targetState{
+			oidsPerPDU:     gosnmp.MaxOids,
+			legacyCounters: make(map[int]bool),
+			flaps:          make(map[int]*flapState),
+		}
+		p.states[host] = st
+	}
+	return st
+}
+
+// interfaceSample is the raw result of polling one interface, collected
+// without holding p.mu and committed afterwards.
+type interfaceSample struct {
+	counters      CounterSample
+	status        string
+	descr         string
+	lastChange    uint32 // ifLastChange, in sysUpTime hundredths of a second
+	hasLastChange bool
+	err           error
+	missingHC     bool // agent lacks the 64-bit counters for this interface
 }
 
 // pollTarget collects SNMP counters for a single target and updates stats.
-func (p *Poller) pollTarget(groupName string, target dashboard.Target) {
+// All SNMP I/O happens without holding p.mu; the results are committed under
+// the write lock in one step once the target has been fully polled.
+//
+// sysUpTime is read first on every cycle. It tells an agent restart apart
+// from a counter wrap, and a target that cannot answer it is failed without
+// waiting out a timeout per interface batch. While a target is unreachable
+// the read doubles as a fast probe; once it answers, the full poll runs in
+// the same cycle.
+func (p *Poller) pollTarget(target dashboard.Target) {
 	client, err := p.getOrCreateClient(target)
 	if err != nil {
-		p.setTargetError(target, err)
+		p.targetFailed(target, err)
 		return
 	}
 
+	state := p.beginPoll(target.Host)
+	uptime, ok, err := readUptime(client, state)
+	if err != nil {
+		p.targetFailed(target, err)
+		return
+	}
+	restarted := false
+	if ok {
+		restarted = state.observeUptime(uptime, time.Now())
+	}
+	if restarted {
+		// Many platforms renumber ifIndex on reboot.
+		p.mu.Lock()
+		state.resolveNeeded = true
+		p.mu.Unlock()
+	}
+
+	if p.needsResolve(target.Host) {
+		p.resolve(client, target.Host)
+	}
+
+	indexes := p.interfaceIndexes(target.Host)
+	samples := p.getInterfaceSamples(client, target.Host, indexes)
+	if state.renumbered(indexes, samples) {
+		p.resolve(client, target.Host)
+		indexes = p.interfaceIndexes(target.Host)
+		samples = p.getInterfaceSamples(client, target.Host, indexes)
+	}
+	poll := targetPoll{indexes: indexes, samples: samples, restarted: restarted}
+	if len(target.OIDs) > 0 {
+		poll.readings = getMetricReadings(client, state, target.OIDs)
+	}
+	poll.device = pollDevice(client, state, restarted)
+	poll.sensors = pollSensors(client, state, indexes, restarted)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.commitTarget(target, poll)
+	p.notify()
+}
+
+// targetFailed records an error that prevented a target from being polled.
+// A target removed from the dashboard while it was being polled is ignored,
+// and so is a poll cut short by the engine stopping.
+func (p *Poller) targetFailed(target dashboard.Target, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.settings[target.Host]; !ok || p.phase == EngineStopping {
+		return
+	}
+	now := p.now()
+	p.setTargetError(target, err)
+	p.recordFailureLocked(p.data[target.Host], p.targetStateLocked(target.Host), now)
+	p.recordLocked(recordEntry{Kind: entryFailed, Time: now, Host: target.Host, Error: err.Error()})
+	p.notify()
+}
+
+// getSysUpTime reads the agent's sysUpTime. ok is false if the agent
+// answered but has no usable value.
+func getSysUpTime(client *gosnmp.GoSNMP) (uptime uint32, ok bool, err error) {
+	result, err := client.Get([]string{OIDsysUpTime})
+	if err != nil {
+		return 0, false, err
+	}
+	if result.Error != gosnmp.NoError || len(result.Variables) == 0 {
+		return 0, false, nil
+	}
+	v := result.Variables[0]
+	if v.Type != gosnmp.TimeTicks {
+		return 0, false, nil
+	}
+	return uint32(gosnmp.ToBigInt(v.Value).Uint64()), true, nil
+}
+
+// targetPoll is everything read from a target in one cycle, collected
+// without holding p.mu and committed in one step.
+type targetPoll struct {
+	indexes   []int // ifIndex of each sample, in the order of polledInterfaces
+	samples   []interfaceSample
+	readings  []metricReading // custom OIDs
+	device    DeviceStats
+	sensors   *sensorPoll // nil when not read this cycle
+	restarted bool
+}
+
+// commitTarget applies a target's polled interface samples, custom OID
+// readings, device health and sensors to its stats. If the dashboard was
+// reloaded while the target was being polled, results that no longer match
+// its interfaces or custom OIDs are discarded, and so is the whole poll of
+// a target that was removed.
+// Must be called while holding the write lock on p.mu.
+func (p *Poller) commitTarget(target dashboard.Target, poll targetPoll) {
+	current, ok := p.targetConfigLocked(target.Host)
+	if !ok {
+		return
+	}
 	ts := p.getOrCreateTargetStats(target)
-	now := time.Now()
+	now := p.now()
+	p.recordPollLocked(target.Host, poll, now)
 
-	for i, iface := range ts.Interfaces {
-		counters, err := p.getInterfaceCounters(client, iface.IfIndex)
-		if err != nil {
-			ts.Interfaces[i].PollError = err
-			p.errorCount++
-			continue
+	// Rates are computed against the baselines from before this commit, so
+	// a port that is both configured and a LAG member gets the same rate
+	// in both rows.
+	prev := maps.Clone(p.prevCounters[target.Host])
+	if p.prevCounters[target.Host] == nil {
+		p.prevCounters[target.Host] = make(map[int]CounterSample)
+	}
+	persisted := make(map[int]bool)
+	polled := polledInterfaces(ts)
+	if !slices.Equal(poll.indexes, indexesOf(polled)) {
+		polled = nil
+	}
+	for i, iface := range polled {
+		if i >= len(poll.samples) {
+			break
 		}
-
-		status, _ := p.getInterfaceStatus(client, iface.IfIndex)
-		ts.Interfaces[i].Status = status
-
-		prevKey := target.Host
-		if p.prevCounters[prevKey] != nil {
-			if prev, ok := p.prevCounters[prevKey][iface.IfIndex]; ok {
-				rate, err := CalculateRate(prev, counters)
-				if err == nil {
-					ts.Interfaces[i].InRate = rate.InRate
-					ts.Interfaces[i].OutRate = rate.OutRate
-					ts.Interfaces[i].Utilization = CalculateUtilization(rate.InRate, rate.OutRate, iface.Speed)
-					ts.Interfaces[i].History.Add(rate)
-				}
-			}
+		if poll.sensors != nil {
+			iface.Optics = poll.sensors.optics[iface.IfIndex]
 		}
-
-		if p.prevCounters[prevKey] == nil {
-			p.prevCounters[prevKey] = make(map[int]CounterSample)
+		p.commitInterfaceLocked(ts, iface, poll.samples[i], prev, poll.restarted, now)
+		if !persisted[iface.IfIndex] {
+			persisted[iface.IfIndex] = p.persistRateLocked(ts.Host, iface, prev[iface.IfIndex], poll.samples[i])
 		}
-		p.prevCounters[prevKey][iface.IfIndex] = counters
+	}
 
-		ts.Interfaces[i].LastPoll = now
-		ts.Interfaces[i].PollError = nil
+	st := p.targetStateLocked(target.Host)
+	if slices.Equal(target.OIDs, current.OIDs) {
+		p.commitMetricsLocked(ts, st, target.OIDs, poll.readings, poll.restarted, now)
+	}
+	p.commitDeviceLocked(ts, poll.device, now)
+	if poll.sensors != nil {
+		p.commitSensorsLocked(ts, poll.sensors.chassis, now)
 	}
 
 	ts.LastPoll = now
 	ts.PollError = nil
+	p.recordSuccessLocked(ts, st)
+}
+
+// commitInterfaceLocked applies one interface's polled sample to its stats,
+// computing rates against the baseline in prev and storing the sample as the
+// next baseline. Counters that restarted, hit a discontinuity or were
+// cleared are re-baselined instead of turned into a rate. Must be called
+// while holding the write lock on p.mu.
+func (p *Poller) commitInterfaceLocked(ts *TargetStats, iface *InterfaceStats, sample interfaceSample, prev map[int]CounterSample, restarted bool, now time.Time) {
+	if iface.NotFound && errors.Is(sample.err, ErrInterfaceUnresolved) {
+		// Not a poll failure: the device has no such interface.
+		iface.PollError = nil
+		iface.LastPoll = now
+		return
+	}
+	if sample.err != nil {
+		iface.PollError = sample.err
+		p.errorCount++
+		return
+	}
+
+	p.commitFlapsLocked(ts, iface, sample, restarted, now)
+	iface.Status = sample.status
+	iface.CounterBits = sample.counters.Bits
+
+	if last, ok := prev[iface.IfIndex]; ok {
+		reason := CounterResetReason(last, sample.counters, restarted)
+		var rate RateSample
+		var err error
+		if reason == "" {
+			rate, err = CalculateRate(last, sample.counters)
+			switch {
+			case errors.Is(err, ErrCounterWrap):
+				reason = ResetCleared
+			case err == nil && ImplausibleWrap(last, sample.counters, rate, iface.Speed):
+				reason = ResetCleared
+			}
+		}
+		if reason != "" {
+			iface.CounterResets++
+			iface.LastCounterReset = now
+			iface.CounterResetReason = reason
+			e := targetEvent(CounterResetEvent, ts, now)
+			e.Interface, e.Detail = iface.Name, reason
+			p.publishLocked(e)
+		} else if err == nil {
+			iface.InRate = rate.InRate
+			iface.OutRate = rate.OutRate
+			iface.Utilization = CalculateUtilization(rate.InRate, rate.OutRate, iface.Speed)
+			iface.InPPS = rate.InPPS
+			iface.OutPPS = rate.OutPPS
+			iface.InAvgSize = rate.InAvgSize
+			iface.OutAvgSize = rate.OutAvgSize
+			iface.InErrors = rate.InErrors
+			iface.OutErrors = rate.OutErrors
+			iface.InDiscards = rate.InDiscards
+			iface.OutDiscards = rate.OutDiscards
+			iface.InErrorPct = rate.InErrorPct
+			iface.OutErrorPct = rate.OutErrorPct
+			iface.addRate(rate)
+		}
+	}
+	p.prevCounters[ts.Host][iface.IfIndex] = sample.counters
+
+	iface.LastPoll = now
+	iface.PollError = nil
+}
+
+// polledInterfaces returns every interface polled for a target: the
+// configured interfaces in order, followed by the members of each link
+// aggregate among them.
+func polledInterfaces(ts *TargetStats) []*InterfaceStats {
+	polled := make([]*InterfaceStats, 0, len(ts.Interfaces))
+	for i := range ts.Interfaces {
+		polled = append(polled, &ts.Interfaces[i])
+	}
+	for i := range ts.Interfaces {
+		for j := range ts.Interfaces[i].Members {
+			polled = append(polled, &ts.Interfaces[i].Members[j])
+		}
+	}
+	return polled
 }
 
 // getOrCreateClient returns an existing SNMP client or creates a new one.
+// Only the worker currently polling a target creates its client, so the
+// lock is held just long enough to read and publish the map entry.
 func (p *Poller) getOrCreateClient(target dashboard.Target) (*gosnmp.GoSNMP, error) {
-	if client, ok := p.clients[target.Host]; ok {
+	p.mu.RLock()
+	client, ok := p.clients[target.Host]
+	p.mu.RUnlock()
+	if ok {
 		return client, nil
 	}
 
@@ -164,76 +635,281 @@ func (p *Poller) getOrCreateClient(target dashboard.Target) (*gosnmp.GoSNMP, err
 		return nil, err
 	}
 
-	client, err := NewSNMPClient(target.Host, target.Port, id, 5*time.Second)
+	settings := p.pollSettings(target.Host)
+	client, err = NewSNMPClient(target.Host, target.Port, id, settings.Timeout)
 	if err != nil {
 		return nil, err
 	}
+	client.Retries = settings.Retries
+	if settings.MaxRepetitions > 0 {
+		client.MaxRepetitions = uint32(settings.MaxRepetitions)
+	}
+	p.targetState(target.Host).maxRepetitions = settings.MaxRepetitions
 
 	if err := client.Connect(); err != nil {
 		return nil, err
 	}
 
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.phase == EngineStopping {
+		client.Conn.Close()
+		return nil, errStopping
+	}
 	p.clients[target.Host] = client
 	return client, nil
 }
 
-// getOrCreateTargetStats returns existing stats or initializes new ones.
-// On first call for a target, it performs SNMP walks to resolve interface
-// names to ifIndex values and discover interface speeds.
-// If stats were pre-populated by initTargetStats (IfIndex == 0), the
-// interface resolution is performed on this call.
+// pollSettings returns the resolved poll settings for a target.
+func (p *Poller) pollSettings(host string) dashboard.PollSettings {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.settings[host]
+}
+
+// getOrCreateTargetStats returns existing stats or initializes empty ones.
+// Stats are normally pre-populated by initTargetStats; interface resolution
+// happens separately in pollTarget. Must be called while holding the write
+// lock on p.mu.
 func (p *Poller) getOrCreateTargetStats(target dashboard.Target) *TargetStats {
 	if ts, ok := p.data[target.Host]; ok {
-		// If interfaces haven't been resolved yet (pre-populated), resolve now
-		if len(ts.Interfaces) > 0 && ts.Interfaces[0].IfIndex == 0 {
-			resolved := p.resolveInterfaces(target)
-			for i, iface := range ts.Interfaces {
-				if info, found := resolved[iface.Name]; found {
-					ts.Interfaces[i].IfIndex = info.IfIndex
-					ts.Interfaces[i].Speed = info.Speed
-					ts.Interfaces[i].Description = info.Description
-				}
-			}
-		}
 		return ts
 	}
 
 	// Fallback: create new (should not happen after initTargetStats)
-	ts := &TargetStats{
-		Host:  target.Host,
-		Label: target.Label,
+	ts := p.newTargetStats(target)
+	p.data[target.Host] = ts
+	return ts
+}
+
+// needsResolve reports whether the target's interface names need to be
+// (re-)resolved to ifIndex values: on the first cycle, after a restart or
+// renumbering was detected, and periodically while any configured interface
+// could not be found on the device.
+func (p *Poller) needsResolve(host string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	st := p.states[host]
+	if st == nil || st.resolvedAt.IsZero() || st.resolveNeeded {
+		return true
+	}
+	ts, ok := p.data[host]
+	if !ok {
+		return true
+	}
+	for _, iface := range ts.Interfaces {
+		if iface.NotFound && time.Since(st.resolvedAt) >= resolveRetryInterval {
+			return true
+		}
 	}
+	return false
+}
 
-	resolved := p.resolveInterfaces(target)
+// resolve walks the target's interface table and remaps the configured
+// interface names onto it. A walk that returns nothing (e.g. a timeout) is
+// reported to subscribers as a failed attempt and retried on the next cycle.
+func (p *Poller) resolve(client *gosnmp.GoSNMP, host string) {
+	resolved := p.resolveInterfaces(client)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(resolved) == 0 {
+		p.resolveFailedLocked(host)
+		return
+	}
+	p.applyResolvedLocked(host, resolved)
+}
 
-	for _, ifName := range target.Interfaces {
-		stats := InterfaceStats{
-			Name:    ifName,
-			History: NewRingBuffer[RateSample](p.dash.MaxHistory),
+// resolveFailedLocked reports a failed walk of the target's interface
+// table to subscribers. Must be called while holding the write lock on
+// p.mu.
+func (p *Poller) resolveFailedLocked(host string) {
+	ts, ok := p.data[host]
+	if !ok {
+		return
+	}
+	now := p.now()
+	p.recordLocked(recordEntry{Kind: entryUnresolved, Time: now, Host: host})
+	e := targetEvent(ResolutionFailedEvent, ts, now)
+	e.Detail = "interface table walk returned nothing"
+	p.publishLocked(e)
+}
+
+// renumbered reports whether the polled samples show that an ifIndex no
+// longer belongs to the interface it was resolved to: either its ifDescr has
+// changed, or the index has vanished from the device. The latter is only
+// acted on once per resolveRetryInterval so that an interface with a
+// permanently missing column does not trigger a walk every cycle.
+func (st *targetState) renumbered(indexes []int, samples []interfaceSample) bool {
+	for i, ifIndex := range indexes {
+		if ifIndex == 0 || i >= len(samples) {
+			continue
 		}
-		if info, ok := resolved[ifName]; ok {
-			stats.IfIndex = info.IfIndex
-			stats.Speed = info.Speed
-			stats.Description = info.Description
+		s := samples[i]
+		want := st.descr[ifIndex]
+		if s.err == nil && s.descr != "" && want != "" && s.descr != want {
+			return true
+		}
+		if errors.Is(s.err, ErrNoSuchInstance) && time.Since(st.resolvedAt) >= resolveRetryInterval {
+			return true
 		}
-		ts.Interfaces = append(ts.Interfaces, stats)
 	}
-	p.data[target.Host] = ts
-	return ts
+	return false
+}
+
+// applyResolved copies resolved ifIndex, speed and description values onto
+// the target's interface stats. Interfaces that moved to a different ifIndex
+// lose their counter baselines, names missing from the device are flagged
+// as not found, and link aggregates get their member ports.
+func (p *Poller) applyResolved(host string, resolved map[string]DiscoveredInterface) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.applyResolvedLocked(host, resolved)
+}
+
+// applyResolvedLocked is applyResolved for callers already holding the
+// write lock on p.mu.
+func (p *Poller) applyResolvedLocked(host string, resolved map[string]DiscoveredInterface) {
+	ts, ok := p.data[host]
+	if !ok {
+		return
+	}
+	byIndex := make(map[int]DiscoveredInterface)
+	for _, info := range resolved {
+		byIndex[info.IfIndex] = info
+	}
+	st := p.targetStateLocked(host)
+	st.tableSize = len(byIndex)
+	st.resolvedAt = p.now()
+	st.resolveNeeded = false
+	p.recordLocked(recordEntry{Kind: entryResolved, Time: st.resolvedAt, Host: host, Resolved: resolved})
+	st.descr = make(map[int]string)
+
+	var oldMembers []int
+	for _, iface := range ts.Interfaces {
+		for _, m := range iface.Members {
+			oldMembers = append(oldMembers, m.IfIndex)
+		}
+	}
+	for i := range ts.Interfaces {
+		iface := &ts.Interfaces[i]
+		info, found := resolved[iface.Name]
+		if !found {
+			if !iface.NotFound {
+				e := targetEvent(ResolutionFailedEvent, ts, st.resolvedAt)
+				e.Interface, e.Detail = iface.Name, "not found on the device"
+				p.publishLocked(e)
+			}
+			p.forgetIndexLocked(host, iface.IfIndex)
+			iface.IfIndex = 0
+			iface.NotFound = true
+			iface.Members = nil
+			continue
+		}
+		if iface.IfIndex != info.IfIndex {
+			p.forgetIndexLocked(host, iface.IfIndex)
+			p.forgetIndexLocked(host, info.IfIndex)
+		}
+		iface.IfIndex = info.IfIndex
+		iface.Speed = info.Speed
+		iface.Description = info.Description
+		iface.NotFound = false
+		st.descr[info.IfIndex] = info.Description
+		iface.Members = p.resolveMembersLocked(host, iface.Members, info.Members, byIndex)
+	}
+
+	// Departed LAG members lose their baselines, unless still polled.
+	for _, idx := range oldMembers {
+		if _, polled := st.descr[idx]; !polled {
+			p.forgetIndexLocked(host, idx)
+		}
+	}
+}
+
+// resolveMembersLocked rebuilds a link aggregate's member list from the
+// resolved member ifIndexes. Members that kept their ifIndex keep their
+// stats and history. Must be called while holding the write lock on p.mu.
+func (p *Poller) resolveMembersLocked(host string, old []InterfaceStats, indexes []int, byIndex map[int]DiscoveredInterface) []InterfaceStats {
+	st := p.targetStateLocked(host)
+	prev := make(map[int]InterfaceStats, len(old))
+	for _, m := range old {
+		prev[m.IfIndex] = m
+	}
+	var members []InterfaceStats
+	for _, idx := range indexes {
+		info, ok := byIndex[idx]
+		if !ok {
+			continue
+		}
+		m, ok := prev[idx]
+		if !ok || m.Name != info.Name {
+			m = InterfaceStats{IfIndex: idx, Name: info.Name, History: NewRingBuffer[RateSample](p.dash.MaxHistory), Rollups: NewRollups()}
+			p.restoreRates(host, &m, p.settings[host].Interval, p.now())
+		}
+		delete(prev, idx)
+		m.Speed = info.Speed
+		m.Description = info.Description
+		st.descr[idx] = info.Description
+		members = append(members, m)
+	}
+	return members
+}
+
+// forgetIndexLocked drops everything learned about an ifIndex so that a
+// different interface can take it over cleanly. Must be called by the
+// worker polling the target while holding the write lock on p.mu; a reload
+// uses dropIndexLocked instead.
+func (p *Poller) forgetIndexLocked(host string, ifIndex int) {
+	if ifIndex == 0 {
+		return
+	}
+	delete(p.prevCounters[host], ifIndex)
+	st := p.targetStateLocked(host)
+	delete(st.legacyCounters, ifIndex)
+	delete(st.flaps, ifIndex)
+}
+
+// dropIndexLocked is forgetIndexLocked for a reload, which may run while
+// the target is being polled: what the worker uses unlocked is left for it
+// to forget when its next poll begins. Must be called while holding the
+// write lock on p.mu.
+func (p *Poller) dropIndexLocked(host string, ifIndex int) {
+	if ifIndex == 0 {
+		return
+	}
+	delete(p.prevCounters[host], ifIndex)
+	st := p.targetStateLocked(host)
+	delete(st.flaps, ifIndex)
+	st.forget = append(st.forget, ifIndex)
+}
+
+// interfaceIndexes returns the ifIndex of each of the target's polled
+// interfaces, in the order of polledInterfaces.
+func (p *Poller) interfaceIndexes(host string) []int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	ts, ok := p.data[host]
+	if !ok {
+		return nil
+	}
+	return indexesOf(polledInterfaces(ts))
+}
+
+// indexesOf returns the ifIndex of each interface.
+func indexesOf(ifaces []*InterfaceStats) []int {
+	indexes := make([]int, len(ifaces))
+	for i, iface := range ifaces {
+		indexes[i] = iface.IfIndex
+	}
+	return indexes
 }
 
 // resolveInterfaces walks ifName, ifDescr, and ifHighSpeed on the target
 // to build a mapping from interface name -> (ifIndex, speed, description).
 // This is needed because dashboard configs reference interfaces by name,
 // but SNMP counters are indexed by ifIndex.
-func (p *Poller) resolveInterfaces(target dashboard.Target) map[string]DiscoveredInterface {
+func (p *Poller) resolveInterfaces(client *gosnmp.GoSNMP) map[string]DiscoveredInterface {
 	result := make(map[string]DiscoveredInterface)
 
-	client, err := p.getOrCreateClient(target)
-	if err != nil {
-		return result
-	}
-
 	// Build ifIndex -> DiscoveredInterface from walks
 	byIndex := make(map[int]*DiscoveredInterface)
 
@@ -261,6 +937,15 @@ func (p *Poller) resolveInterfaces(target dashboard.Target) map[string]Discovere
 		}
 	})
 
+	// Link aggregate members; best effort, like the walks above
+	if lags, err := discoverLAGMembers(client); err == nil {
+		for agg, members := range lags {
+			if iface, ok := byIndex[agg]; ok {
+				iface.Members = members
+			}
+		}
+	}
+
 	// Map by name (exact match) and also by description (fallback)
 	for _, iface := range byIndex {
 		result[iface.Name] = *iface
@@ -272,59 +957,18 @@ func (p *Poller) resolveInterfaces(target dashboard.Target) map[string]Discovere
 	return result
 }
 
-// getInterfaceCounters fetches HC in/out octet counters for a single interface.
-func (p *Poller) getInterfaceCounters(client *gosnmp.GoSNMP, ifIndex int) (CounterSample, error) {
-	oids := []string{
-		fmt.Sprintf("%s.%d", OIDifHCInOctets, ifIndex),
-		fmt.Sprintf("%s.%d", OIDifHCOutOctets, ifIndex),
-	}
-
-	result, err := client.Get(oids)
-	if err != nil {
-		return CounterSample{}, err
-	}
-
-	cs := CounterSample{Timestamp: time.Now()}
-	for _, v := range result.Variables {
-		val := gosnmp.ToBigInt(v.Value).Uint64()
-		if v.Name == oids[0] || v.Name == "."+oids[0] {
-			cs.InOctets = val
-		} else {
-			cs.OutOctets = val
-		}
-	}
-	return cs, nil
-}
-
-// getInterfaceStatus fetches the operational status of an interface.
-func (p *Poller) getInterfaceStatus(client *gosnmp.GoSNMP, ifIndex int) (string, error) {
-	oid := fmt.Sprintf("%s.%d", OIDifOperStatus, ifIndex)
-
-	result, err := client.Get([]string{oid})
-	if err != nil {
-		return "unknown", err
-	}
-
-	if len(result.Variables) > 0 {
-		val := gosnmp.ToBigInt(result.Variables[0].Value).Int64()
-		switch val {
-		case 1:
-			return "up", nil
-		case 2:
-			return "down", nil
-		case 3:
-			return "testing", nil
-		default:
-			return "unknown", nil
-		}
-	}
-	return "unknown", nil
-}
-
 // setTargetError records a poll error for a target.
+// Must be called while holding the write lock on p.mu.
 func (p *Poller) setTargetError(target dashboard.Target, err error) {
 	ts := p.getOrCreateTargetStats(target)
 	ts.PollError = err
+	for _, iface := range polledInterfaces(ts) {
+		iface.PollError = err
+	}
+	for i := range ts.Metrics {
+		ts.Metrics[i].PollError = err
+	}
+	ts.Device.PollError = err
 	p.errorCount++
 }
 
@@ -349,23 +993,17 @@ func (p *Poller) TrySnapshot() *DashboardSnapshot {
 }
 
 // TryInfo returns engine info without blocking. If the write lock is held,
-// it returns minimal info (name + running state) to avoid freezing the UI.
+// it returns the info as of the last update, to avoid freezing the UI.
 func (p *Poller) TryInfo() EngineInfo {
 	if p.mu.TryRLock() {
-		info := EngineInfo{
-			Name:       p.dash.Name,
-			State:      EngineRunning,
-			LastPoll:   p.lastPoll,
-			PollCount:  p.pollCount,
-			ErrorCount: p.errorCount,
-		}
+		info := p.infoLocked()
 		p.mu.RUnlock()
 		return info
 	}
-	return EngineInfo{
-		Name:  p.dash.Name,
-		State: EngineRunning,
+	if info := p.cachedInfo.Load(); info != nil {
+		return *info
 	}
+	return EngineInfo{State: EngineStarting}
 }
 
 // snapshotLocked builds a DashboardSnapshot without acquiring any lock.
@@ -373,15 +1011,34 @@ func (p *Poller) TryInfo() EngineInfo {
 func (p *Poller) snapshotLocked() *DashboardSnapshot {
 	snap := &DashboardSnapshot{
 		Name:      p.dash.Name,
+		Columns:   p.dash.Columns,
 		LastPoll:  p.lastPoll,
 		PollCount: p.pollCount,
+		Events:    p.events.All(),
+		// Copied because the poller updates the rows in place.
+		Aggregates: slices.Clone(p.aggregates),
 	}
+	if p.configErr != nil {
+		snap.ConfigError = p.configErr.Error()
+	}
+	snap.Recording = p.recordingLocked()
+	snap.Replay = p.replayLocked()
 
 	for _, group := range p.dash.Groups {
 		gs := GroupSnapshot{Name: group.Name}
 		for _, target := range group.Targets {
 			if ts, ok := p.data[target.Host]; ok {
-				gs.Targets = append(gs.Targets, *ts)
+				// Copy the slices too: the poller keeps updating the
+				// elements in place after the snapshot is handed out.
+				t := *ts
+				t.Interfaces = slices.Clone(ts.Interfaces)
+				for i := range t.Interfaces {
+					t.Interfaces[i].Members = slices.Clone(t.Interfaces[i].Members)
+				}
+				t.Metrics = slices.Clone(ts.Metrics)
+				t.Device.Storage = slices.Clone(ts.Device.Storage)
+				t.Sensors = slices.Clone(ts.Sensors)
+				gs.Targets = append(gs.Targets, t)
 			}
 		}
 		snap.Groups = append(snap.Groups, gs)
@@ -389,55 +1046,17 @@ func (p *Poller) snapshotLocked() *DashboardSnapshot {
 	return snap
 }
 
-// Subscribe returns a channel that receives an event after each poll cycle.
-func (p *Poller) Subscribe() <-chan EngineEvent {
-	ch := make(chan EngineEvent, 1)
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	p.subscribers = append(p.subscribers, ch)
-	return ch
-}
-
-// notify sends the current snapshot to all subscribers (non-blocking)
-// and caches the snapshot for lock-free reads.
+// notify caches the current snapshot and engine info for lock-free reads.
 // Must be called while holding the write lock on p.mu.
 func (p *Poller) notify() {
-	snap := p.snapshotLocked()
-	p.cachedSnap.Store(snap)
-	event := EngineEvent{DashboardName: p.dash.Name, Snapshot: snap}
-	for _, ch := range p.subscribers {
-		select {
-		case ch <- event:
-		default:
-		}
-	}
+	p.cachedSnap.Store(p.snapshotLocked())
+	info := p.infoLocked()
+	p.cachedInfo.Store(&info)
 }
 
 // Info returns summary information about this engine.
 func (p *Poller) Info() EngineInfo {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
-	return EngineInfo{
-		Name:       p.dash.Name,
-		State:      EngineRunning,
-		LastPoll:   p.lastPoll,
-		PollCount:  p.pollCount,
-		ErrorCount: p.errorCount,
-	}
-}
-
-// Stop signals the polling loop to exit.
-func (p *Poller) Stop() {
-	close(p.stopCh)
-}
-
-// cleanup closes all SNMP connections.
-func (p *Poller) cleanup() {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	for _, client := range p.clients {
-		if client.Conn != nil {
-			client.Conn.Close()
-		}
-	}
+	return p.infoLocked()
 }
@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tonhe/flo/internal/dashboard"
+	"github.com/tonhe/flo/internal/identity"
+)
+
+func newTestPoller(t *testing.T, interfaces ...string) *Poller {
+	t.Helper()
+	dash := &dashboard.Dashboard{
+		Name:       "test",
+		Interval:   time.Second,
+		MaxHistory: 10,
+		Groups: []dashboard.Group{{
+			Name: "Default",
+			Targets: []dashboard.Target{{
+				Host:       "10.0.0.1",
+				Label:      "sw1",
+				Interfaces: interfaces,
+			}},
+		}},
+	}
+	p, err := NewPoller(dash, nil)
+	if err != nil {
+		t.Fatalf("NewPoller() error: %v", err)
+	}
+	p.initTargetStats()
+	return p
+}
+
+func TestApplyResolvedRemap(t *testing.T) {
+	p := newTestPoller(t, "Gi0/1", "Gi0/2", "Gi0/9")
+	if !p.needsResolve("10.0.0.1") {
+		t.Fatal("unresolved target should need resolving")
+	}
+
+	p.applyResolved("10.0.0.1", map[string]DiscoveredInterface{
+		"Gi0/1": {IfIndex: 1, Name: "Gi0/1", Description: "GigabitEthernet0/1"},
+		"Gi0/2": {IfIndex: 2, Name: "Gi0/2", Description: "GigabitEthernet0/2"},
+	})
+	ifaces := p.data["10.0.0.1"].Interfaces
+	if ifaces[0].IfIndex != 1 || ifaces[1].IfIndex != 2 {
+		t.Fatalf("unexpected ifIndexes: %d, %d", ifaces[0].IfIndex, ifaces[1].IfIndex)
+	}
+	if !ifaces[2].NotFound {
+		t.Error("interface missing from the device should be flagged not found")
+	}
+	if p.needsResolve("10.0.0.1") {
+		t.Error("freshly resolved target should not need resolving")
+	}
+
+	// Gi0/1 moves to a new ifIndex; its old baseline must not carry over.
+	p.prevCounters["10.0.0.1"] = map[int]CounterSample{1: {InOctets: 1}, 2: {InOctets: 2}}
+	p.applyResolved("10.0.0.1", map[string]DiscoveredInterface{
+		"Gi0/1": {IfIndex: 5, Name: "Gi0/1", Description: "GigabitEthernet0/1"},
+		"Gi0/2": {IfIndex: 2, Name: "Gi0/2", Description: "GigabitEthernet0/2"},
+	})
+	if ifaces[0].IfIndex != 5 {
+		t.Errorf("expected Gi0/1 remapped to ifIndex 5, got %d", ifaces[0].IfIndex)
+	}
+	if _, ok := p.prevCounters["10.0.0.1"][1]; ok {
+		t.Error("baseline for the vacated ifIndex should be dropped")
+	}
+	if _, ok := p.prevCounters["10.0.0.1"][2]; !ok {
+		t.Error("baseline for an unchanged interface should be kept")
+	}
+}
+
+func TestTargetStateRenumbered(t *testing.T) {
+	st := &targetState{
+		resolvedAt: time.Now(),
+		descr:      map[int]string{1: "GigabitEthernet0/1", 2: "GigabitEthernet0/2"},
+	}
+	indexes := []int{1, 2}
+
+	same := []interfaceSample{{descr: "GigabitEthernet0/1"}, {descr: "GigabitEthernet0/2"}}
+	if st.renumbered(indexes, same) {
+		t.Error("matching ifDescr should not be treated as renumbering")
+	}
+
+	moved := []interfaceSample{{descr: "GigabitEthernet0/1"}, {descr: "GigabitEthernet1/1"}}
+	if !st.renumbered(indexes, moved) {
+		t.Error("changed ifDescr should be treated as renumbering")
+	}
+
+	gone := []interfaceSample{{descr: "GigabitEthernet0/1"}, {err: ErrNoSuchInstance}}
+	if st.renumbered(indexes, gone) {
+		t.Error("vanished index should wait for the retry interval")
+	}
+	st.resolvedAt = time.Now().Add(-resolveRetryInterval)
+	if !st.renumbered(indexes, gone) {
+		t.Error("vanished index should trigger a re-resolve after the retry interval")
+	}
+}
+
+func TestPollBoundedAndSkipsInFlight(t *testing.T) {
+	// A socket that never answers keeps every poll waiting out its timeout.
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var requests atomic.Int32
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			if _, _, err := conn.ReadFrom(buf); err != nil {
+				return
+			}
+			requests.Add(1)
+		}
+	}()
+
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	const targets = maxConcurrentTargets + 4
+	group := dashboard.Group{Name: "Default"}
+	for i := range targets {
+		group.Targets = append(group.Targets, dashboard.Target{Host: fmt.Sprintf("slow%d", i), Interfaces: []string{"Gi0/1"}})
+	}
+	p, err := NewPoller(&dashboard.Dashboard{Name: "slow", Interval: time.Second, MaxHistory: 10, Groups: []dashboard.Group{group}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.initTargetStats()
+	for _, target := range group.Targets {
+		client, err := NewSNMPClient("127.0.0.1", port, &identity.Identity{Version: "2c", Community: "public"}, 500*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.Retries = 0
+		if err := client.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		p.clients[target.Host] = client
+	}
+	t.Cleanup(p.cleanup)
+
+	done := make(chan struct{})
+	go func() {
+		p.poll()
+		close(done)
+	}()
+	time.Sleep(200 * time.Millisecond)
+	if n := requests.Load(); n != maxConcurrentTargets {
+		t.Errorf("expected %d targets polled at once, got %d", maxConcurrentTargets, n)
+	}
+
+	// Every target is still in flight: a cycle due now polls none of them.
+	p.mu.Lock()
+	for _, st := range p.states {
+		st.nextPoll = time.Time{}
+	}
+	p.mu.Unlock()
+	p.poll()
+	if n := requests.Load(); n != maxConcurrentTargets {
+		t.Errorf("targets still in flight should be skipped, got %d requests", n)
+	}
+
+	<-done
+	if n := requests.Load(); n != targets {
+		t.Errorf("expected each target polled once, got %d requests", n)
+	}
+	if p.pollCount != 1 {
+		t.Errorf("expected only the first cycle to complete, got %d", p.pollCount)
+	}
+}
@@ -2,48 +2,253 @@ package engine
 
 import (
 	"errors"
+	"math"
 	"time"
 )
 
-// ErrCounterWrap indicates that an SNMP counter has wrapped around.
+// ErrCounterWrap indicates that an SNMP counter has decreased in a way that
+// cannot be explained by a 32-bit wrap.
 var ErrCounterWrap = errors.New("counter wrap detected")
 
-// CounterSample holds raw SNMP counter values at a point in time.
+// ErrCounterWidth indicates that two samples were taken at different counter
+// widths and cannot be compared.
+var ErrCounterWidth = errors.New("counter width changed")
+
+// Reasons recorded when an interface's counters are re-baselined instead of
+// being converted to a rate.
+const (
+	ResetAgentRestart  = "agent restarted"
+	ResetDiscontinuity = "counter discontinuity"
+	ResetCleared       = "counters cleared"
+)
+
+// CounterSample holds raw SNMP counter values at a point in time. Bits is the
+// width of the octet and packet counters (32 or 64); error and discard
+// counters are always 32-bit. Discontinuity is the interface's
+// ifCounterDiscontinuityTime, or 0 when the agent does not report it.
 type CounterSample struct {
-	InOctets  uint64
-	OutOctets uint64
-	Timestamp time.Time
+	InOctets      uint64
+	OutOctets     uint64
+	InUcastPkts   uint64
+	OutUcastPkts  uint64
+	InMcastPkts   uint64
+	OutMcastPkts  uint64
+	InBcastPkts   uint64
+	OutBcastPkts  uint64
+	InErrors      uint64
+	OutErrors     uint64
+	InDiscards    uint64
+	OutDiscards   uint64
+	Bits          int
+	Discontinuity uint32
+	Timestamp     time.Time
 }
 
-// RateSample holds calculated bit rates at a point in time.
+// RateSample holds calculated bit rates at a point in time, along with
+// packet rates, per-second error and discard rates and the share of packets
+// in error.
 type RateSample struct {
-	Timestamp time.Time
-	InRate    float64
-	OutRate   float64
+	Timestamp   time.Time
+	InRate      float64
+	OutRate     float64
+	InPPS       PacketRates
+	OutPPS      PacketRates
+	InAvgSize   float64 // bytes per packet
+	OutAvgSize  float64 // bytes per packet
+	InErrors    float64 // errors/s
+	OutErrors   float64 // errors/s
+	InDiscards  float64 // discards/s
+	OutDiscards float64 // discards/s
+	InErrorPct  float64
+	OutErrorPct float64
 }
 
-// CalculateRate computes the bit rate between two counter samples.
-// Returns ErrCounterWrap if either counter has decreased.
+// CalculateRate computes the bit rate between two counter samples. A 32-bit
+// counter that decreases is assumed to have wrapped once and its delta is
+// computed modulo 2^32. Returns ErrCounterWrap if a 64-bit counter has
+// decreased, or ErrCounterWidth if the samples were taken at different
+// counter widths.
 func CalculateRate(prev, curr CounterSample) (RateSample, error) {
 	elapsed := curr.Timestamp.Sub(prev.Timestamp).Seconds()
 	if elapsed <= 0 {
 		return RateSample{}, errors.New("zero or negative elapsed time")
 	}
+	if prev.Bits != curr.Bits {
+		return RateSample{}, ErrCounterWidth
+	}
+
+	var err error
+	delta := func(prev, curr uint64, bits int) uint64 {
+		d, ok := counterDelta(prev, curr, bits)
+		if !ok {
+			err = ErrCounterWrap
+		}
+		return d
+	}
 
-	if curr.InOctets < prev.InOctets || curr.OutOctets < prev.OutOctets {
-		return RateSample{}, ErrCounterWrap
+	deltaIn := delta(prev.InOctets, curr.InOctets, curr.Bits)
+	deltaOut := delta(prev.OutOctets, curr.OutOctets, curr.Bits)
+	deltaInUcast := delta(prev.InUcastPkts, curr.InUcastPkts, curr.Bits)
+	deltaOutUcast := delta(prev.OutUcastPkts, curr.OutUcastPkts, curr.Bits)
+	deltaInMcast := delta(prev.InMcastPkts, curr.InMcastPkts, curr.Bits)
+	deltaOutMcast := delta(prev.OutMcastPkts, curr.OutMcastPkts, curr.Bits)
+	deltaInBcast := delta(prev.InBcastPkts, curr.InBcastPkts, curr.Bits)
+	deltaOutBcast := delta(prev.OutBcastPkts, curr.OutBcastPkts, curr.Bits)
+	deltaInErr := delta(prev.InErrors, curr.InErrors, 32)
+	deltaOutErr := delta(prev.OutErrors, curr.OutErrors, 32)
+	deltaInDisc := delta(prev.InDiscards, curr.InDiscards, 32)
+	deltaOutDisc := delta(prev.OutDiscards, curr.OutDiscards, 32)
+	if err != nil {
+		return RateSample{}, err
 	}
 
-	deltaIn := curr.InOctets - prev.InOctets
-	deltaOut := curr.OutOctets - prev.OutOctets
+	deltaInPkts := deltaInUcast + deltaInMcast + deltaInBcast
+	deltaOutPkts := deltaOutUcast + deltaOutMcast + deltaOutBcast
 
 	return RateSample{
 		Timestamp: curr.Timestamp,
 		InRate:    float64(deltaIn) * 8 / elapsed,
 		OutRate:   float64(deltaOut) * 8 / elapsed,
+		InPPS: PacketRates{
+			Unicast:   float64(deltaInUcast) / elapsed,
+			Multicast: float64(deltaInMcast) / elapsed,
+			Broadcast: float64(deltaInBcast) / elapsed,
+		},
+		OutPPS: PacketRates{
+			Unicast:   float64(deltaOutUcast) / elapsed,
+			Multicast: float64(deltaOutMcast) / elapsed,
+			Broadcast: float64(deltaOutBcast) / elapsed,
+		},
+		InAvgSize:   averagePacketSize(deltaIn, deltaInPkts),
+		OutAvgSize:  averagePacketSize(deltaOut, deltaOutPkts),
+		InErrors:    float64(deltaInErr) / elapsed,
+		OutErrors:   float64(deltaOutErr) / elapsed,
+		InDiscards:  float64(deltaInDisc) / elapsed,
+		OutDiscards: float64(deltaOutDisc) / elapsed,
+		InErrorPct:  ErrorPercent(deltaInErr, deltaInPkts),
+		OutErrorPct: ErrorPercent(deltaOutErr, deltaOutPkts),
 	}, nil
 }
 
+// deriveRatios sets a rate's average packet sizes and error percentages
+// from its bit, packet and error rates, for rates that were not computed
+// from counters.
+func (r *RateSample) deriveRatios() {
+	r.InAvgSize, r.OutAvgSize, r.InErrorPct, r.OutErrorPct = 0, 0, 0, 0
+	if pkts := r.InPPS.Total(); pkts > 0 {
+		r.InAvgSize = r.InRate / 8 / pkts
+		r.InErrorPct = r.InErrors / (r.InErrors + pkts) * 100
+	}
+	if pkts := r.OutPPS.Total(); pkts > 0 {
+		r.OutAvgSize = r.OutRate / 8 / pkts
+		r.OutErrorPct = r.OutErrors / (r.OutErrors + pkts) * 100
+	}
+}
+
+// counterDelta returns the increase of a counter between two readings. For
+// 32-bit counters a decrease is treated as a single wrap past 2^32; a 64-bit
+// counter cannot realistically wrap between polls, so a decrease there is
+// reported as not ok.
+func counterDelta(prev, curr uint64, bits int) (uint64, bool) {
+	if curr >= prev {
+		return curr - prev, true
+	}
+	if bits == 32 && prev <= math.MaxUint32 {
+		return math.MaxUint32 - prev + curr + 1, true
+	}
+	return 0, false
+}
+
+// CounterResetReason reports why the delta between two samples of the same
+// interface cannot be trusted, or "" if it can. restarted is true when the
+// agent's sysUpTime shows it has restarted since prev was taken. Samples of
+// different counter widths are not comparable and never count as a reset.
+func CounterResetReason(prev, curr CounterSample, restarted bool) string {
+	switch {
+	case restarted:
+		return ResetAgentRestart
+	case prev.Bits != curr.Bits:
+		return ""
+	case prev.Discontinuity != curr.Discontinuity:
+		return ResetDiscontinuity
+	case curr.Bits == 64 && counterDecreased(prev, curr):
+		return ResetCleared
+	}
+	return ""
+}
+
+// counterDecreased reports whether either octet counter went backwards.
+func counterDecreased(prev, curr CounterSample) bool {
+	return curr.InOctets < prev.InOctets || curr.OutOctets < prev.OutOctets
+}
+
+// ImplausibleWrap reports whether rate was computed across a 32-bit wrap that
+// would put the interface above its link speed. A decrease that large is far
+// more likely a counter clear than a genuine wrap. Without a link speed, a
+// wrap covering more than half the counter's range is taken for a clear.
+func ImplausibleWrap(prev, curr CounterSample, rate RateSample, speedMbps uint64) bool {
+	if curr.Bits != 32 || !counterDecreased(prev, curr) {
+		return false
+	}
+	if speedMbps == 0 {
+		return wrappedOverHalf(prev.InOctets, curr.InOctets) || wrappedOverHalf(prev.OutOctets, curr.OutOctets)
+	}
+	return CalculateUtilization(rate.InRate, rate.OutRate, speedMbps) > 100
+}
+
+// wrappedOverHalf reports whether a 32-bit counter going from prev down to
+// curr wrapped by more than half its range.
+func wrappedOverHalf(prev, curr uint64) bool {
+	if curr >= prev {
+		return false
+	}
+	delta, _ := counterDelta(prev, curr, 32)
+	return delta > math.MaxUint32/2
+}
+
+// AgentRestarted reports whether sysUpTime going from prev to curr over
+// elapsed wall-clock time means the agent restarted. sysUpTime is a 32-bit
+// count of hundredths of a second that wraps roughly every 497 days; a
+// decrease is only a wrap when prev plus the elapsed time would have passed
+// 2^32.
+func AgentRestarted(prev, curr uint32, elapsed time.Duration) bool {
+	if curr >= prev {
+		return false
+	}
+	expected := uint64(prev) + uint64(elapsed/(10*time.Millisecond))
+	return expected <= math.MaxUint32
+}
+
+// PacketRates holds packets per second for one direction, by cast type.
+type PacketRates struct {
+	Unicast   float64
+	Multicast float64
+	Broadcast float64
+}
+
+// Total returns the combined packet rate across all cast types.
+func (p PacketRates) Total() float64 {
+	return p.Unicast + p.Multicast + p.Broadcast
+}
+
+// averagePacketSize returns the mean packet size in bytes for an interval.
+func averagePacketSize(octets, pkts uint64) float64 {
+	if pkts == 0 {
+		return 0
+	}
+	return float64(octets) / float64(pkts)
+}
+
+// ErrorPercent returns errored packets as a percentage of all packets seen
+// in an interval, where pkts counts only the packets delivered successfully.
+func ErrorPercent(errs, pkts uint64) float64 {
+	total := errs + pkts
+	if total == 0 {
+		return 0
+	}
+	return float64(errs) / float64(total) * 100
+}
+
 // CalculateUtilization returns the utilization percentage given rates and
 // interface speed in Mbps. It uses whichever direction (in/out) is higher.
 func CalculateUtilization(inRate, outRate float64, speedMbps uint64) float64 {
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"math"
 	"testing"
 	"time"
 )
@@ -45,9 +46,171 @@ func TestCalculateRateCounterWrap(t *testing.T) {
 	}
 }
 
+func TestCalculateRate32BitWrap(t *testing.T) {
+	now := time.Now()
+	prev := CounterSample{
+		InOctets:  math.MaxUint32 - 999,
+		OutOctets: 100,
+		Bits:      32,
+		Timestamp: now.Add(-10 * time.Second),
+	}
+	curr := CounterSample{
+		InOctets:  9_000,
+		OutOctets: 100,
+		Bits:      32,
+		Timestamp: now,
+	}
+	rate, err := CalculateRate(prev, curr)
+	if err != nil {
+		t.Fatalf("CalculateRate() error: %v", err)
+	}
+	// 1000 octets to reach the wrap plus 9000 after it = 10000 octets in 10s
+	if rate.InRate != 8_000 {
+		t.Errorf("expected InRate 8000 across the wrap, got %f", rate.InRate)
+	}
+}
+
+func TestCalculateRate64BitDecrease(t *testing.T) {
+	now := time.Now()
+	prev := CounterSample{InOctets: 5_000, Bits: 64, Timestamp: now.Add(-10 * time.Second)}
+	curr := CounterSample{InOctets: 1_000, Bits: 64, Timestamp: now}
+	if _, err := CalculateRate(prev, curr); err != ErrCounterWrap {
+		t.Errorf("expected ErrCounterWrap for a decreasing 64-bit counter, got %v", err)
+	}
+}
+
+func TestCalculateRateErrors(t *testing.T) {
+	now := time.Now()
+	prev := CounterSample{
+		InUcastPkts: 10_000,
+		InErrors:    100,
+		OutDiscards: 0,
+		Timestamp:   now.Add(-10 * time.Second),
+	}
+	curr := CounterSample{
+		InUcastPkts: 19_900,
+		InErrors:    200,
+		OutDiscards: 50,
+		Timestamp:   now,
+	}
+	rate, err := CalculateRate(prev, curr)
+	if err != nil {
+		t.Fatalf("CalculateRate() error: %v", err)
+	}
+	if rate.InErrors != 10 {
+		t.Errorf("expected 10 in errors/s, got %f", rate.InErrors)
+	}
+	if rate.OutDiscards != 5 {
+		t.Errorf("expected 5 out discards/s, got %f", rate.OutDiscards)
+	}
+	if rate.InErrorPct < 0.99 || rate.InErrorPct > 1.01 {
+		t.Errorf("expected ~1%% in errors, got %f", rate.InErrorPct)
+	}
+}
+
+func TestCalculateRatePackets(t *testing.T) {
+	now := time.Now()
+	prev := CounterSample{Timestamp: now.Add(-10 * time.Second)}
+	curr := CounterSample{
+		InOctets:    500_000,
+		InUcastPkts: 800,
+		InMcastPkts: 100,
+		InBcastPkts: 100,
+		Timestamp:   now,
+	}
+	rate, err := CalculateRate(prev, curr)
+	if err != nil {
+		t.Fatalf("CalculateRate() error: %v", err)
+	}
+	if rate.InPPS.Total() != 100 {
+		t.Errorf("expected 100 pps in, got %f", rate.InPPS.Total())
+	}
+	if rate.InPPS.Broadcast != 10 {
+		t.Errorf("expected 10 broadcast pps, got %f", rate.InPPS.Broadcast)
+	}
+	if rate.InAvgSize != 500 {
+		t.Errorf("expected 500 byte average packet, got %f", rate.InAvgSize)
+	}
+	if rate.OutAvgSize != 0 {
+		t.Errorf("expected 0 average size with no packets, got %f", rate.OutAvgSize)
+	}
+}
+
 func TestCalculateUtilization(t *testing.T) {
 	util := CalculateUtilization(500_000_000, 300_000_000, 1000)
 	if util < 49 || util > 51 {
 		t.Errorf("expected ~50%%, got %f", util)
 	}
 }
+
+func TestCounterResetReason(t *testing.T) {
+	base := CounterSample{InOctets: 5_000, OutOctets: 5_000, Bits: 64, Discontinuity: 100}
+	tests := []struct {
+		name      string
+		curr      CounterSample
+		restarted bool
+		want      string
+	}{
+		{"increase", CounterSample{InOctets: 6_000, OutOctets: 6_000, Bits: 64, Discontinuity: 100}, false, ""},
+		{"agent restart", CounterSample{InOctets: 6_000, OutOctets: 6_000, Bits: 64, Discontinuity: 100}, true, ResetAgentRestart},
+		{"discontinuity", CounterSample{InOctets: 6_000, OutOctets: 6_000, Bits: 64, Discontinuity: 900}, false, ResetDiscontinuity},
+		{"64-bit decrease", CounterSample{InOctets: 10, OutOctets: 6_000, Bits: 64, Discontinuity: 100}, false, ResetCleared},
+		{"width change", CounterSample{InOctets: 10, Bits: 32}, false, ""},
+	}
+	for _, tt := range tests {
+		if got := CounterResetReason(base, tt.curr, tt.restarted); got != tt.want {
+			t.Errorf("%s: CounterResetReason() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestImplausibleWrap(t *testing.T) {
+	now := time.Now()
+	prev := CounterSample{InOctets: math.MaxUint32 - 1_000, Bits: 32, Timestamp: now.Add(-10 * time.Second)}
+
+	// A genuine wrap: a few kilobytes past 2^32 is well within link speed.
+	wrapped := CounterSample{InOctets: 1_000, Bits: 32, Timestamp: now}
+	rate, err := CalculateRate(prev, wrapped)
+	if err != nil {
+		t.Fatalf("CalculateRate() error: %v", err)
+	}
+	if ImplausibleWrap(prev, wrapped, rate, 1000) {
+		t.Error("small wrap should be plausible")
+	}
+
+	// A clear from near 2^32 back to zero would be ~3.4 Gbps on a 100M link.
+	cleared := CounterSample{InOctets: 0, Bits: 32, Timestamp: now}
+	prev.InOctets = 1_000
+	rate, err = CalculateRate(prev, cleared)
+	if err != nil {
+		t.Fatalf("CalculateRate() error: %v", err)
+	}
+	if !ImplausibleWrap(prev, cleared, rate, 100) {
+		t.Error("wrap implying a rate above link speed should be implausible")
+	}
+	if !ImplausibleWrap(prev, cleared, rate, 0) {
+		t.Error("wrap across most of the counter should be a clear when the speed is unknown")
+	}
+
+	// Without a link speed, a small wrap is still a wrap.
+	prev.InOctets = math.MaxUint32 - 1_000
+	rate, err = CalculateRate(prev, wrapped)
+	if err != nil {
+		t.Fatalf("CalculateRate() error: %v", err)
+	}
+	if ImplausibleWrap(prev, wrapped, rate, 0) {
+		t.Error("small wrap should be plausible when the speed is unknown")
+	}
+}
+
+func TestAgentRestarted(t *testing.T) {
+	if AgentRestarted(1_000, 2_000, 10*time.Second) {
+		t.Error("increasing uptime is not a restart")
+	}
+	if !AgentRestarted(500_000, 300, 10*time.Second) {
+		t.Error("uptime falling well short of 2^32 should be a restart")
+	}
+	if AgentRestarted(math.MaxUint32-200, 300, 10*time.Second) {
+		t.Error("uptime passing 2^32 within the interval is a wrap")
+	}
+}
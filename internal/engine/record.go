@@ -0,0 +1,355 @@
+package engine
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/tonhe/flo/internal/dashboard"
+)
+
+// recordVersion is the format of the recordings written by this version.
+const recordVersion = 1
+
+// RecordingExt is the extension of recording files.
+const RecordingExt = ".jsonl"
+
+// RecordingName returns a file name for a recording of the named
+// dashboard begun at t, e.g. "core-20260301-140322.jsonl".
+func RecordingName(dashboard string, t time.Time) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, dashboard)
+	return name + "-" + t.Format("20060102-150405") + RecordingExt
+}
+
+// Kinds of recording entries.
+const (
+	entryStart      = "start"      // first entry: format version and dashboard
+	entryDashboard  = "dashboard"  // the dashboard was reloaded
+	entryResolved   = "resolved"   // a target's interfaces were resolved
+	entryUnresolved = "unresolved" // a target's interface table walk failed
+	entryBaseline   = "baseline"   // a target's counters when recording began
+	entryPoll       = "poll"       // a target was polled
+	entryFailed     = "failed"     // a target could not be polled
+	entryCycle      = "cycle"      // a poll cycle completed
+)
+
+// recordEntry is one line of a recording: something that changed the
+// engine's state, and when.
+type recordEntry struct {
+	Kind      string                         `json:"kind"`
+	Time      time.Time                      `json:"time"`
+	Version   int                            `json:"version,omitempty"`
+	Dashboard *dashboard.Dashboard           `json:"dashboard,omitempty"`
+	Host      string                         `json:"host,omitempty"`
+	Resolved  map[string]DiscoveredInterface `json:"resolved,omitempty"`
+	Counters  map[int]CounterSample          `json:"counters,omitempty"`
+	Poll      *recordedPoll                  `json:"poll,omitempty"`
+	Error     string                         `json:"error,omitempty"`
+}
+
+// recordedPoll is a targetPoll as written to a recording, with its errors
+// as text, along with the sysUpTime that dates ifLastChange.
+type recordedPoll struct {
+	Indexes     []int             `json:"indexes"`
+	Samples     []recordedSample  `json:"samples"`
+	Readings    []recordedReading `json:"readings,omitempty"`
+	Device      DeviceStats       `json:"device"`
+	DeviceError string            `json:"device_error,omitempty"`
+	Sensors     *recordedSensors  `json:"sensors,omitempty"`
+	Restarted   bool              `json:"restarted,omitempty"`
+	Uptime      uint32            `json:"uptime,omitempty"`
+	UptimeAt    time.Time         `json:"uptime_at,omitzero"`
+}
+
+// recordedSample is a recorded interfaceSample.
+type recordedSample struct {
+	Counters      CounterSample `json:"counters"`
+	Status        string        `json:"status,omitempty"`
+	Descr         string        `json:"descr,omitempty"`
+	LastChange    uint32        `json:"last_change,omitempty"`
+	HasLastChange bool          `json:"has_last_change,omitempty"`
+	Error         string        `json:"error,omitempty"`
+	MissingHC     bool          `json:"missing_hc,omitempty"`
+}
+
+// recordedReading is a recorded metricReading.
+type recordedReading struct {
+	Value float64   `json:"value"`
+	Raw   uint64    `json:"raw"`
+	Bits  int       `json:"bits,omitempty"`
+	At    time.Time `json:"at"`
+	Error string    `json:"error,omitempty"`
+}
+
+// recordedSensors is a recorded sensorPoll.
+type recordedSensors struct {
+	Optics  map[int][]SensorReading `json:"optics,omitempty"`
+	Chassis []SensorReading         `json:"chassis,omitempty"`
+}
+
+// encodePoll converts a target's poll for a recording.
+func encodePoll(poll targetPoll, st *targetState) *recordedPoll {
+	r := &recordedPoll{
+		Indexes:     poll.indexes,
+		Samples:     make([]recordedSample, len(poll.samples)),
+		Device:      poll.device,
+		DeviceError: errorText(poll.device.PollError),
+		Restarted:   poll.restarted,
+		Uptime:      st.uptime,
+		UptimeAt:    st.uptimeAt,
+	}
+	r.Device.History, r.Device.PollError = nil, nil
+	for i, s := range poll.samples {
+		r.Samples[i] = recordedSample{
+			Counters:      s.counters,
+			Status:        s.status,
+			Descr:         s.descr,
+			LastChange:    s.lastChange,
+			HasLastChange: s.hasLastChange,
+			Error:         errorText(s.err),
+			MissingHC:     s.missingHC,
+		}
+	}
+	for _, m := range poll.readings {
+		r.Readings = append(r.Readings, recordedReading{Value: m.value, Raw: m.raw, Bits: m.bits, At: m.at, Error: errorText(m.err)})
+	}
+	if poll.sensors != nil {
+		r.Sensors = &recordedSensors{Optics: poll.sensors.optics, Chassis: poll.sensors.chassis}
+	}
+	return r
+}
+
+// decode converts a recorded poll back into the target's poll.
+func (r *recordedPoll) decode() targetPoll {
+	poll := targetPoll{
+		indexes:   r.Indexes,
+		samples:   make([]interfaceSample, len(r.Samples)),
+		device:    r.Device,
+		restarted: r.Restarted,
+	}
+	poll.device.PollError = decodeError(r.DeviceError)
+	for i, s := range r.Samples {
+		poll.samples[i] = interfaceSample{
+			counters:      s.Counters,
+			status:        s.Status,
+			descr:         s.Descr,
+			lastChange:    s.LastChange,
+			hasLastChange: s.HasLastChange,
+			err:           decodeError(s.Error),
+			missingHC:     s.MissingHC,
+		}
+	}
+	for _, m := range r.Readings {
+		poll.readings = append(poll.readings, metricReading{value: m.Value, raw: m.Raw, bits: m.Bits, at: m.At, err: decodeError(m.Error)})
+	}
+	if r.Sensors != nil {
+		poll.sensors = &sensorPoll{optics: r.Sensors.Optics, chassis: r.Sensors.Chassis}
+		if poll.sensors.optics == nil {
+			poll.sensors.optics = make(map[int][]SensorReading)
+		}
+	}
+	return poll
+}
+
+// errorText returns the message of err, or "" if it is nil.
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// decodeError turns a recorded error message back into an error, restoring
+// the errors the poller tells apart.
+func decodeError(text string) error {
+	switch text {
+	case "":
+		return nil
+	case ErrInterfaceUnresolved.Error():
+		return ErrInterfaceUnresolved
+	case ErrNoSuchInstance.Error():
+		return ErrNoSuchInstance
+	}
+	return errors.New(text)
+}
+
+// resolvedOf rebuilds the interface resolution a target's stats were last
+// given, as recorded at the start of a recording.
+func resolvedOf(ts *TargetStats) map[string]DiscoveredInterface {
+	resolved := make(map[string]DiscoveredInterface)
+	for _, iface := range ts.Interfaces {
+		if iface.NotFound || iface.IfIndex == 0 {
+			continue
+		}
+		info := DiscoveredInterface{IfIndex: iface.IfIndex, Name: iface.Name, Description: iface.Description, Speed: iface.Speed}
+		for _, m := range iface.Members {
+			info.Members = append(info.Members, m.IfIndex)
+			resolved[m.Name] = DiscoveredInterface{IfIndex: m.IfIndex, Name: m.Name, Description: m.Description, Speed: m.Speed}
+		}
+		resolved[iface.Name] = info
+	}
+	return resolved
+}
+
+// recorder writes a poller's results to a recording as they are committed.
+// Entries are buffered and flushed at the end of each poll cycle.
+type recorder struct {
+	file    string
+	since   time.Time
+	out     io.WriteCloser
+	w       *bufio.Writer
+	enc     *json.Encoder
+	entries int
+	err     error // first write error; nothing more is written after it
+}
+
+// newRecorder returns a recorder writing to out, named file.
+func newRecorder(out io.WriteCloser, file string, since time.Time) *recorder {
+	w := bufio.NewWriter(out)
+	return &recorder{file: file, since: since, out: out, w: w, enc: json.NewEncoder(w)}
+}
+
+// write appends an entry to the recording.
+func (r *recorder) write(e recordEntry) {
+	if r.err != nil {
+		return
+	}
+	if r.err = r.enc.Encode(e); r.err == nil {
+		r.entries++
+	}
+}
+
+// flush writes the buffered entries out.
+func (r *recorder) flush() {
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+}
+
+// close flushes the recording and closes it, returning the first error
+// writing it met.
+func (r *recorder) close() error {
+	r.flush()
+	err := r.out.Close()
+	if r.err != nil {
+		return r.err
+	}
+	return err
+}
+
+// RecordingInfo describes the recording a running engine is writing.
+type RecordingInfo struct {
+	File    string
+	Since   time.Time
+	Entries int
+	Error   string // why the recording stopped being written; empty if fine
+}
+
+// StartRecording writes everything the engine learns from its targets from
+// now on to out, until StopRecording is called or the engine stops: every
+// poll's counters, status, readings and timestamps, interface resolutions,
+// failures and dashboard reloads, one JSON object per line. file names the
+// recording in snapshots. The recording begins with the dashboard and the
+// interfaces and counter baselines each target already has, so that a
+// replay picks up exactly where it started. out is closed when the
+// recording ends, or if it cannot be started.
+func (p *Poller) StartRecording(out io.WriteCloser, file string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var err error
+	switch {
+	case p.replay != nil:
+		err = errors.New("a replay cannot be recorded")
+	case p.rec != nil:
+		err = fmt.Errorf("already recording to %s", p.rec.file)
+	case p.phase == EngineStopping || p.phase == EngineStopped:
+		err = errStopping
+	}
+	if err != nil {
+		out.Close()
+		return err
+	}
+
+	now := p.now()
+	rec := newRecorder(out, file, now)
+	rec.write(recordEntry{Kind: entryStart, Time: now, Version: recordVersion, Dashboard: p.dash})
+	for _, group := range p.dash.Groups {
+		for _, target := range group.Targets {
+			ts, st := p.data[target.Host], p.states[target.Host]
+			if ts == nil || st == nil || st.resolvedAt.IsZero() {
+				continue
+			}
+			rec.write(recordEntry{Kind: entryResolved, Time: now, Host: target.Host, Resolved: resolvedOf(ts)})
+			if len(p.prevCounters[target.Host]) > 0 {
+				rec.write(recordEntry{Kind: entryBaseline, Time: now, Host: target.Host, Counters: p.prevCounters[target.Host]})
+			}
+		}
+	}
+	rec.flush()
+	if rec.err != nil {
+		out.Close()
+		return rec.err
+	}
+	p.rec = rec
+	p.notify()
+	return nil
+}
+
+// StopRecording ends the engine's recording and closes it. It returns the
+// first error writing the recording met.
+func (p *Poller) StopRecording() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.rec == nil {
+		return errors.New("not recording")
+	}
+	err := p.stopRecordingLocked()
+	p.notify()
+	return err
+}
+
+// stopRecordingLocked ends the recording, if any. Must be called while
+// holding the write lock on p.mu.
+func (p *Poller) stopRecordingLocked() error {
+	if p.rec == nil {
+		return nil
+	}
+	err := p.rec.close()
+	p.rec = nil
+	return err
+}
+
+// recordLocked appends an entry to the recording, if any. Must be called
+// while holding the write lock on p.mu.
+func (p *Poller) recordLocked(e recordEntry) {
+	if p.rec != nil {
+		p.rec.write(e)
+	}
+}
+
+// recordPollLocked appends a target's poll to the recording, if any. Must
+// be called while holding the write lock on p.mu.
+func (p *Poller) recordPollLocked(host string, poll targetPoll, now time.Time) {
+	if p.rec != nil {
+		p.rec.write(recordEntry{Kind: entryPoll, Time: now, Host: host, Poll: encodePoll(poll, p.targetStateLocked(host))})
+	}
+}
+
+// recordingLocked describes the recording, if any. Must be called while
+// holding at least a read lock on p.mu.
+func (p *Poller) recordingLocked() *RecordingInfo {
+	if p.rec == nil {
+		return nil
+	}
+	return &RecordingInfo{File: p.rec.file, Since: p.rec.since, Entries: p.rec.entries, Error: errorText(p.rec.err)}
+}
@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tonhe/flo/internal/simulate"
+)
+
+// replayed plays the recording at path back as fast as it goes and returns
+// the final snapshot.
+func replayed(t *testing.T, path string) *DashboardSnapshot {
+	t.Helper()
+	p, err := NewReplay(path, 1e6)
+	if err != nil {
+		t.Fatalf("NewReplay() error: %v", err)
+	}
+	go p.Run()
+	t.Cleanup(func() { p.Stop(context.Background()) })
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		snap := p.Snapshot()
+		if snap.Replay != nil && snap.Replay.Done {
+			return snap
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("replay did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// near reports whether two rates agree to within a millionth.
+func near(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-6*math.Max(math.Abs(a), math.Abs(b))
+}
+
+func TestRecordReplay(t *testing.T) {
+	scenario := simulate.Scenario{
+		Behavior:      simulate.Behavior{Profile: simulate.ProfileFlat, Utilization: 0.5},
+		RebootDownStr: "1m",
+	}
+	p, device := newSimulatedTarget(t, scenario, 4, "Gi0/1", "Gi0/2", "Gi0/9")
+	target := p.dash.Groups[0].Targets[0]
+	cycle := func() {
+		p.pollTarget(target)
+		p.mu.Lock()
+		p.completeCycleLocked()
+		p.mu.Unlock()
+	}
+
+	// The recording starts mid-session, from the resolved interfaces and
+	// the counters of the poll before it.
+	cycle()
+	path := filepath.Join(t.TempDir(), RecordingName("sim", time.Now()))
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.StartRecording(f, filepath.Base(path)); err != nil {
+		t.Fatalf("StartRecording() error: %v", err)
+	}
+	again, err := os.Create(path + ".again")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.StartRecording(again, "again"); err == nil {
+		t.Error("expected an error starting a second recording")
+	}
+	for range 3 {
+		time.Sleep(200 * time.Millisecond)
+		cycle()
+	}
+	device.Reboot(time.Now())
+	cycle()
+	if rec := p.Snapshot().Recording; rec == nil || rec.File != filepath.Base(path) || rec.Entries == 0 || rec.Error != "" {
+		t.Fatalf("expected the recording in the snapshot, got %+v", rec)
+	}
+	if err := p.StopRecording(); err != nil {
+		t.Fatalf("StopRecording() error: %v", err)
+	}
+	live := p.Snapshot()
+	if live.Recording != nil {
+		t.Error("the recording should be gone from the snapshot once stopped")
+	}
+
+	snap := replayed(t, path)
+	if snap.Name != ReplayName("sim") || snap.Replay.Error != "" {
+		t.Errorf("unexpected replay %q: %+v", snap.Name, snap.Replay)
+	}
+	if !snap.LastPoll.Equal(live.LastPoll) || !snap.Now().Equal(live.LastPoll) {
+		t.Errorf("expected the replay to end at %v, got last poll %v, now %v", live.LastPoll, snap.LastPoll, snap.Now())
+	}
+	if snap.PollCount != 4 {
+		t.Errorf("expected the 4 recorded cycles, got %d", snap.PollCount)
+	}
+	want, got := live.Groups[0].Targets[0], snap.Groups[0].Targets[0]
+	if got.Health != want.Health || got.PollError == nil || got.PollError.Error() != want.PollError.Error() {
+		t.Errorf("expected the failed last poll replayed, got %v (%v), want %v (%v)", got.Health, got.PollError, want.Health, want.PollError)
+	}
+	for i, w := range want.Interfaces {
+		g := got.Interfaces[i]
+		if g.IfIndex != w.IfIndex || g.NotFound != w.NotFound || g.Status != w.Status || g.Speed != w.Speed {
+			t.Errorf("%s: expected %d/%v/%q/%d, got %d/%v/%q/%d", w.Name,
+				w.IfIndex, w.NotFound, w.Status, w.Speed, g.IfIndex, g.NotFound, g.Status, g.Speed)
+		}
+		// Live rates are timed on the monotonic clock, which a recording
+		// does not keep.
+		if !near(g.InRate, w.InRate) || !near(g.OutRate, w.OutRate) || !near(g.InPPS.Unicast, w.InPPS.Unicast) {
+			t.Errorf("%s: expected the recorded rates %.0f/%.0f, got %.0f/%.0f", w.Name, w.InRate, w.OutRate, g.InRate, g.OutRate)
+		}
+		if g.History.Len() != w.History.Len() {
+			t.Errorf("%s: expected %d rates in the history, got %d", w.Name, w.History.Len(), g.History.Len())
+		}
+	}
+	if w := want.Interfaces[0]; w.History.Len() != 3 {
+		t.Errorf("expected a rate for each recorded poll that answered, got %d", w.History.Len())
+	}
+}
+
+func TestReplayStop(t *testing.T) {
+	p, _ := newSimulatedTarget(t, simulate.Scenario{}, 1, "Gi0/1")
+	path := filepath.Join(t.TempDir(), "rec.jsonl")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.StartRecording(f, "rec.jsonl"); err != nil {
+		t.Fatal(err)
+	}
+	p.pollTarget(p.dash.Groups[0].Targets[0])
+	if err := p.StopRecording(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Far too slow to finish: the replay is stopped while waiting.
+	r, err := NewReplay(path, 1e-6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go r.Run()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := r.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error: %v", err)
+	}
+	if info := r.Info(); info.State != EngineStopped {
+		t.Errorf("expected the replay stopped, got %v", info.State)
+	}
+	if err := r.StartRecording(f, "rec.jsonl"); err == nil {
+		t.Error("a replay should not be recorded")
+	}
+}
+
+func TestNewReplayErrors(t *testing.T) {
+	dir := t.TempDir()
+	notRecording := filepath.Join(dir, "notes.jsonl")
+	if err := os.WriteFile(notRecording, []byte("{\"kind\":\"poll\"}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	newer := filepath.Join(dir, "newer.jsonl")
+	if err := os.WriteFile(newer, []byte("{\"kind\":\"start\",\"version\":99,\"dashboard\":{\"Name\":\"x\"}}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for name, path := range map[string]string{"missing": filepath.Join(dir, "none"), "not a recording": notRecording, "newer version": newer} {
+		if _, err := NewReplay(path, 1); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := NewReplay(newer, 0); err == nil {
+		t.Error("expected an error for a speed of 0")
+	}
+}
+
+func TestRecordingName(t *testing.T) {
+	at := time.Date(2026, 3, 1, 14, 3, 22, 0, time.UTC)
+	if got := RecordingName("Core / DC1", at); got != "Core---DC1-20260301-140322.jsonl" {
+		t.Errorf("unexpected recording name %q", got)
+	}
+}
@@ -0,0 +1,230 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/tonhe/flo/internal/dashboard"
+)
+
+// Reload applies a changed definition of the dashboard to the running
+// poller in place. Targets, interfaces, custom OIDs and aggregates that are
+// still configured keep their stats, history and counter baselines; new
+// ones start empty, or from the on-disk history, and new interfaces are
+// resolved on the target's next poll; removed ones are dropped, along with
+// the SNMP session of a removed target. A target whose identity, port,
+// timeout, retries or max-repetitions changed gets a new session on its
+// next poll, and one whose interval changed is polled on the next tick and
+// then at the new interval. History buffers are resized to a changed
+// max_history, keeping the most recent samples. A successful reload clears
+// any config error.
+func (p *Poller) Reload(dash *dashboard.Dashboard) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.reloadLocked(dash)
+}
+
+// reloadLocked is Reload for callers already holding the write lock on
+// p.mu.
+func (p *Poller) reloadLocked(dash *dashboard.Dashboard) error {
+	if dash.Name != p.dash.Name {
+		return fmt.Errorf("dashboard %q cannot replace %q", dash.Name, p.dash.Name)
+	}
+
+	now := p.now()
+	p.recordLocked(recordEntry{Kind: entryDashboard, Time: now, Dashboard: dash})
+	oldTargets := make(map[string]dashboard.Target)
+	for _, group := range p.dash.Groups {
+		for _, target := range group.Targets {
+			oldTargets[target.Host] = target
+		}
+	}
+	oldSettings, oldData, oldAggregates := p.settings, p.data, p.aggregates
+	p.dash = dash
+	p.data = make(map[string]*TargetStats)
+	p.settings = make(map[string]dashboard.PollSettings)
+
+	for _, group := range dash.Groups {
+		for _, target := range group.Targets {
+			settings := dash.PollSettings(group, target)
+			p.settings[target.Host] = settings
+			ts, ok := oldData[target.Host]
+			if !ok {
+				ts = p.newTargetStats(target)
+				for i := range ts.Interfaces {
+					p.restoreRates(target.Host, &ts.Interfaces[i], settings.Interval, now)
+				}
+				p.data[target.Host] = ts
+				continue
+			}
+			old := oldTargets[target.Host]
+			p.reloadTargetLocked(ts, old, target, settings.Interval, now)
+			p.data[target.Host] = ts
+
+			was := oldSettings[target.Host]
+			if old.Identity != target.Identity || old.Port != target.Port ||
+				was.Timeout != settings.Timeout || was.Retries != settings.Retries ||
+				was.MaxRepetitions != settings.MaxRepetitions {
+				p.dropClientLocked(target.Host)
+			}
+			if was.Interval != settings.Interval {
+				p.targetStateLocked(target.Host).nextPoll = time.Time{}
+			}
+		}
+	}
+	for host := range oldData {
+		if _, ok := p.data[host]; !ok {
+			p.dropTargetLocked(host)
+		}
+	}
+
+	p.aggregates = p.newAggregateStats()
+	for i := range p.aggregates {
+		for _, old := range oldAggregates {
+			if old.Name == p.aggregates[i].Name {
+				p.aggregates[i].History = old.History.Resized(dash.MaxHistory)
+				p.aggregates[i].Rollups = old.Rollups
+				break
+			}
+		}
+	}
+
+	if tick := schedulerTick(p.settings, dash.Interval); tick != p.tick {
+		p.tick = tick
+		select {
+		case p.retick <- struct{}{}:
+		default:
+		}
+	}
+	p.configErr = nil
+	p.notify()
+	return nil
+}
+
+// SetConfigError records why the dashboard's file could not be reloaded,
+// so that it is shown alongside the data still being polled under the last
+// good definition. A nil error clears it.
+func (p *Poller) SetConfigError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.configErr = err
+	p.notify()
+}
+
+// targetConfigLocked returns the current definition of the target at host.
+// Must be called while holding at least a read lock on p.mu.
+func (p *Poller) targetConfigLocked(host string) (dashboard.Target, bool) {
+	for _, group := range p.dash.Groups {
+		for _, target := range group.Targets {
+			if target.Host == host {
+				return target, true
+			}
+		}
+	}
+	return dashboard.Target{}, false
+}
+
+// reloadTargetLocked brings a target's stats in line with its new
+// definition. Interfaces are matched by name and custom OIDs by their whole
+// definition; the baselines of interfaces no longer polled are forgotten.
+// Must be called while holding the write lock on p.mu, after p.dash is
+// replaced.
+func (p *Poller) reloadTargetLocked(ts *TargetStats, old, target dashboard.Target, interval time.Duration, now time.Time) {
+	ts.Label = target.Label
+	st := p.targetStateLocked(target.Host)
+	size := p.dash.MaxHistory
+
+	before := polledInterfaces(ts)
+	byName := make(map[string]InterfaceStats, len(ts.Interfaces))
+	for _, iface := range ts.Interfaces {
+		byName[iface.Name] = iface
+	}
+	interfaces := make([]InterfaceStats, 0, len(target.Interfaces))
+	for _, name := range target.Interfaces {
+		iface, ok := byName[name]
+		if !ok {
+			iface = InterfaceStats{Name: name, History: NewRingBuffer[RateSample](size), Rollups: NewRollups()}
+			p.restoreRates(target.Host, &iface, interval, now)
+			st.resolveNeeded = true
+		}
+		interfaces = append(interfaces, iface)
+	}
+	ts.Interfaces = interfaces
+
+	polled := make(map[int]bool)
+	for _, iface := range polledInterfaces(ts) {
+		polled[iface.IfIndex] = true
+		iface.History = iface.History.Resized(size)
+	}
+	for _, iface := range before {
+		if !polled[iface.IfIndex] {
+			p.dropIndexLocked(target.Host, iface.IfIndex)
+		}
+	}
+	ts.Device.History = ts.Device.History.Resized(size)
+
+	if !slices.Equal(old.OIDs, target.OIDs) {
+		metrics := make([]MetricStats, len(target.OIDs))
+		prev := make([]metricReading, len(target.OIDs))
+		for i, oid := range target.OIDs {
+			if j := slices.Index(old.OIDs, oid); j >= 0 && j < len(ts.Metrics) {
+				metrics[i] = ts.Metrics[j]
+				if j < len(st.metricPrev) {
+					prev[i] = st.metricPrev[j]
+				}
+				continue
+			}
+			metrics[i] = MetricStats{
+				Label:   oid.Label,
+				OID:     oid.OID,
+				Kind:    oid.Type,
+				Unit:    oid.Unit,
+				History: NewRingBuffer[MetricSample](size),
+			}
+		}
+		ts.Metrics = metrics
+		st.metricPrev = prev
+	}
+	for i := range ts.Metrics {
+		ts.Metrics[i].History = ts.Metrics[i].History.Resized(size)
+	}
+}
+
+// dropClientLocked discards a target's SNMP session so that its next poll
+// opens a new one. A session still in use by a poll in flight is closed
+// once that poll completes. Must be called while holding the write lock on
+// p.mu.
+func (p *Poller) dropClientLocked(host string) {
+	client, ok := p.clients[host]
+	if !ok {
+		return
+	}
+	delete(p.clients, host)
+	if p.inFlight[host] {
+		p.retired[host] = client
+	} else if client.Conn != nil {
+		client.Conn.Close()
+	}
+}
+
+// closeRetiredLocked closes the session dropped while host was being
+// polled, if any. Must be called while holding the write lock on p.mu.
+func (p *Poller) closeRetiredLocked(host string) {
+	if client, ok := p.retired[host]; ok {
+		if client.Conn != nil {
+			client.Conn.Close()
+		}
+		delete(p.retired, host)
+	}
+}
+
+// dropTargetLocked forgets everything about a target that is no longer
+// configured and closes its SNMP session. A poll of the target still in
+// flight is discarded when it completes. Must be called while holding the
+// write lock on p.mu.
+func (p *Poller) dropTargetLocked(host string) {
+	p.dropClientLocked(host)
+	delete(p.prevCounters, host)
+	delete(p.states, host)
+}
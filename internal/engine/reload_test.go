@@ -0,0 +1,300 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/tonhe/flo/internal/dashboard"
+	"github.com/tonhe/flo/internal/simulate"
+)
+
+// reloadedDashboard returns a copy of the poller's dashboard with the given
+// targets in its only group.
+func reloadedDashboard(p *Poller, targets ...dashboard.Target) *dashboard.Dashboard {
+	dash := *p.dash
+	dash.Groups = []dashboard.Group{{Name: "Default", Targets: targets}}
+	return &dash
+}
+
+func TestReloadKeepsStats(t *testing.T) {
+	p := newTestPoller(t, "Gi0/1", "Gi0/2")
+	p.applyResolved("10.0.0.1", map[string]DiscoveredInterface{
+		"Gi0/1": {IfIndex: 1, Name: "Gi0/1"},
+		"Gi0/2": {IfIndex: 2, Name: "Gi0/2"},
+	})
+	p.data["10.0.0.1"].Interfaces[0].addRate(RateSample{Timestamp: time.Now(), InRate: 1000})
+	p.prevCounters["10.0.0.1"] = map[int]CounterSample{1: {InOctets: 1}, 2: {InOctets: 2}}
+
+	err := p.Reload(reloadedDashboard(p,
+		dashboard.Target{Host: "10.0.0.1", Label: "core", Interfaces: []string{"Gi0/1", "Gi0/3"}},
+		dashboard.Target{Host: "10.0.0.2", Label: "sw2", Interfaces: []string{"Gi0/1"}},
+	))
+	if err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+
+	ts := p.data["10.0.0.1"]
+	if ts.Label != "core" {
+		t.Errorf("expected label updated to core, got %q", ts.Label)
+	}
+	if len(ts.Interfaces) != 2 || ts.Interfaces[0].Name != "Gi0/1" || ts.Interfaces[1].Name != "Gi0/3" {
+		t.Fatalf("unexpected interfaces %+v", ts.Interfaces)
+	}
+	if ts.Interfaces[0].IfIndex != 1 || ts.Interfaces[0].History.Len() != 1 {
+		t.Error("a kept interface should keep its ifIndex and history")
+	}
+	if !p.needsResolve("10.0.0.1") {
+		t.Error("a target with a new interface should need resolving")
+	}
+	if _, ok := p.prevCounters["10.0.0.1"][1]; !ok {
+		t.Error("baseline of a kept interface should be kept")
+	}
+	if _, ok := p.prevCounters["10.0.0.1"][2]; ok {
+		t.Error("baseline of a removed interface should be dropped")
+	}
+	if _, ok := p.data["10.0.0.2"]; !ok {
+		t.Error("an added target should get stats")
+	}
+	if p.settings["10.0.0.2"].Interval != time.Second {
+		t.Errorf("an added target should get poll settings, got %+v", p.settings["10.0.0.2"])
+	}
+
+	other := *p.dash
+	other.Name = "other"
+	if err := p.Reload(&other); err == nil {
+		t.Error("expected an error reloading a different dashboard")
+	}
+	if err := NewManager().Update(&other); err == nil {
+		t.Error("expected an error updating a dashboard that is not running")
+	}
+}
+
+func TestReloadPollSettings(t *testing.T) {
+	p := newTestPoller(t, "Gi0/1")
+	host := "10.0.0.1"
+	p.data[host].Interfaces[0].addRate(RateSample{InRate: 1})
+	p.data[host].Interfaces[0].addRate(RateSample{InRate: 2})
+	p.clients[host] = &gosnmp.GoSNMP{}
+	st := p.targetStateLocked(host)
+	st.nextPoll = time.Now().Add(time.Hour)
+
+	// Same identity, port and settings: the session is kept.
+	if err := p.Reload(reloadedDashboard(p, p.dash.Groups[0].Targets[0])); err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+	if _, ok := p.clients[host]; !ok {
+		t.Error("an unchanged target should keep its session")
+	}
+
+	target := p.dash.Groups[0].Targets[0]
+	target.Identity = "other"
+	target.IntervalStr, target.Interval = "5s", 5*time.Second
+	dash := reloadedDashboard(p, target)
+	dash.MaxHistory = 1
+	if err := p.Reload(dash); err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+	if _, ok := p.clients[host]; ok {
+		t.Error("a changed identity should drop the session")
+	}
+	if !st.nextPoll.IsZero() {
+		t.Error("a changed interval should make the target due")
+	}
+	if p.tick != 5*time.Second {
+		t.Errorf("expected tick 5s, got %v", p.tick)
+	}
+	select {
+	case <-p.retick:
+	default:
+		t.Error("a changed tick should be signalled to the polling loop")
+	}
+	history := p.data[host].Interfaces[0].History.All()
+	if len(history) != 1 || history[0].InRate != 2 {
+		t.Errorf("expected history cut to the newest sample, got %+v", history)
+	}
+}
+
+func TestReloadRemovesTarget(t *testing.T) {
+	p := newTestPoller(t, "Gi0/1")
+	old := p.dash.Groups[0].Targets[0]
+	p.prevCounters["10.0.0.1"] = map[int]CounterSample{1: {InOctets: 1}}
+
+	if err := p.Reload(reloadedDashboard(p, dashboard.Target{Host: "10.0.0.2", Interfaces: []string{"Gi0/1"}})); err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+	if _, ok := p.data["10.0.0.1"]; ok {
+		t.Error("a removed target should lose its stats")
+	}
+	if _, ok := p.prevCounters["10.0.0.1"]; ok {
+		t.Error("a removed target should lose its baselines")
+	}
+
+	// A poll that was in flight when the target was removed.
+	p.commitTarget(old, targetPoll{indexes: []int{0}, samples: []interfaceSample{{status: "up"}}})
+	p.targetFailed(old, os.ErrDeadlineExceeded)
+	if _, ok := p.data["10.0.0.1"]; ok {
+		t.Error("a late poll should not bring a removed target back")
+	}
+}
+
+func TestReloadDiscardsStalePoll(t *testing.T) {
+	p := newTestPoller(t, "Gi0/1", "Gi0/2")
+	p.applyResolved("10.0.0.1", map[string]DiscoveredInterface{
+		"Gi0/1": {IfIndex: 1, Name: "Gi0/1"},
+		"Gi0/2": {IfIndex: 2, Name: "Gi0/2"},
+	})
+	target := p.dash.Groups[0].Targets[0]
+	poll := targetPoll{
+		indexes: p.interfaceIndexes("10.0.0.1"),
+		samples: []interfaceSample{{status: "up"}, {status: "up"}},
+	}
+
+	reloaded := target
+	reloaded.Interfaces = []string{"Gi0/2", "Gi0/1"}
+	if err := p.Reload(reloadedDashboard(p, reloaded)); err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+	p.commitTarget(target, poll)
+	for _, iface := range p.data["10.0.0.1"].Interfaces {
+		if iface.Status != "" {
+			t.Errorf("samples polled before the reload should be discarded, %s got %q", iface.Name, iface.Status)
+		}
+	}
+}
+
+func TestReloadRemapsMetrics(t *testing.T) {
+	p := newTestPoller(t)
+	a := dashboard.CustomOID{Label: "a", OID: "1.3.6.1.4.1.1.0", Type: dashboard.OIDKindGauge}
+	b := dashboard.CustomOID{Label: "b", OID: "1.3.6.1.4.1.2.0", Type: dashboard.OIDKindCounter}
+	c := dashboard.CustomOID{Label: "c", OID: "1.3.6.1.4.1.3.0", Type: dashboard.OIDKindGauge}
+	target := p.dash.Groups[0].Targets[0]
+	target.OIDs = []dashboard.CustomOID{a, b}
+	if err := p.Reload(reloadedDashboard(p, target)); err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+	ts := p.data["10.0.0.1"]
+	ts.Metrics[1].History.Add(MetricSample{Timestamp: time.Now(), Value: 5})
+	st := p.targetStateLocked("10.0.0.1")
+	st.metricPrev = []metricReading{{value: 1}, {value: 2}}
+
+	target.OIDs = []dashboard.CustomOID{b, c}
+	if err := p.Reload(reloadedDashboard(p, target)); err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+	if len(ts.Metrics) != 2 || ts.Metrics[0].Label != "b" || ts.Metrics[1].Label != "c" {
+		t.Fatalf("unexpected metrics %+v", ts.Metrics)
+	}
+	if ts.Metrics[0].History.Len() != 1 || ts.Metrics[1].History.Len() != 0 {
+		t.Error("a kept OID should keep its history and a new one start empty")
+	}
+	if st.metricPrev[0].value != 2 || st.metricPrev[1].value != 0 {
+		t.Errorf("previous readings should follow their OIDs, got %+v", st.metricPrev)
+	}
+}
+
+func TestReloadChanged(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.toml")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`name = "test"
+max_history = 10
+[[groups]]
+name = "Default"
+[[groups.targets]]
+host = "10.0.0.1"
+interfaces = ["Gi0/1"]
+`)
+
+	p := newTestPoller(t, "Gi0/1")
+	m := NewManager()
+	m.engines["test"] = p
+	w := newDashboardWatcher(dir)
+	if changed := w.changed(); len(changed) != 0 {
+		t.Errorf("existing files should not count as changed, got %v", changed)
+	}
+
+	write(`name = "test"
+max_history = 10
+[[groups]]
+name = "Default"
+[[groups.targets]]
+host = "10.0.0.1"
+interfaces = ["Gi0/1", "Gi0/2"]
+`)
+	m.reloadChanged(w)
+	if got := len(p.Snapshot().Groups[0].Targets[0].Interfaces); got != 2 {
+		t.Fatalf("expected the edited dashboard applied, got %d interfaces", got)
+	}
+
+	write(`name = "test`)
+	m.reloadChanged(w)
+	snap := p.Snapshot()
+	if snap.ConfigError == "" {
+		t.Error("a file that fails to parse should set the config error")
+	}
+	if got := len(snap.Groups[0].Targets[0].Interfaces); got != 2 {
+		t.Errorf("a bad file should leave the last good dashboard, got %d interfaces", got)
+	}
+
+	write(`name = "test"
+max_history = 10
+[[groups]]
+name = "Default"
+[[groups.targets]]
+host = "10.0.0.1"
+interfaces = ["Gi0/2"]
+`)
+	m.reloadChanged(w)
+	if snap := p.Snapshot(); snap.ConfigError != "" {
+		t.Errorf("a fixed file should clear the config error, got %q", snap.ConfigError)
+	}
+}
+
+func TestReloadDuringPoll(t *testing.T) {
+	p, _ := newSimulatedTarget(t, simulate.Scenario{}, 4, "Gi0/1", "Gi0/2", "Gi0/3")
+	target := p.dash.Groups[0].Targets[0]
+	p.pollTarget(target)
+
+	// Interfaces come and go while the target is being polled.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 10 {
+			p.pollTarget(target)
+		}
+	}()
+	for i := 0; ; i++ {
+		select {
+		case <-done:
+		default:
+			changed := target
+			if i%2 == 0 {
+				changed.Interfaces = []string{"Gi0/1"}
+			}
+			if err := p.Reload(reloadedDashboard(p, changed)); err != nil {
+				t.Fatalf("Reload() error: %v", err)
+			}
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		break
+	}
+
+	if err := p.Reload(reloadedDashboard(p, target)); err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+	p.pollTarget(target)
+	for _, iface := range p.Snapshot().Groups[0].Targets[0].Interfaces {
+		if iface.IfIndex == 0 || iface.PollError != nil || iface.Status != "up" {
+			t.Errorf("%s: expected resolved and up after the reloads, got ifIndex %d, %q (%v)", iface.Name, iface.IfIndex, iface.Status, iface.PollError)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package engine
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ReplayInfo describes the recording a replay engine is playing back.
+type ReplayInfo struct {
+	File     string
+	Speed    float64   // how many times faster than it was recorded
+	Start    time.Time // when the recording began
+	Position time.Time // recorded time of the last entry played back
+	Done     bool      // the whole recording has been played back
+	Error    string    // why the replay ended before the recording did
+}
+
+// replayer feeds a recording back through a poller.
+type replayer struct {
+	file  string
+	in    io.Closer
+	dec   *json.Decoder
+	speed float64
+	start time.Time
+	at    time.Time
+	done  bool
+	err   error
+}
+
+// ReplayName returns the name of the engine replaying a recording of the
+// named dashboard.
+func ReplayName(dashboard string) string {
+	return dashboard + " (replay)"
+}
+
+// NewReplay creates a Poller that plays back the recording at path instead
+// of polling: every recorded poll, failure, interface resolution and
+// dashboard reload is applied at the time it was recorded, speed times
+// faster, through the same code that applied it live. The replay's
+// snapshots, events and clock are those of the recording, so it renders as
+// it happened. The engine is named after the recorded dashboard with
+// ReplayName, and keeps showing the end of the recording until stopped.
+func NewReplay(path string, speed float64) (*Poller, error) {
+	if speed <= 0 {
+		return nil, fmt.Errorf("invalid replay speed %v", speed)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bufio.NewReader(f))
+	var head recordEntry
+	if err := dec.Decode(&head); err != nil || head.Kind != entryStart || head.Dashboard == nil {
+		f.Close()
+		return nil, fmt.Errorf("%s is not a flo recording", filepath.Base(path))
+	}
+	if head.Version > recordVersion {
+		f.Close()
+		return nil, fmt.Errorf("%s: unsupported recording version %d", filepath.Base(path), head.Version)
+	}
+
+	dash := head.Dashboard
+	dash.Name = ReplayName(dash.Name)
+	p, err := NewPoller(dash, nil)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	p.replay = &replayer{
+		file:  filepath.Base(path),
+		in:    f,
+		dec:   dec,
+		speed: speed,
+		start: head.Time,
+		at:    head.Time,
+	}
+	return p, nil
+}
+
+// runReplay plays the recording back at its pace, then keeps the final
+// state until the engine is stopped. A recording cut short, as by a crash,
+// plays back up to its last complete entry.
+func (p *Poller) runReplay() {
+	r := p.replay
+	defer r.in.Close()
+	began := time.Now()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		var e recordEntry
+		if err := r.dec.Decode(&e); err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+				r.err = err
+			}
+			break
+		}
+		due := time.Duration(float64(e.Time.Sub(r.start)) / r.speed)
+		timer.Reset(due - time.Since(began))
+		select {
+		case <-timer.C:
+		case <-p.stopCh:
+			p.shutdown()
+			return
+		}
+		p.applyEntry(e)
+	}
+
+	p.mu.Lock()
+	r.done = true
+	if p.phase == EngineStarting {
+		p.phase = EngineRunning
+	}
+	p.notify()
+	p.mu.Unlock()
+
+	<-p.stopCh
+	p.shutdown()
+}
+
+// applyEntry applies one recorded entry to the engine, as of the time it
+// was recorded.
+func (p *Poller) applyEntry(e recordEntry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if e.Time.After(p.replay.at) {
+		p.replay.at = e.Time
+	}
+
+	switch e.Kind {
+	case entryDashboard:
+		if e.Dashboard != nil {
+			e.Dashboard.Name = p.dash.Name
+			if err := p.reloadLocked(e.Dashboard); err != nil {
+				p.configErr = err
+			}
+		}
+	case entryResolved:
+		p.applyResolvedLocked(e.Host, e.Resolved)
+	case entryUnresolved:
+		p.resolveFailedLocked(e.Host)
+	case entryBaseline:
+		if _, ok := p.data[e.Host]; ok {
+			p.prevCounters[e.Host] = e.Counters
+		}
+	case entryPoll:
+		target, ok := p.targetConfigLocked(e.Host)
+		if !ok || e.Poll == nil {
+			break
+		}
+		st := p.targetStateLocked(e.Host)
+		st.uptime, st.uptimeAt = e.Poll.Uptime, e.Poll.UptimeAt
+		p.commitTarget(target, e.Poll.decode())
+	case entryFailed:
+		target, ok := p.targetConfigLocked(e.Host)
+		if !ok {
+			break
+		}
+		p.setTargetError(target, decodeError(e.Error))
+		p.recordFailureLocked(p.data[e.Host], p.targetStateLocked(e.Host), e.Time)
+	case entryCycle:
+		p.completeCycleLocked()
+		return
+	}
+	p.notify()
+}
+
+// replayLocked describes the replay, if the engine is one. Must be called
+// while holding at least a read lock on p.mu.
+func (p *Poller) replayLocked() *ReplayInfo {
+	r := p.replay
+	if r == nil {
+		return nil
+	}
+	return &ReplayInfo{
+		File:     r.file,
+		Speed:    r.speed,
+		Start:    r.start,
+		Position: r.at,
+		Done:     r.done,
+		Error:    errorText(r.err),
+	}
+}
+
+// now returns the engine's current time: the wall clock, or for a replay
+// the recorded time being played back. Must be called while holding a lock
+// on p.mu.
+func (p *Poller) now() time.Time {
+	if p.replay != nil {
+		return p.replay.at
+	}
+	return time.Now()
+}
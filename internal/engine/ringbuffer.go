@@ -3,6 +3,8 @@ package engine
 import "sync"
 
 // RingBuffer is a generic, thread-safe, fixed-capacity circular buffer.
+// Storage grows as items are added, so a large capacity costs nothing until
+// it is used.
 type RingBuffer[T any] struct {
 	mu    sync.RWMutex
 	items []T
@@ -14,8 +16,7 @@ type RingBuffer[T any] struct {
 // NewRingBuffer creates a new RingBuffer with the given capacity.
 func NewRingBuffer[T any](capacity int) *RingBuffer[T] {
 	return &RingBuffer[T]{
-		items: make([]T, capacity),
-		cap:   capacity,
+		cap: capacity,
 	}
 }
 
@@ -23,7 +24,11 @@ func NewRingBuffer[T any](capacity int) *RingBuffer[T] {
 func (r *RingBuffer[T]) Add(item T) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.items[r.head] = item
+	if len(r.items) < r.cap {
+		r.items = append(r.items, item)
+	} else {
+		r.items[r.head] = item
+	}
 	r.head = (r.head + 1) % r.cap
 	if r.count < r.cap {
 		r.count++
@@ -37,6 +42,26 @@ func (r *RingBuffer[T]) Len() int {
 	return r.count
 }
 
+// Cap returns the number of items the buffer holds before it overwrites the
+// oldest.
+func (r *RingBuffer[T]) Cap() int {
+	return r.cap
+}
+
+// Resized returns a buffer of the given capacity holding the most recent
+// items of r, or r itself if its capacity is already that.
+func (r *RingBuffer[T]) Resized(capacity int) *RingBuffer[T] {
+	if r.cap == capacity {
+		return r
+	}
+	resized := NewRingBuffer[T](capacity)
+	items := r.All()
+	for _, item := range items[max(len(items)-capacity, 0):] {
+		resized.Add(item)
+	}
+	return resized
+}
+
 // All returns all items in order from oldest to newest.
 func (r *RingBuffer[T]) All() []T {
 	r.mu.RLock()
@@ -56,3 +56,21 @@ func TestRingBufferLast(t *testing.T) {
 		t.Errorf("expected InRate=3, got %f", last.InRate)
 	}
 }
+
+func TestRingBufferResized(t *testing.T) {
+	rb := NewRingBuffer[RateSample](5)
+	for i := 0; i < 5; i++ {
+		rb.Add(RateSample{InRate: float64(i)})
+	}
+	if rb.Resized(5) != rb {
+		t.Error("resizing to the same capacity should return the buffer")
+	}
+	smaller := rb.Resized(2).All()
+	if len(smaller) != 2 || smaller[0].InRate != 3 || smaller[1].InRate != 4 {
+		t.Errorf("expected the newest 2 items, got %+v", smaller)
+	}
+	larger := rb.Resized(10)
+	if larger.Len() != 5 || larger.Cap() != 10 {
+		t.Errorf("expected 5 of 10 items, got %d of %d", larger.Len(), larger.Cap())
+	}
+}
@@ -0,0 +1,238 @@
+package engine
+
+import (
+	"sync"
+	"time"
+)
+
+// rollupTiers are the consolidated resolutions kept for every interface,
+// finest first, with how many buckets of each are kept: a day of 1-minute
+// buckets, a week of 5-minute buckets and 30 days of hourly buckets.
+var rollupTiers = []struct {
+	width   time.Duration
+	buckets int
+}{
+	{time.Minute, 24 * 60},
+	{5 * time.Minute, 7 * 24 * 12},
+	{time.Hour, 30 * 24},
+}
+
+// rollupSpan returns how far back the coarsest rollup tier reaches.
+func rollupSpan() time.Duration {
+	var span time.Duration
+	for _, t := range rollupTiers {
+		span = max(span, t.width*time.Duration(t.buckets))
+	}
+	return span
+}
+
+// Stat is the minimum, average and maximum of a value over a bucket.
+type Stat struct {
+	Min float64
+	Avg float64
+	Max float64
+}
+
+// add folds the nth value (counting from 1) into the stat.
+func (s *Stat) add(v float64, n int) {
+	if n == 1 {
+		*s = Stat{Min: v, Avg: v, Max: v}
+		return
+	}
+	s.Min = min(s.Min, v)
+	s.Max = max(s.Max, v)
+	s.Avg += (v - s.Avg) / float64(n)
+}
+
+// merge combines two stats over a and b values.
+func (s Stat) merge(o Stat, a, b int) Stat {
+	if a == 0 {
+		return o
+	}
+	if b == 0 {
+		return s
+	}
+	return Stat{
+		Min: min(s.Min, o.Min),
+		Avg: (s.Avg*float64(a) + o.Avg*float64(b)) / float64(a+b),
+		Max: max(s.Max, o.Max),
+	}
+}
+
+// RateBucket consolidates the rates of an interface over Width starting at
+// Start. A raw sample is a bucket of one sample and no width.
+type RateBucket struct {
+	Start   time.Time
+	Width   time.Duration
+	Samples int
+	InRate  Stat // bits/s
+	OutRate Stat // bits/s
+	InPPS   Stat // packets/s
+	OutPPS  Stat // packets/s
+}
+
+// End returns the end of the time the bucket covers.
+func (b RateBucket) End() time.Time {
+	return b.Start.Add(b.Width)
+}
+
+// add folds a rate into the bucket.
+func (b *RateBucket) add(r RateSample) {
+	b.Samples++
+	b.InRate.add(r.InRate, b.Samples)
+	b.OutRate.add(r.OutRate, b.Samples)
+	b.InPPS.add(r.InPPS.Total(), b.Samples)
+	b.OutPPS.add(r.OutPPS.Total(), b.Samples)
+}
+
+// merge combines two adjacent buckets into one spanning both.
+func (b RateBucket) merge(o RateBucket) RateBucket {
+	return RateBucket{
+		Start:   b.Start,
+		Width:   o.End().Sub(b.Start),
+		Samples: b.Samples + o.Samples,
+		InRate:  b.InRate.merge(o.InRate, b.Samples, o.Samples),
+		OutRate: b.OutRate.merge(o.OutRate, b.Samples, o.Samples),
+		InPPS:   b.InPPS.merge(o.InPPS, b.Samples, o.Samples),
+		OutPPS:  b.OutPPS.merge(o.OutPPS, b.Samples, o.Samples),
+	}
+}
+
+// rawBucket returns a raw sample as a bucket.
+func rawBucket(r RateSample) RateBucket {
+	var b RateBucket
+	b.add(r)
+	b.Start = r.Timestamp
+	return b
+}
+
+// rollupTier is one resolution of an interface's consolidated history: the
+// completed buckets and the one still filling.
+type rollupTier struct {
+	width time.Duration
+	done  *RingBuffer[RateBucket]
+	open  RateBucket
+}
+
+// Rollups consolidates an interface's rates into 1-minute, 5-minute and
+// hourly min/avg/max buckets, so that days or weeks of history fit in a few
+// thousand points. It is safe for concurrent use.
+type Rollups struct {
+	mu    sync.RWMutex
+	tiers []*rollupTier
+}
+
+// NewRollups creates empty rollups at every tier.
+func NewRollups() *Rollups {
+	r := &Rollups{}
+	for _, t := range rollupTiers {
+		r.tiers = append(r.tiers, &rollupTier{width: t.width, done: NewRingBuffer[RateBucket](t.buckets)})
+	}
+	return r
+}
+
+// Add consolidates a rate into each tier, closing the tier's open bucket
+// when the rate falls into a later one. Rates older than the open bucket
+// are ignored.
+func (r *Rollups) Add(rate RateSample) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tiers {
+		start := rate.Timestamp.Truncate(t.width)
+		if start.Before(t.open.Start) {
+			continue
+		}
+		if !start.Equal(t.open.Start) {
+			if t.open.Samples > 0 {
+				t.done.Add(t.open)
+			}
+			t.open = RateBucket{Start: start, Width: t.width}
+		}
+		t.open.add(rate)
+	}
+}
+
+// addRate records a rate in the interface's raw history and its rollups.
+func (iface *InterfaceStats) addRate(r RateSample) {
+	iface.History.Add(r)
+	if iface.Rollups != nil {
+		iface.Rollups.Add(r)
+	}
+}
+
+// all returns a tier's buckets, including the open one, oldest first.
+func (t *rollupTier) all() []RateBucket {
+	all := t.done.All()
+	if t.open.Samples > 0 {
+		all = append(all, t.open)
+	}
+	return all
+}
+
+// rateSources returns the interface's raw samples as buckets and then the
+// buckets of each rollup tier, finest first and each oldest first.
+func (iface *InterfaceStats) rateSources() [][]RateBucket {
+	var sources [][]RateBucket
+	if iface.History != nil {
+		raw := iface.History.All()
+		buckets := make([]RateBucket, len(raw))
+		for i, s := range raw {
+			buckets[i] = rawBucket(s)
+		}
+		sources = append(sources, buckets)
+	}
+	if iface.Rollups != nil {
+		iface.Rollups.mu.RLock()
+		for _, t := range iface.Rollups.tiers {
+			sources = append(sources, t.all())
+		}
+		iface.Rollups.mu.RUnlock()
+	}
+	return sources
+}
+
+// RateRange returns an interface's rates over [from, to), at the finest
+// resolution whose oldest entry reaches back to from: the raw samples, or
+// else the buckets of the first rollup tier that does. A coarser tier is
+// only used when a whole bucket of it predates the finer resolution's data,
+// so when nothing reaches back to from, as early on or after a restart, the
+// finest one holding the oldest data is used. When there are more than
+// points buckets, adjacent ones are merged so that at most points remain.
+func (iface *InterfaceStats) RateRange(from, to time.Time, points int) []RateBucket {
+	var best []RateBucket
+	for _, src := range iface.rateSources() {
+		if len(src) == 0 {
+			continue
+		}
+		if best == nil || !src[0].End().After(best[0].Start) {
+			best = src
+		}
+		if !best[0].Start.After(from) {
+			break
+		}
+	}
+	var out []RateBucket
+	for _, b := range best {
+		if b.Start.Before(to) && (!b.Start.Before(from) || b.End().After(from)) {
+			out = append(out, b)
+		}
+	}
+	return downsample(out, points)
+}
+
+// downsample merges runs of adjacent buckets so that at most points remain.
+func downsample(buckets []RateBucket, points int) []RateBucket {
+	if points <= 0 || len(buckets) <= points {
+		return buckets
+	}
+	per := (len(buckets) + points - 1) / points
+	out := make([]RateBucket, 0, points)
+	for i := 0; i < len(buckets); i += per {
+		b := buckets[i]
+		for _, next := range buckets[i+1 : min(i+per, len(buckets))] {
+			b = b.merge(next)
+		}
+		out = append(out, b)
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRollupsAdd(t *testing.T) {
+	r := NewRollups()
+	start := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	// 10 minutes of 10-second samples ramping from 0 to 59 kbps.
+	for i := range 60 {
+		r.Add(RateSample{
+			Timestamp: start.Add(time.Duration(i) * 10 * time.Second),
+			InRate:    float64(i) * 1000,
+			InPPS:     PacketRates{Unicast: 10, Broadcast: 1},
+		})
+	}
+	minute := r.tiers[0]
+	if n := minute.done.Len(); n != 9 {
+		t.Fatalf("expected 9 closed 1-minute buckets, got %d", n)
+	}
+	first := minute.done.All()[0]
+	if first.Samples != 6 || first.InRate != (Stat{Min: 0, Avg: 2500, Max: 5000}) {
+		t.Errorf("unexpected first bucket %+v", first)
+	}
+	if first.InPPS.Avg != 11 {
+		t.Errorf("expected packet rates to be totalled, got %v", first.InPPS.Avg)
+	}
+	if minute.open.Samples != 6 || !minute.open.Start.Equal(start.Add(9*time.Minute)) {
+		t.Errorf("unexpected open bucket %+v", minute.open)
+	}
+	five := r.tiers[1]
+	if five.done.Len() != 1 || five.done.All()[0].InRate.Max != 29000 {
+		t.Errorf("unexpected 5-minute buckets %+v", five.done.All())
+	}
+
+	// A late sample cannot reopen a closed bucket.
+	r.Add(RateSample{Timestamp: start, InRate: 1e9})
+	if minute.done.All()[0].InRate.Max != 5000 {
+		t.Error("a late sample should be ignored")
+	}
+}
+
+func TestRateRange(t *testing.T) {
+	iface := &InterfaceStats{History: NewRingBuffer[RateSample](6), Rollups: NewRollups()}
+	start := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	for i := range 120 {
+		iface.addRate(RateSample{Timestamp: start.Add(time.Duration(i) * 10 * time.Second), OutRate: float64(i)})
+	}
+	end := start.Add(20 * time.Minute)
+
+	// The raw samples cover the last minute.
+	raw := iface.RateRange(end.Add(-time.Minute), end, 0)
+	if len(raw) != 6 || raw[0].Width != 0 || raw[5].OutRate.Avg != 119 {
+		t.Errorf("expected the 6 raw samples, got %+v", raw)
+	}
+
+	// Longer ranges come from the 1-minute tier, open bucket included.
+	buckets := iface.RateRange(start, end, 0)
+	if len(buckets) != 20 || buckets[0].Width != time.Minute {
+		t.Fatalf("expected 20 one-minute buckets, got %d", len(buckets))
+	}
+	if buckets[19].OutRate.Max != 119 {
+		t.Errorf("expected the open bucket last, got %+v", buckets[19])
+	}
+
+	merged := iface.RateRange(start, end, 8)
+	if len(merged) != 7 {
+		t.Fatalf("expected 20 buckets merged 3 at a time into 7, got %d", len(merged))
+	}
+	if m := merged[0]; m.Samples != 18 || m.Width != 3*time.Minute || m.OutRate.Min != 0 || m.OutRate.Max != 17 || m.OutRate.Avg != 8.5 {
+		t.Errorf("unexpected merged bucket %+v", m)
+	}
+
+	// Just after starting, the raw samples are all there is to chart, and
+	// are finer than the rollups holding the same data.
+	fresh := &InterfaceStats{History: NewRingBuffer[RateSample](10), Rollups: NewRollups()}
+	for i := range 5 {
+		fresh.addRate(RateSample{Timestamp: start.Add(time.Duration(i) * 10 * time.Second)})
+	}
+	if got := fresh.RateRange(end.Add(-time.Hour), end, 0); len(got) != 5 || got[0].Width != 0 {
+		t.Errorf("expected the 5 raw samples, got %+v", got)
+	}
+}
@@ -0,0 +1,574 @@
+package engine
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// sensorPollInterval is how often entity sensors are read. Light levels and
+// temperatures change slowly, and a port-dense chassis can have hundreds of
+// sensors.
+const sensorPollInterval = time.Minute
+
+// EntitySensorDataType values (ENTITY-SENSOR-MIB; dBm is an extension from
+// CISCO-ENTITY-SENSOR-MIB that other vendors have adopted).
+const (
+	sensorTypeVoltsDC = 4
+	sensorTypeAmperes = 5
+	sensorTypeWatts   = 6
+	sensorTypeCelsius = 8
+	sensorTypeRPM     = 10
+	sensorTypeDBm     = 14
+)
+
+// PhysicalClass values from ENTITY-MIB.
+const (
+	entityClassPowerSupply = 6
+	entityClassFan         = 7
+	entityClassPort        = 10
+)
+
+// darkDBm is reported for an optic whose power reads as 0 W, which has no
+// logarithm; it is below the sensitivity of any common receiver.
+const darkDBm = -40.0
+
+// sensorColumns names the columns of a sensor value table.
+type sensorColumns struct {
+	typ, scale, precision, value, status string
+}
+
+var (
+	entitySensorColumns = sensorColumns{OIDentPhySensorType, OIDentPhySensorScale, OIDentPhySensorPrecision, OIDentPhySensorValue, OIDentPhySensorOperStatus}
+	ciscoSensorColumns  = sensorColumns{OIDentSensorType, OIDentSensorScale, OIDentSensorPrecision, OIDentSensorValue, OIDentSensorStatus}
+)
+
+// entitySensor is a physical sensor learned from the entity tables.
+type entitySensor struct {
+	name       string
+	dataType   int
+	scale      int // EntitySensorDataScale
+	precision  int
+	valueOID   string
+	statusOID  string
+	ifIndex    int  // interface the sensor belongs to; 0 if none
+	inPort     bool // contained in a port, e.g. a transceiver sensor
+	role       string
+	thresholds []SensorThreshold
+}
+
+// chassis reports whether the sensor belongs to the chassis rather than to
+// a port's transceiver.
+func (s entitySensor) chassis() bool {
+	return s.ifIndex == 0 && !s.inPort
+}
+
+// entityComponent is a power supply or fan whose operational state is
+// polled.
+type entityComponent struct {
+	name   string
+	role   string // SensorPowerSupply or SensorFan
+	oid    string
+	decode func(int) (status, state string)
+}
+
+// sensorPlan records the sensors and components a target reports.
+type sensorPlan struct {
+	discovery
+	sensors    []entitySensor
+	components []entityComponent
+}
+
+// empty reports whether the device reported no sensors or components.
+func (s *sensorPlan) empty() bool {
+	return len(s.sensors) == 0 && len(s.components) == 0
+}
+
+// discoverSensors walks the sensor tables and the parts of ENTITY-MIB
+// needed to name sensors, find power supplies and fans, and map sensors to
+// interfaces. The rest of the entity tables are only walked if the device
+// has sensors or components at all.
+func discoverSensors(client *gosnmp.GoSNMP) (*sensorPlan, error) {
+	tables := make(map[string]map[string]gosnmp.SnmpPDU)
+	if err := walkColumns(client, tables, OIDentPhySensorType, OIDentSensorType, OIDentPhysicalClass); err != nil {
+		return nil, err
+	}
+	cols := entitySensorColumns
+	if len(tables[OIDentPhySensorType]) == 0 {
+		cols = ciscoSensorColumns
+	}
+	hasSensors := len(tables[cols.typ]) > 0
+	hasComponents := false
+	for _, pdu := range tables[OIDentPhysicalClass] {
+		if c := pduInt(pdu); c == entityClassPowerSupply || c == entityClassFan {
+			hasComponents = true
+			break
+		}
+	}
+	if !hasSensors && !hasComponents {
+		return &sensorPlan{}, nil
+	}
+
+	walk := []string{OIDentPhysicalName, OIDentPhysicalDescr, OIDentPhysicalContainedIn, OIDentAliasMappingIdentifier}
+	if hasSensors {
+		walk = append(walk, cols.scale, cols.precision)
+	}
+	if len(tables[OIDentSensorType]) > 0 {
+		walk = append(walk, OIDentSensorThresholdSeverity, OIDentSensorThresholdRelation, OIDentSensorThresholdValue)
+	}
+	if hasComponents {
+		walk = append(walk, OIDentStateOper)
+	}
+	if err := walkColumns(client, tables, walk...); err != nil {
+		return nil, err
+	}
+	if hasComponents && len(tables[OIDentStateOper]) == 0 {
+		if err := walkColumns(client, tables, OIDcefcFRUPowerOperStatus, OIDcefcFanTrayOperStatus); err != nil {
+			return nil, err
+		}
+	}
+	return planSensors(tables), nil
+}
+
+// planSensors builds the sensor plan from walked columns, preferring the
+// standard sensor table over the Cisco one.
+func planSensors(tables map[string]map[string]gosnmp.SnmpPDU) *sensorPlan {
+	cols := entitySensorColumns
+	if len(tables[OIDentPhySensorType]) == 0 {
+		cols = ciscoSensorColumns
+	}
+	name := func(idx string) string {
+		if n := pduString(tables[OIDentPhysicalName][idx]); n != "" {
+			return n
+		}
+		return pduString(tables[OIDentPhysicalDescr][idx])
+	}
+	tree := entityTree{
+		containedIn: tables[OIDentPhysicalContainedIn],
+		classes:     tables[OIDentPhysicalClass],
+		aliases:     interfaceAliases(tables[OIDentAliasMappingIdentifier]),
+	}
+	thresholds := thresholdRows(tables[OIDentSensorThresholdSeverity])
+
+	plan := &sensorPlan{}
+	for _, idx := range sortedInstances(tables[cols.typ]) {
+		s := entitySensor{
+			name:      name(idx),
+			dataType:  pduInt(tables[cols.typ][idx]),
+			scale:     pduInt(tables[cols.scale][idx]),
+			precision: pduInt(tables[cols.precision][idx]),
+			valueOID:  cols.value + "." + idx,
+			statusOID: cols.status + "." + idx,
+		}
+		s.ifIndex, s.inPort = tree.locate(idx)
+		s.role = sensorRole(s.dataType, s.name)
+		for _, row := range thresholds[idx] {
+			if t, ok := s.threshold(tables, row); ok {
+				s.thresholds = append(s.thresholds, t)
+			}
+		}
+		plan.sensors = append(plan.sensors, s)
+	}
+
+	for _, idx := range sortedInstances(tables[OIDentPhysicalClass]) {
+		c := entityComponent{name: name(idx)}
+		switch pduInt(tables[OIDentPhysicalClass][idx]) {
+		case entityClassPowerSupply:
+			c.role = SensorPowerSupply
+		case entityClassFan:
+			c.role = SensorFan
+		default:
+			continue
+		}
+		switch {
+		case tables[OIDentStateOper][idx].Value != nil:
+			c.oid, c.decode = OIDentStateOper+"."+idx, entityOperState
+		case c.role == SensorPowerSupply && tables[OIDcefcFRUPowerOperStatus][idx].Value != nil:
+			c.oid, c.decode = OIDcefcFRUPowerOperStatus+"."+idx, ciscoPowerState
+		case c.role == SensorFan && tables[OIDcefcFanTrayOperStatus][idx].Value != nil:
+			c.oid, c.decode = OIDcefcFanTrayOperStatus+"."+idx, ciscoFanState
+		default:
+			continue
+		}
+		plan.components = append(plan.components, c)
+	}
+	return plan
+}
+
+// entityTree is the ENTITY-MIB containment tree of a device.
+type entityTree struct {
+	containedIn map[string]gosnmp.SnmpPDU
+	classes     map[string]gosnmp.SnmpPDU
+	aliases     map[string]int // entPhysicalIndex to ifIndex
+}
+
+// interfaceAliases maps entPhysicalIndex to ifIndex from the
+// entAliasMappingTable, whose values are ifIndex instance OIDs.
+func interfaceAliases(rows map[string]gosnmp.SnmpPDU) map[string]int {
+	const ifIndexColumn = "1.3.6.1.2.1.2.2.1.1."
+	aliases := make(map[string]int)
+	for suffix, pdu := range rows {
+		phys, _, _ := strings.Cut(suffix, ".")
+		target := strings.TrimPrefix(pduString(pdu), ".")
+		if idx, ok := strings.CutPrefix(target, ifIndexColumn); ok {
+			if n, err := strconv.Atoi(idx); err == nil {
+				aliases[phys] = n
+			}
+		}
+	}
+	return aliases
+}
+
+// locate returns the ifIndex of the port an entity belongs to, by climbing
+// the containment tree until an entity that is aliased to an interface.
+// Sensors are usually contained in a transceiver module, which is
+// contained in (or is) the port. inPort is true if any ancestor is a port,
+// even one without an interface alias.
+func (t entityTree) locate(idx string) (ifIndex int, inPort bool) {
+	for depth := 0; depth < 8 && idx != "0" && idx != ""; depth++ {
+		if pduInt(t.classes[idx]) == entityClassPort {
+			inPort = true
+		}
+		if ifIndex, ok := t.aliases[idx]; ok {
+			return ifIndex, true
+		}
+		parent, ok := t.containedIn[idx]
+		if !ok {
+			break
+		}
+		idx = strconv.Itoa(pduInt(parent))
+	}
+	return 0, inPort
+}
+
+// thresholdRows groups CISCO-ENTITY-SENSOR-MIB threshold row suffixes
+// (entPhysicalIndex.entSensorThresholdIndex) by sensor.
+func thresholdRows(severities map[string]gosnmp.SnmpPDU) map[string][]string {
+	rows := make(map[string][]string)
+	for _, suffix := range sortedInstances(severities) {
+		phys, _, _ := strings.Cut(suffix, ".")
+		rows[phys] = append(rows[phys], suffix)
+	}
+	return rows
+}
+
+// threshold decodes one vendor threshold row for the sensor. Equality
+// thresholds are ignored since they do not describe a range.
+func (s entitySensor) threshold(tables map[string]map[string]gosnmp.SnmpPDU, row string) (SensorThreshold, bool) {
+	t := SensorThreshold{Severity: SensorWarning}
+	// entSensorThresholdSeverity: other(1), minor(10), major(20), critical(30)
+	if pduInt(tables[OIDentSensorThresholdSeverity][row]) >= 20 {
+		t.Severity = SensorAlarm
+	}
+	// entSensorThresholdRelation: lessThan(1), lessOrEqual(2),
+	// greaterThan(3), greaterOrEqual(4), equalTo(5), notEqualTo(6)
+	switch pduInt(tables[OIDentSensorThresholdRelation][row]) {
+	case 1, 2:
+	case 3, 4:
+		t.High = true
+	default:
+		return t, false
+	}
+	value, ok := tables[OIDentSensorThresholdValue][row]
+	if !ok {
+		return t, false
+	}
+	t.Value, _ = s.convert(float64(gosnmp.ToBigInt(value.Value).Int64()))
+	return t, true
+}
+
+// sensorRole classifies a sensor by its data type and, for optical power,
+// by its name.
+func sensorRole(dataType int, name string) string {
+	switch dataType {
+	case sensorTypeCelsius:
+		return SensorTemperature
+	case sensorTypeAmperes:
+		return SensorCurrent
+	case sensorTypeVoltsDC:
+		return SensorVoltage
+	case sensorTypeRPM:
+		return SensorFanSpeed
+	case sensorTypeDBm, sensorTypeWatts:
+		words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+			return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+		})
+		for _, w := range words {
+			switch w {
+			case "rx", "receive", "received", "input":
+				return SensorRxPower
+			case "tx", "transmit", "transmitted", "output":
+				return SensorTxPower
+			}
+		}
+		if dataType == sensorTypeWatts {
+			return SensorPower
+		}
+	}
+	return ""
+}
+
+// convert turns a raw sensor value into the unit flo displays it in,
+// applying the sensor's scale and precision. Currents are shown in mA and
+// optical power in dBm, whichever unit the agent reports it in.
+func (s entitySensor) convert(raw float64) (float64, string) {
+	v := raw
+	// Divide for negative exponents: -152 / 10 is exactly the float64
+	// nearest -15.2, while -152 * 0.1 is not.
+	if exp := sensorExponent(s.scale) - s.precision; exp < 0 {
+		v /= math.Pow10(-exp)
+	} else {
+		v *= math.Pow10(exp)
+	}
+	switch s.dataType {
+	case sensorTypeCelsius:
+		return v, "°C"
+	case sensorTypeAmperes:
+		return v * 1000, "mA"
+	case sensorTypeVoltsDC:
+		return v, "V"
+	case sensorTypeRPM:
+		return v, "RPM"
+	case sensorTypeDBm:
+		return v, "dBm"
+	case sensorTypeWatts:
+		if s.role == SensorRxPower || s.role == SensorTxPower {
+			if v <= 0 {
+				return darkDBm, "dBm"
+			}
+			return 10 * math.Log10(v*1000), "dBm"
+		}
+		return v, "W"
+	}
+	return v, ""
+}
+
+// sensorExponent returns the power of ten of an EntitySensorDataScale,
+// where units(9) is 10^0 and each step is a factor of 1000.
+func sensorExponent(scale int) int {
+	if scale < 1 || scale > 17 {
+		return 0
+	}
+	return 3 * (scale - 9)
+}
+
+// reading builds the sensor's current reading from its raw value and
+// entPhySensorOperStatus (ok(1), unavailable(2), nonoperational(3)).
+func (s entitySensor) reading(raw float64, operStatus int) SensorReading {
+	value, unit := s.convert(raw)
+	r := SensorReading{
+		Name:       s.name,
+		Role:       s.role,
+		Value:      value,
+		Unit:       unit,
+		Status:     SensorOK,
+		Thresholds: s.thresholds,
+	}
+	if operStatus > 1 {
+		r.Status = SensorUnavailable
+		return r
+	}
+	r.Status = thresholdState(value, s.thresholds)
+	return r
+}
+
+// thresholdState returns the most severe threshold the value has crossed.
+func thresholdState(value float64, thresholds []SensorThreshold) string {
+	state := SensorOK
+	for _, t := range thresholds {
+		crossed := t.High && value >= t.Value || !t.High && value <= t.Value
+		if !crossed {
+			continue
+		}
+		if t.Severity == SensorAlarm {
+			return SensorAlarm
+		}
+		state = SensorWarning
+	}
+	return state
+}
+
+// pduInt returns an integer-valued varbind's value.
+func pduInt(v gosnmp.SnmpPDU) int {
+	return int(gosnmp.ToBigInt(v.Value).Int64())
+}
+
+// sensorResult pairs a sensor with its current reading.
+type sensorResult struct {
+	sensor  entitySensor
+	reading SensorReading
+}
+
+// readSensors GETs the value and status of each sensor. A sensor that no
+// longer exists marks the plan stale and is left out.
+func readSensors(client *gosnmp.GoSNMP, st *targetState, sensors []entitySensor) ([]sensorResult, error) {
+	names := make([]string, 0, 2*len(sensors))
+	for _, s := range sensors {
+		names = append(names, s.valueOID, s.statusOID)
+	}
+	raw := getReadings(client, st, names)
+
+	results := make([]sensorResult, 0, len(sensors))
+	for i, s := range sensors {
+		value, status := raw[2*i], raw[2*i+1]
+		switch {
+		case errors.Is(value.err, ErrNoSuchInstance):
+			st.sensors.stale = true
+			continue
+		case value.err != nil:
+			return nil, value.err
+		}
+		results = append(results, sensorResult{s, s.reading(value.value, int(status.value))})
+	}
+	return results, nil
+}
+
+// entityOperState decodes ENTITY-STATE-MIB entStateOper.
+func entityOperState(v int) (status, state string) {
+	switch v {
+	case 2:
+		return SensorAlarm, "disabled"
+	case 3:
+		return SensorOK, "enabled"
+	case 4:
+		return SensorWarning, "testing"
+	}
+	return SensorUnavailable, "unknown"
+}
+
+// ciscoPowerState decodes CISCO-ENTITY-FRU-CONTROL-MIB
+// cefcFRUPowerOperStatus.
+func ciscoPowerState(v int) (status, state string) {
+	switch v {
+	case 2:
+		return SensorOK, "on"
+	case 3:
+		return SensorWarning, "off (admin)"
+	case 9:
+		return SensorWarning, "on, fan failed"
+	case 12:
+		return SensorWarning, "on, inline power failed"
+	case 8:
+		return SensorAlarm, "failed"
+	case 1, 4, 5, 6, 7, 10, 11:
+		return SensorAlarm, "off"
+	}
+	return SensorUnavailable, "unknown"
+}
+
+// ciscoFanState decodes CISCO-ENTITY-FRU-CONTROL-MIB cefcFanTrayOperStatus.
+func ciscoFanState(v int) (status, state string) {
+	switch v {
+	case 2:
+		return SensorOK, "up"
+	case 3:
+		return SensorAlarm, "down"
+	case 4:
+		return SensorWarning, "warning"
+	}
+	return SensorUnavailable, "unknown"
+}
+
+// readComponents GETs the operational state of each power supply and fan.
+// A component that no longer exists marks the plan stale and is left out.
+func readComponents(client *gosnmp.GoSNMP, st *targetState, components []entityComponent) ([]SensorReading, error) {
+	names := make([]string, len(components))
+	for i, c := range components {
+		names[i] = c.oid
+	}
+	raw := getReadings(client, st, names)
+
+	readings := make([]SensorReading, 0, len(components))
+	for i, c := range components {
+		switch {
+		case errors.Is(raw[i].err, ErrNoSuchInstance):
+			st.sensors.stale = true
+			continue
+		case raw[i].err != nil:
+			return nil, raw[i].err
+		}
+		status, state := c.decode(int(raw[i].value))
+		readings = append(readings, SensorReading{Name: c.name, Role: c.role, Status: status, State: state})
+	}
+	return readings, nil
+}
+
+// sensorPoll is the result of reading a target's sensors.
+type sensorPoll struct {
+	optics  map[int][]SensorReading // transceiver sensors by ifIndex
+	chassis []SensorReading         // chassis sensors, then power supplies and fans
+}
+
+// pollSensors reads the chassis sensors, power supplies and fans, and the
+// transceiver sensors of the monitored interfaces. It returns nil when the
+// sensors are not due this cycle or could not be read, in which case the
+// previous readings are kept.
+func pollSensors(client *gosnmp.GoSNMP, st *targetState, indexes []int, restarted bool) *sensorPoll {
+	now := time.Now()
+	if !restarted && now.Sub(st.sensorsAt) < sensorPollInterval {
+		return nil
+	}
+	st.sensorsAt = now
+	if needsDiscovery(st.sensors, now, restarted) {
+		plan, err := discoverSensors(client)
+		if err != nil {
+			return nil
+		}
+		plan.learnedAt = now
+		st.sensors = plan
+	}
+
+	monitored := make(map[int]bool, len(indexes))
+	for _, idx := range indexes {
+		if idx > 0 {
+			monitored[idx] = true
+		}
+	}
+	var sensors []entitySensor
+	for _, s := range st.sensors.sensors {
+		if monitored[s.ifIndex] || s.chassis() {
+			sensors = append(sensors, s)
+		}
+	}
+
+	results, err := readSensors(client, st, sensors)
+	if err != nil {
+		return nil
+	}
+	components, err := readComponents(client, st, st.sensors.components)
+	if err != nil {
+		return nil
+	}
+	poll := &sensorPoll{optics: make(map[int][]SensorReading)}
+	for _, r := range results {
+		if r.sensor.chassis() {
+			poll.chassis = append(poll.chassis, r.reading)
+		} else {
+			poll.optics[r.sensor.ifIndex] = append(poll.optics[r.sensor.ifIndex], r.reading)
+		}
+	}
+	poll.chassis = append(poll.chassis, components...)
+	return poll
+}
+
+// commitSensorsLocked applies a reading of the chassis sensors to the
+// target's stats and records an event for each sensor whose status changed.
+// Must be called while holding the write lock on p.mu.
+func (p *Poller) commitSensorsLocked(ts *TargetStats, readings []SensorReading, now time.Time) {
+	type key struct{ name, role string }
+	prev := make(map[key]SensorReading, len(ts.Sensors))
+	for _, r := range ts.Sensors {
+		prev[key{r.Name, r.Role}] = r
+	}
+	for _, r := range readings {
+		old, ok := prev[key{r.Name, r.Role}]
+		if ok && old.Status != r.Status {
+			p.recordEventLocked(ts, EventSensorState, r.Name, old.Status, r.Status, r.State, now)
+		}
+	}
+	ts.Sensors = readings
+}
@@ -0,0 +1,202 @@
+package engine
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+func TestPlanSensors(t *testing.T) {
+	// Port 100 is Gi1/0/1 (ifIndex 5); transceiver 101 sits in it and
+	// carries the DOM sensors. Sensor 200 is a chassis inlet sensor.
+	tables := map[string]map[string]gosnmp.SnmpPDU{
+		OIDentSensorType: {
+			"102": {Value: sensorTypeDBm},
+			"103": {Value: sensorTypeCelsius},
+			"200": {Value: sensorTypeCelsius},
+		},
+		OIDentSensorScale:     {"102": {Value: 9}, "103": {Value: 9}, "200": {Value: 9}},
+		OIDentSensorPrecision: {"102": {Value: 1}, "103": {Value: 0}, "200": {Value: 0}},
+		OIDentPhysicalName: {
+			"102": {Value: []byte("Gi1/0/1 Receive Power Sensor")},
+			"103": {Value: []byte("Gi1/0/1 Module Temperature Sensor")},
+			"200": {Value: []byte("Inlet")},
+		},
+		OIDentPhysicalContainedIn: {
+			"101": {Value: 100},
+			"102": {Value: 101},
+			"103": {Value: 101},
+			"200": {Value: 1},
+		},
+		OIDentAliasMappingIdentifier: {
+			"100.0": {Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.2.1.2.2.1.1.5"},
+		},
+		OIDentSensorThresholdSeverity: {"102.1": {Value: 30}, "102.2": {Value: 10}, "102.3": {Value: 5}},
+		OIDentSensorThresholdRelation: {"102.1": {Value: 1}, "102.2": {Value: 4}, "102.3": {Value: 5}},
+		OIDentSensorThresholdValue:    {"102.1": {Value: -140}, "102.2": {Value: 20}, "102.3": {Value: 0}},
+	}
+
+	plan := planSensors(tables)
+	if len(plan.sensors) != 3 {
+		t.Fatalf("expected 3 sensors, got %d", len(plan.sensors))
+	}
+	rx, temp, inlet := plan.sensors[0], plan.sensors[1], plan.sensors[2]
+	if rx.ifIndex != 5 || temp.ifIndex != 5 {
+		t.Errorf("transceiver sensors should map to ifIndex 5, got %d and %d", rx.ifIndex, temp.ifIndex)
+	}
+	if inlet.ifIndex != 0 {
+		t.Errorf("chassis sensor should not map to an interface, got %d", inlet.ifIndex)
+	}
+	if rx.role != SensorRxPower || temp.role != SensorTemperature {
+		t.Errorf("unexpected roles %q and %q", rx.role, temp.role)
+	}
+	if rx.valueOID != OIDentSensorValue+".102" {
+		t.Errorf("expected the Cisco value column, got %s", rx.valueOID)
+	}
+
+	want := []SensorThreshold{
+		{Severity: SensorAlarm, Value: -14},
+		{Severity: SensorWarning, High: true, Value: 2},
+	}
+	if len(rx.thresholds) != len(want) {
+		t.Fatalf("thresholds = %+v, want %+v", rx.thresholds, want)
+	}
+	for i := range want {
+		if rx.thresholds[i] != want[i] {
+			t.Errorf("threshold %d = %+v, want %+v", i, rx.thresholds[i], want[i])
+		}
+	}
+
+	if r := rx.reading(-152, 1); r.Status != SensorAlarm || r.Value != -15.2 || r.Unit != "dBm" {
+		t.Errorf("expected -15.2 dBm in alarm, got %+v", r)
+	}
+	if r := rx.reading(-31, 1); r.Status != SensorOK {
+		t.Errorf("expected -3.1 dBm to be ok, got %s", r.Status)
+	}
+	if r := rx.reading(-31, 2); r.Status != SensorUnavailable {
+		t.Errorf("expected an unavailable sensor, got %s", r.Status)
+	}
+}
+
+func TestSensorConvert(t *testing.T) {
+	// 0.5 mW reported in microwatts.
+	tx := entitySensor{dataType: sensorTypeWatts, scale: 7, role: SensorTxPower}
+	if v, unit := tx.convert(500); unit != "dBm" || math.Abs(v-(-3.0103)) > 0.001 {
+		t.Errorf("expected about -3.01 dBm, got %v %s", v, unit)
+	}
+	if v, _ := tx.convert(0); v != darkDBm {
+		t.Errorf("expected a dark optic at %v dBm, got %v", darkDBm, v)
+	}
+	// 6.5 mA bias reported in microamperes.
+	bias := entitySensor{dataType: sensorTypeAmperes, scale: 7}
+	if v, unit := bias.convert(6500); unit != "mA" || math.Abs(v-6.5) > 1e-9 {
+		t.Errorf("expected 6.5 mA, got %v %s", v, unit)
+	}
+}
+
+func TestPlanSensorsChassis(t *testing.T) {
+	// Entity 10 is a port without an interface alias; its transceiver
+	// sensor 12 must not be taken for a chassis sensor. Entities 20 and 21
+	// are a power supply and a fan tray, 22 is a fan speed sensor in the
+	// tray, and 30 is a power supply the device reports no state for.
+	tables := map[string]map[string]gosnmp.SnmpPDU{
+		OIDentPhySensorType: {
+			"12": {Value: sensorTypeCelsius},
+			"22": {Value: sensorTypeRPM},
+		},
+		OIDentPhySensorScale:     {"12": {Value: 9}, "22": {Value: 9}},
+		OIDentPhySensorPrecision: {"12": {Value: 0}, "22": {Value: 0}},
+		OIDentPhysicalClass: {
+			"1":  {Value: 3},
+			"10": {Value: entityClassPort},
+			"11": {Value: 9},
+			"12": {Value: 8},
+			"20": {Value: entityClassPowerSupply},
+			"21": {Value: entityClassFan},
+			"22": {Value: 8},
+			"30": {Value: entityClassPowerSupply},
+		},
+		OIDentPhysicalName: {
+			"12": {Value: []byte("Te1/1 Temperature")},
+			"20": {Value: []byte("PSU 1")},
+			"21": {Value: []byte("Fan Tray 1")},
+			"22": {Value: []byte("Fan 1 Speed")},
+			"30": {Value: []byte("PSU 2")},
+		},
+		OIDentPhysicalContainedIn: {
+			"10": {Value: 1}, "11": {Value: 10}, "12": {Value: 11},
+			"20": {Value: 1}, "21": {Value: 1}, "22": {Value: 21}, "30": {Value: 1},
+		},
+		OIDcefcFRUPowerOperStatus: {"20": {Value: 2}},
+		OIDcefcFanTrayOperStatus:  {"21": {Value: 2}},
+	}
+
+	plan := planSensors(tables)
+	if len(plan.sensors) != 2 {
+		t.Fatalf("expected 2 sensors, got %d", len(plan.sensors))
+	}
+	optic, fan := plan.sensors[0], plan.sensors[1]
+	if optic.chassis() {
+		t.Error("a sensor inside a port should not be a chassis sensor")
+	}
+	if !fan.chassis() || fan.role != SensorFanSpeed {
+		t.Errorf("expected a chassis fan speed sensor, got %+v", fan)
+	}
+	if v, unit := fan.convert(4200); v != 4200 || unit != "RPM" {
+		t.Errorf("expected 4200 RPM, got %v %s", v, unit)
+	}
+
+	if len(plan.components) != 2 {
+		t.Fatalf("expected the power supply and fan tray with a state, got %+v", plan.components)
+	}
+	psu, tray := plan.components[0], plan.components[1]
+	if psu.name != "PSU 1" || psu.role != SensorPowerSupply || psu.oid != OIDcefcFRUPowerOperStatus+".20" {
+		t.Errorf("unexpected power supply %+v", psu)
+	}
+	if tray.role != SensorFan || tray.oid != OIDcefcFanTrayOperStatus+".21" {
+		t.Errorf("unexpected fan tray %+v", tray)
+	}
+	if status, state := psu.decode(9); status != SensorWarning || state != "on, fan failed" {
+		t.Errorf("expected a warning for onButFanFail, got %s %q", status, state)
+	}
+
+	tables[OIDentStateOper] = map[string]gosnmp.SnmpPDU{"20": {Value: 2}}
+	plan = planSensors(tables)
+	if plan.components[0].oid != OIDentStateOper+".20" {
+		t.Errorf("ENTITY-STATE-MIB should be preferred, got %s", plan.components[0].oid)
+	}
+	if status, state := plan.components[0].decode(2); status != SensorAlarm || state != "disabled" {
+		t.Errorf("expected a disabled power supply in alarm, got %s %q", status, state)
+	}
+}
+
+func TestCommitSensorsEvents(t *testing.T) {
+	p := newTestPoller(t)
+	ts := p.data["10.0.0.1"]
+	now := time.Now()
+
+	p.commitSensorsLocked(ts, []SensorReading{
+		{Name: "PSU 1", Role: SensorPowerSupply, Status: SensorOK, State: "on"},
+	}, now)
+	if p.events.Len() != 0 {
+		t.Fatal("the first reading should not record an event")
+	}
+
+	p.commitSensorsLocked(ts, []SensorReading{
+		{Name: "PSU 1", Role: SensorPowerSupply, Status: SensorAlarm, State: "failed"},
+	}, now)
+	events := p.events.All()
+	if len(events) != 1 {
+		t.Fatalf("expected one event, got %+v", events)
+	}
+	e := events[0]
+	if e.Kind != EventSensorState || e.Target != "sw1" || e.Subject != "PSU 1" ||
+		e.From != SensorOK || e.To != SensorAlarm || e.Detail != "failed" {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if snap := p.Snapshot(); len(snap.Events) != 1 || len(snap.Groups[0].Targets[0].Sensors) != 1 {
+		t.Errorf("expected the event and sensor in the snapshot, got %+v", snap)
+	}
+}
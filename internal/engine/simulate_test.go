@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/tonhe/flo/internal/dashboard"
+	"github.com/tonhe/flo/internal/identity"
+	"github.com/tonhe/flo/internal/simulate"
+)
+
+// newSimulatedTarget starts a simulated device with the given scenario on
+// a free localhost port and returns a poller for a dashboard monitoring
+// the named interfaces on it, already connected.
+func newSimulatedTarget(t *testing.T, scenario simulate.Scenario, ports int, interfaces ...string) (*Poller, *simulate.Device) {
+	t.Helper()
+	if err := scenario.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	device, err := simulate.NewDevice(simulate.Synthetic("sim", ports), scenario, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go simulate.NewAgent(device, "public").Serve(conn)
+
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	dash := &dashboard.Dashboard{
+		Name:       "sim",
+		Interval:   time.Second,
+		MaxHistory: 10,
+		Groups: []dashboard.Group{{
+			Name: "Default",
+			Targets: []dashboard.Target{{
+				Host:       "127.0.0.1",
+				Port:       port,
+				Label:      "sim",
+				Interfaces: interfaces,
+			}},
+		}},
+	}
+	p, err := NewPoller(dash, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.initTargetStats()
+
+	client, err := NewSNMPClient("127.0.0.1", port, &identity.Identity{Version: "2c", Community: "public"}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Retries = 0
+	if err := client.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	p.clients["127.0.0.1"] = client
+	t.Cleanup(p.cleanup)
+	return p, device
+}
+
+func TestPollSimulatedDevice(t *testing.T) {
+	var names []string
+	for i := 1; i <= 8; i++ {
+		names = append(names, fmt.Sprintf("Gi0/%d", i))
+	}
+	names = append(names, "Gi0/99")
+	scenario := simulate.Scenario{
+		Behavior:      simulate.Behavior{Profile: simulate.ProfileFlat, Utilization: 0.5},
+		RebootDownStr: "0s",
+	}
+	p, device := newSimulatedTarget(t, scenario, 10, names...)
+	target := p.dash.Groups[0].Targets[0]
+
+	p.pollTarget(target)
+	time.Sleep(500 * time.Millisecond)
+	p.pollTarget(target)
+
+	ts := p.Snapshot().Groups[0].Targets[0]
+	if ts.Health == TargetUnreachable || ts.PollError != nil {
+		t.Fatalf("expected the target answering, got %v (%v)", ts.Health, ts.PollError)
+	}
+	for _, iface := range ts.Interfaces[:8] {
+		if iface.Status != "up" || iface.CounterBits != 64 || iface.Speed != 1000 {
+			t.Errorf("%s: expected up with 64-bit counters at 1000 Mbps, got %q/%d/%d", iface.Name, iface.Status, iface.CounterBits, iface.Speed)
+		}
+		// Half of 1 Gb/s, give or take noise and timing.
+		if iface.InRate < 0.3e9 || iface.InRate > 0.7e9 {
+			t.Errorf("%s: expected about 500 Mb/s in, got %.0f", iface.Name, iface.InRate)
+		}
+		if iface.InPPS.Unicast == 0 || iface.InPPS.Multicast == 0 || iface.InPPS.Broadcast == 0 {
+			t.Errorf("%s: expected unicast, multicast and broadcast packets, got %+v", iface.Name, iface.InPPS)
+		}
+	}
+	if !ts.Interfaces[8].NotFound {
+		t.Error("an interface the device lacks should be not found")
+	}
+
+	device.Reboot(time.Now())
+	time.Sleep(100 * time.Millisecond)
+	p.pollTarget(target)
+	ts = p.Snapshot().Groups[0].Targets[0]
+	iface := ts.Interfaces[0]
+	if iface.CounterResets != 1 || iface.CounterResetReason != ResetAgentRestart {
+		t.Errorf("expected a reboot to re-baseline the counters, got %d resets (%q)", iface.CounterResets, iface.CounterResetReason)
+	}
+	if iface.InRate > 1e9 {
+		t.Errorf("a reboot should not produce a rate spike, got %.0f", iface.InRate)
+	}
+}
+
+func TestPollSimulatedVersion1(t *testing.T) {
+	p, _ := newSimulatedTarget(t, simulate.Scenario{}, 2, "Gi0/1")
+	p.clients["127.0.0.1"].Version = gosnmp.Version1
+	target := p.dash.Groups[0].Targets[0]
+
+	p.pollTarget(target)
+	p.pollTarget(target)
+	iface := p.Snapshot().Groups[0].Targets[0].Interfaces[0]
+	if iface.PollError != nil || iface.CounterBits != 32 || iface.Status != "up" {
+		t.Errorf("expected SNMPv1 polled with 32-bit counters, got %d bits, status %q, error %v", iface.CounterBits, iface.Status, iface.PollError)
+	}
+}
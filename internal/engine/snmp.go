@@ -17,6 +17,38 @@ const (
 	OIDifHCOutOctets = "1.3.6.1.2.1.31.1.1.1.10"
 	OIDifHighSpeed   = "1.3.6.1.2.1.31.1.1.1.15"
 	OIDifOperStatus  = "1.3.6.1.2.1.2.2.1.8"
+	OIDifLastChange  = "1.3.6.1.2.1.2.2.1.9"
+)
+
+// SNMP OIDs used to detect agent restarts and counter discontinuities.
+const (
+	OIDsysUpTime                  = "1.3.6.1.2.1.1.3.0"
+	OIDifCounterDiscontinuityTime = "1.3.6.1.2.1.31.1.1.1.19"
+)
+
+// SNMP OIDs for interface error, discard and packet counters.
+const (
+	OIDifInDiscards         = "1.3.6.1.2.1.2.2.1.13"
+	OIDifInErrors           = "1.3.6.1.2.1.2.2.1.14"
+	OIDifOutDiscards        = "1.3.6.1.2.1.2.2.1.19"
+	OIDifOutErrors          = "1.3.6.1.2.1.2.2.1.20"
+	OIDifHCInUcastPkts      = "1.3.6.1.2.1.31.1.1.1.7"
+	OIDifHCInMulticastPkts  = "1.3.6.1.2.1.31.1.1.1.8"
+	OIDifHCInBroadcastPkts  = "1.3.6.1.2.1.31.1.1.1.9"
+	OIDifHCOutUcastPkts     = "1.3.6.1.2.1.31.1.1.1.11"
+	OIDifHCOutMulticastPkts = "1.3.6.1.2.1.31.1.1.1.12"
+	OIDifHCOutBroadcastPkts = "1.3.6.1.2.1.31.1.1.1.13"
+)
+
+// 32-bit ifTable counters, used when an agent has no ifXTable (and always
+// for SNMPv1, which cannot carry Counter64 values).
+const (
+	OIDifInOctets      = "1.3.6.1.2.1.2.2.1.10"
+	OIDifInUcastPkts   = "1.3.6.1.2.1.2.2.1.11"
+	OIDifInNUcastPkts  = "1.3.6.1.2.1.2.2.1.12"
+	OIDifOutOctets     = "1.3.6.1.2.1.2.2.1.16"
+	OIDifOutUcastPkts  = "1.3.6.1.2.1.2.2.1.17"
+	OIDifOutNUcastPkts = "1.3.6.1.2.1.2.2.1.18"
 )
 
 // Extended SNMP OIDs for detailed interface discovery.
@@ -43,6 +75,75 @@ const (
 	OIDlldpRemPortDesc = "1.0.8802.1.1.2.1.4.1.1.8"
 )
 
+// Link aggregation membership: IEEE8023-LAG-MIB, and the generic
+// ifStackTable for agents without it.
+const (
+	OIDdot3adAggPortAttachedAggID = "1.2.840.10006.300.43.1.2.1.1.13"
+	OIDifStackStatus              = "1.3.6.1.2.1.31.1.2.1.3"
+)
+
+// HOST-RESOURCES-MIB OIDs for device CPU, memory and storage.
+const (
+	OIDhrProcessorLoad          = "1.3.6.1.2.1.25.3.3.1.2"
+	OIDhrStorageType            = "1.3.6.1.2.1.25.2.3.1.2"
+	OIDhrStorageDescr           = "1.3.6.1.2.1.25.2.3.1.3"
+	OIDhrStorageAllocationUnits = "1.3.6.1.2.1.25.2.3.1.4"
+	OIDhrStorageSize            = "1.3.6.1.2.1.25.2.3.1.5"
+	OIDhrStorageUsed            = "1.3.6.1.2.1.25.2.3.1.6"
+
+	OIDhrStorageRam         = "1.3.6.1.2.1.25.2.1.2"
+	OIDhrStorageFixedDisk   = "1.3.6.1.2.1.25.2.1.4"
+	OIDhrStorageFlashMemory = "1.3.6.1.2.1.25.2.1.9"
+)
+
+// Vendor CPU and memory OIDs, used when a device has no HOST-RESOURCES-MIB.
+const (
+	OIDcpmCPUTotal1minRev  = "1.3.6.1.4.1.9.9.109.1.1.1.1.7"
+	OIDciscoMemoryPoolUsed = "1.3.6.1.4.1.9.9.48.1.1.1.5"
+	OIDciscoMemoryPoolFree = "1.3.6.1.4.1.9.9.48.1.1.1.6"
+	OIDjnxOperatingCPU     = "1.3.6.1.4.1.2636.3.1.13.1.8"
+	OIDjnxOperatingBuffer  = "1.3.6.1.4.1.2636.3.1.13.1.11"
+)
+
+// ENTITY-MIB OIDs used to name physical sensors, components and map them
+// to interfaces.
+const (
+	OIDentPhysicalDescr          = "1.3.6.1.2.1.47.1.1.1.1.2"
+	OIDentPhysicalContainedIn    = "1.3.6.1.2.1.47.1.1.1.1.4"
+	OIDentPhysicalClass          = "1.3.6.1.2.1.47.1.1.1.1.5"
+	OIDentPhysicalName           = "1.3.6.1.2.1.47.1.1.1.1.7"
+	OIDentAliasMappingIdentifier = "1.3.6.1.2.1.47.1.3.2.1.2"
+)
+
+// ENTITY-SENSOR-MIB sensor value table, and its Cisco predecessor
+// CISCO-ENTITY-SENSOR-MIB, which uses the same column layout and also
+// carries the vendor alarm thresholds.
+const (
+	OIDentPhySensorType       = "1.3.6.1.2.1.99.1.1.1.1"
+	OIDentPhySensorScale      = "1.3.6.1.2.1.99.1.1.1.2"
+	OIDentPhySensorPrecision  = "1.3.6.1.2.1.99.1.1.1.3"
+	OIDentPhySensorValue      = "1.3.6.1.2.1.99.1.1.1.4"
+	OIDentPhySensorOperStatus = "1.3.6.1.2.1.99.1.1.1.5"
+
+	OIDentSensorType      = "1.3.6.1.4.1.9.9.91.1.1.1.1.1"
+	OIDentSensorScale     = "1.3.6.1.4.1.9.9.91.1.1.1.1.2"
+	OIDentSensorPrecision = "1.3.6.1.4.1.9.9.91.1.1.1.1.3"
+	OIDentSensorValue     = "1.3.6.1.4.1.9.9.91.1.1.1.1.4"
+	OIDentSensorStatus    = "1.3.6.1.4.1.9.9.91.1.1.1.1.5"
+
+	OIDentSensorThresholdSeverity = "1.3.6.1.4.1.9.9.91.1.2.1.1.2"
+	OIDentSensorThresholdRelation = "1.3.6.1.4.1.9.9.91.1.2.1.1.3"
+	OIDentSensorThresholdValue    = "1.3.6.1.4.1.9.9.91.1.2.1.1.4"
+)
+
+// Power supply and fan status: ENTITY-STATE-MIB, and the Cisco FRU MIB for
+// platforms that do not implement it.
+const (
+	OIDentStateOper           = "1.3.6.1.2.1.131.1.1.1.3"
+	OIDcefcFRUPowerOperStatus = "1.3.6.1.4.1.9.9.117.1.1.2.1.2"
+	OIDcefcFanTrayOperStatus  = "1.3.6.1.4.1.9.9.117.1.4.1.1.1"
+)
+
 // NewSNMPClient creates a gosnmp.GoSNMP client configured from an Identity.
 func NewSNMPClient(host string, port int, id *identity.Identity, timeout time.Duration) (*gosnmp.GoSNMP, error) {
 	if port == 0 {
@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultEventBuffer is how many undelivered events a subscription holds
+// when Subscribe is not given a buffer size.
+const DefaultEventBuffer = 64
+
+// EventFilter selects the engine events a subscription receives. Empty
+// fields match everything.
+type EventFilter struct {
+	Dashboard string            // dashboard name
+	Targets   []string          // target hosts or labels
+	Types     []EngineEventType // event types
+}
+
+// matches reports whether the filter selects e. Events that are not about
+// a target, such as PollCompletedEvent, pass any target filter.
+func (f EventFilter) matches(e EngineEvent) bool {
+	if f.Dashboard != "" && f.Dashboard != e.DashboardName {
+		return false
+	}
+	if len(f.Types) > 0 && !slices.Contains(f.Types, e.Type) {
+		return false
+	}
+	if len(f.Targets) > 0 && e.Host != "" &&
+		!slices.Contains(f.Targets, e.Host) && !slices.Contains(f.Targets, e.Target) {
+		return false
+	}
+	return true
+}
+
+// Subscription receives the engine events selected by its filter on C,
+// until Unsubscribe is called.
+type Subscription struct {
+	C       <-chan EngineEvent
+	ch      chan EngineEvent
+	filter  EventFilter
+	hub     *eventHub
+	dropped atomic.Uint64
+}
+
+// Unsubscribe stops delivery and closes C. No event is sent after it
+// returns. It may be called more than once.
+func (s *Subscription) Unsubscribe() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	if _, ok := s.hub.subs[s]; ok {
+		delete(s.hub.subs, s)
+		close(s.ch)
+	}
+}
+
+// Dropped returns how many events were discarded because C was full.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// eventHub fans engine events out to subscriptions. Engines publish while
+// holding their own lock, so publishing never blocks.
+type eventHub struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+// newEventHub returns a hub with no subscriptions.
+func newEventHub() *eventHub {
+	return &eventHub{subs: make(map[*Subscription]struct{})}
+}
+
+// subscribe adds a subscription with room for buffer undelivered events.
+func (h *eventHub) subscribe(filter EventFilter, buffer int) *Subscription {
+	if buffer <= 0 {
+		buffer = DefaultEventBuffer
+	}
+	ch := make(chan EngineEvent, buffer)
+	s := &Subscription{C: ch, ch: ch, filter: filter, hub: h}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subs[s] = struct{}{}
+	return s
+}
+
+// publish offers an event to every subscription whose filter selects it.
+// A subscription whose buffer is full misses the event, which is counted
+// in its Dropped total.
+func (h *eventHub) publish(e EngineEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for s := range h.subs {
+		if !s.filter.matches(e) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// publishLocked reports an event to the manager's subscribers, if the
+// poller was started by one. Must be called while holding the write lock
+// on p.mu.
+func (p *Poller) publishLocked(e EngineEvent) {
+	if p.hub == nil {
+		return
+	}
+	e.DashboardName = p.dash.Name
+	p.hub.publish(e)
+}
+
+// targetEvent returns an event of the given type about a target.
+func targetEvent(typ EngineEventType, ts *TargetStats, now time.Time) EngineEvent {
+	return EngineEvent{Type: typ, Time: now, Host: ts.Host, Target: ts.Label}
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestEventFilterMatches(t *testing.T) {
+	e := EngineEvent{Type: CounterResetEvent, DashboardName: "core", Host: "10.0.0.1", Target: "sw1"}
+	poll := EngineEvent{Type: PollCompletedEvent, DashboardName: "core"}
+	tests := []struct {
+		name   string
+		filter EventFilter
+		event  EngineEvent
+		want   bool
+	}{
+		{"empty", EventFilter{}, e, true},
+		{"dashboard", EventFilter{Dashboard: "core"}, e, true},
+		{"other dashboard", EventFilter{Dashboard: "edge"}, e, false},
+		{"host", EventFilter{Targets: []string{"10.0.0.1"}}, e, true},
+		{"label", EventFilter{Targets: []string{"sw1"}}, e, true},
+		{"other target", EventFilter{Targets: []string{"sw2"}}, e, false},
+		{"not about a target", EventFilter{Targets: []string{"sw2"}}, poll, true},
+		{"type", EventFilter{Types: []EngineEventType{CounterResetEvent}}, e, true},
+		{"other type", EventFilter{Types: []EngineEventType{PollCompletedEvent}}, e, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.matches(tt.event); got != tt.want {
+			t.Errorf("%s: matches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSubscriptionDelivery(t *testing.T) {
+	hub := newEventHub()
+	sub := hub.subscribe(EventFilter{}, 1)
+	hub.publish(EngineEvent{Type: PollCompletedEvent})
+	hub.publish(EngineEvent{Type: PollCompletedEvent})
+	if sub.Dropped() != 1 {
+		t.Errorf("expected 1 dropped event, got %d", sub.Dropped())
+	}
+	if e := <-sub.C; e.Type != PollCompletedEvent {
+		t.Errorf("unexpected event %+v", e)
+	}
+
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	hub.publish(EngineEvent{Type: PollCompletedEvent})
+	if _, ok := <-sub.C; ok {
+		t.Error("channel should be closed after Unsubscribe")
+	}
+}
+
+func TestPollerPublishes(t *testing.T) {
+	p := newTestPoller(t, "Gi0/1")
+	p.hub = newEventHub()
+	sub := p.hub.subscribe(EventFilter{Types: []EngineEventType{
+		TargetUnreachableEvent, TargetRecoveredEvent, CounterResetEvent,
+	}}, 0)
+	p.applyResolved("10.0.0.1", map[string]DiscoveredInterface{"Gi0/1": {IfIndex: 1, Name: "Gi0/1"}})
+	target := p.dash.Groups[0].Targets[0]
+	ts := p.data["10.0.0.1"]
+	st := p.targetStateLocked("10.0.0.1")
+
+	now := time.Now()
+	for i := 0; i < unreachableAfter+1; i++ {
+		p.recordFailureLocked(ts, st, now)
+	}
+	sample := interfaceSample{status: "up", counters: CounterSample{InOctets: 1000, Bits: counters64, Timestamp: now}}
+	poll := targetPoll{indexes: []int{1}, samples: []interfaceSample{sample}}
+	p.commitTarget(target, poll)
+	poll.restarted = true
+	p.commitTarget(target, poll)
+
+	var got []EngineEventType
+	for len(sub.C) > 0 {
+		e := <-sub.C
+		if e.DashboardName != "test" || e.Host != "10.0.0.1" || e.Target != "sw1" {
+			t.Errorf("event missing its dashboard or target: %+v", e)
+		}
+		got = append(got, e.Type)
+	}
+	want := []EngineEventType{TargetUnreachableEvent, TargetRecoveredEvent, CounterResetEvent}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected events %v, got %v", want, got)
+	}
+}
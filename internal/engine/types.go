@@ -9,29 +9,228 @@ type InterfaceStats struct {
 	Description string
 	Speed       uint64 // Mbps
 	Status      string // "up", "down", "testing"
+	NotFound    bool   // the configured name does not exist on the device
+	CounterBits int    // 64 (ifXTable) or 32 (ifTable); 0 until polled
 	InRate      float64
 	OutRate     float64
 	Utilization float64
-	History     *RingBuffer[RateSample]
+	InPPS       PacketRates
+	OutPPS      PacketRates
+	InAvgSize   float64 // bytes per packet
+	OutAvgSize  float64 // bytes per packet
+	InErrors    float64 // errors/s
+	OutErrors   float64 // errors/s
+	InDiscards  float64 // discards/s
+	OutDiscards float64 // discards/s
+	InErrorPct  float64
+	OutErrorPct float64
+	History     *RingBuffer[RateSample] // raw samples, newest MaxHistory
+	Rollups     *Rollups                // consolidated history; see RateRange
 	PollError   error
 	LastPoll    time.Time
+
+	// Counter resets: times the counters were re-baselined because the
+	// agent restarted, reported a discontinuity or cleared its counters.
+	CounterResets      int
+	LastCounterReset   time.Time
+	CounterResetReason string // see Reset* constants
+
+	// Flap detection, from operational status transitions and ifLastChange.
+	Flaps      int       // status changes within the flap window
+	Flapping   bool      // Flaps has reached the flap threshold
+	LastChange time.Time // last status change; zero if unknown
+
+	// Optics holds the transceiver's digital optical monitoring sensors;
+	// empty for ports without a pluggable optic.
+	Optics []SensorReading
+
+	// Members holds the member ports of a link aggregate, polled alongside
+	// it; empty for other interfaces.
+	Members []InterfaceStats
+}
+
+// SensorReading is the current value of an ENTITY-SENSOR-MIB sensor, such
+// as the receive power of a transceiver.
+type SensorReading struct {
+	Name       string
+	Role       string  // see Sensor* role constants; empty if unrecognized
+	Value      float64 // in Unit
+	Unit       string
+	Status     string // SensorOK, SensorWarning, SensorAlarm or SensorUnavailable
+	State      string // reported state of a power supply or fan, e.g. "failed"
+	Thresholds []SensorThreshold
+}
+
+// SensorThreshold is a vendor alarm threshold on a sensor.
+type SensorThreshold struct {
+	Severity string  // SensorWarning or SensorAlarm
+	High     bool    // crossed above Value; otherwise crossed below it
+	Value    float64 // in the sensor's Unit
+}
+
+// Sensor roles.
+const (
+	SensorRxPower     = "rx power"
+	SensorTxPower     = "tx power"
+	SensorTemperature = "temperature"
+	SensorCurrent     = "current"
+	SensorVoltage     = "voltage"
+	SensorFanSpeed    = "fan speed"
+	SensorPower       = "power"
+	SensorPowerSupply = "power supply"
+	SensorFan         = "fan"
+)
+
+// Sensor states, from the sensor's operational status and thresholds.
+const (
+	SensorOK          = "ok"
+	SensorWarning     = "warning"
+	SensorAlarm       = "alarm"
+	SensorUnavailable = "unavailable"
+)
+
+// MetricStats holds the current value and history of a custom OID. Value is
+// the scaled reading for a gauge, or the scaled per-second rate for a
+// counter.
+type MetricStats struct {
+	Label     string
+	OID       string
+	Kind      string // dashboard.OIDKindGauge or dashboard.OIDKindCounter
+	Unit      string
+	Value     float64
+	History   *RingBuffer[MetricSample]
+	PollError error
+	LastPoll  time.Time
+}
+
+// MetricSample is one point in a custom OID's history.
+type MetricSample struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// DeviceStats holds the CPU, memory and storage utilization of a target, as
+// reported by HOST-RESOURCES-MIB or, failing that, a vendor MIB.
+type DeviceStats struct {
+	HasCPU    bool
+	CPU       float64 // percent, averaged over processors
+	HasMemory bool
+	Memory    float64 // percent used
+	MemUsed   uint64  // bytes; 0 when the device only reports a percentage
+	MemTotal  uint64  // bytes
+	Storage   []StorageStats
+	History   *RingBuffer[DeviceSample]
+	PollError error
+	LastPoll  time.Time
+}
+
+// Available reports whether the device reports any health values.
+func (d DeviceStats) Available() bool {
+	return d.HasCPU || d.HasMemory || len(d.Storage) > 0
+}
+
+// StorageStats holds the usage of one disk or flash file system.
+type StorageStats struct {
+	Descr string
+	Used  uint64 // bytes
+	Size  uint64 // bytes
+}
+
+// Percent returns the used share of the storage area.
+func (s StorageStats) Percent() float64 {
+	if s.Size == 0 {
+		return 0
+	}
+	return float64(s.Used) / float64(s.Size) * 100
+}
+
+// DeviceSample is one point in a device's CPU and memory history.
+type DeviceSample struct {
+	Timestamp time.Time
+	CPU       float64
+	Memory    float64
 }
 
 // TargetStats holds the current state and metrics for a single SNMP target.
 type TargetStats struct {
 	Host       string
 	Label      string
+	Device     DeviceStats
 	Interfaces []InterfaceStats
+	Metrics    []MetricStats   // custom OIDs, in configured order
+	Sensors    []SensorReading // chassis sensors, power supplies and fans
 	PollError  error
 	LastPoll   time.Time
+
+	Health           TargetHealth
+	UnreachableSince time.Time // start of the outage; zero unless unreachable
+	NextAttempt      time.Time // next probe while unreachable
+}
+
+// TargetHealth summarizes whether a target is answering polls.
+type TargetHealth int
+
+const (
+	TargetHealthy     TargetHealth = iota // last poll reached the target
+	TargetDegraded                        // some interfaces failing, or a recent failed poll
+	TargetUnreachable                     // several consecutive polls failed; probed with backoff
+)
+
+// String returns the display name of the health state.
+func (h TargetHealth) String() string {
+	switch h {
+	case TargetDegraded:
+		return "degraded"
+	case TargetUnreachable:
+		return "unreachable"
+	default:
+		return "healthy"
+	}
 }
 
+// Event is a state change observed while polling, such as an interface
+// going down or a power supply failing.
+type Event struct {
+	Time    time.Time
+	Kind    string // see Event* constants
+	Host    string
+	Target  string // target label
+	Subject string // interface or sensor name
+	From    string
+	To      string
+	Detail  string // e.g. the power supply state behind a sensor alarm
+}
+
+// Event kinds.
+const (
+	EventInterfaceStatus = "interface status"
+	EventSensorState     = "sensor state"
+)
+
 // DashboardSnapshot is a point-in-time view of all targets in a dashboard.
 type DashboardSnapshot struct {
-	Name      string
-	Groups    []GroupSnapshot
-	LastPoll  time.Time
-	PollCount int
+	Name       string
+	Columns    []string // optional dashboard columns, see dashboard.Column*
+	Groups     []GroupSnapshot
+	Aggregates []InterfaceStats // computed rows combining other interfaces
+	Events     []Event          // recent state changes, oldest first
+	LastPoll   time.Time
+	PollCount  int
+	// ConfigError says why the dashboard's file failed to reload; the data
+	// is still polled under the last definition that loaded. Empty if none.
+	ConfigError string
+	Recording   *RecordingInfo // recording being written; nil if none
+	Replay      *ReplayInfo    // recording being played back; nil if live
+}
+
+// Now returns the time the snapshot's data is current as of, for showing
+// ages and time ranges: the position in the recording for a replay, or the
+// wall clock.
+func (s *DashboardSnapshot) Now() time.Time {
+	if s != nil && s.Replay != nil {
+		return s.Replay.Position
+	}
+	return time.Now()
 }
 
 // GroupSnapshot is a point-in-time view of a target group.
@@ -44,22 +243,66 @@ type GroupSnapshot struct {
 type EngineState int
 
 const (
-	EngineStopped EngineState = iota
-	EngineRunning
-	EngineError
+	EngineStopped  EngineState = iota
+	EngineRunning              // every target answered its last poll
+	EngineError                // no target answered its last poll
+	EngineStarting             // the first poll cycle has not completed
+	EngineDegraded             // some targets or interfaces are failing
+	EngineStopping             // Stop was called; sessions are closing
 )
 
+// String returns the state's display name.
+func (s EngineState) String() string {
+	switch s {
+	case EngineRunning:
+		return "running"
+	case EngineError:
+		return "error"
+	case EngineStarting:
+		return "starting"
+	case EngineDegraded:
+		return "degraded"
+	case EngineStopping:
+		return "stopping"
+	default:
+		return "stopped"
+	}
+}
+
 // EngineInfo provides summary information about a running engine.
 type EngineInfo struct {
 	Name       string
 	State      EngineState
+	Reason     string // why the engine is degraded or in error
 	LastPoll   time.Time
 	PollCount  int
 	ErrorCount int
 }
 
-// EngineEvent is emitted to subscribers after each poll cycle.
+// EngineEventType identifies what an EngineEvent reports.
+type EngineEventType string
+
+// Engine event types.
+const (
+	PollCompletedEvent     EngineEventType = "poll completed"     // a poll cycle finished
+	InterfaceStatusEvent   EngineEventType = "interface status"   // an interface's status changed
+	TargetUnreachableEvent EngineEventType = "target unreachable" // a target stopped answering
+	TargetRecoveredEvent   EngineEventType = "target recovered"   // an unreachable target answered
+	CounterResetEvent      EngineEventType = "counter reset"      // an interface's counters were re-baselined
+	ResolutionFailedEvent  EngineEventType = "resolution failed"  // interfaces could not be resolved
+)
+
+// EngineEvent is a change reported by an engine to its subscribers. Which
+// fields are set depends on the type.
 type EngineEvent struct {
+	Type          EngineEventType
 	DashboardName string
-	Snapshot      *DashboardSnapshot
+	Time          time.Time
+	Host          string             // target host; empty for PollCompletedEvent
+	Target        string             // target label
+	Interface     string             // interface name, for interface events
+	From          string             // previous status, for InterfaceStatusEvent
+	To            string             // new status, for InterfaceStatusEvent
+	Detail        string             // poll error, reset reason or what failed to resolve
+	Snapshot      *DashboardSnapshot // the dashboard after the cycle, for PollCompletedEvent
 }
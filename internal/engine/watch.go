@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tonhe/flo/internal/dashboard"
+)
+
+// watchInterval is how often the dashboards directory is checked for
+// changed files. Comparing sizes and modification times needs no platform
+// support and costs next to nothing for a directory of dashboards.
+const watchInterval = 2 * time.Second
+
+// fileStamp identifies one version of a file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// dashboardWatcher tracks the dashboard files in a directory.
+type dashboardWatcher struct {
+	dir    string
+	stamps map[string]fileStamp // by path
+	names  map[string]string    // dashboard last loaded from each path
+}
+
+// newDashboardWatcher returns a watcher for dir that treats the files
+// already there as unchanged.
+func newDashboardWatcher(dir string) *dashboardWatcher {
+	w := &dashboardWatcher{
+		dir:    dir,
+		stamps: make(map[string]fileStamp),
+		names:  make(map[string]string),
+	}
+	for _, path := range w.changed() {
+		if dash, err := dashboard.LoadDashboard(path); err == nil {
+			w.names[path] = dash.Name
+		}
+	}
+	return w
+}
+
+// changed returns the dashboard files that were created or modified since
+// the last call. Files that were removed are forgotten.
+func (w *dashboardWatcher) changed() []string {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var paths []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".toml") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(w.dir, e.Name())
+		seen[path] = true
+		stamp := fileStamp{modTime: info.ModTime(), size: info.Size()}
+		if old, ok := w.stamps[path]; ok && old == stamp {
+			continue
+		}
+		w.stamps[path] = stamp
+		paths = append(paths, path)
+	}
+	for path := range w.stamps {
+		if !seen[path] {
+			delete(w.stamps, path)
+			delete(w.names, path)
+		}
+	}
+	return paths
+}
+
+// Watch checks the dashboard files in dir every watchInterval and applies
+// any that change to the running engine of the same dashboard with Reload.
+// A file that fails to load leaves the engine polling under its last good
+// definition, with the error in its snapshots until the file is fixed.
+// Dashboards that are not running, or whose file is deleted, are left
+// alone. Watching stops with StopAll; Watch does nothing while already
+// watching.
+func (m *Manager) Watch(dir string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.watchStop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	m.watchStop = stop
+
+	go func() {
+		w := newDashboardWatcher(dir)
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.reloadChanged(w)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// reloadChanged reloads the running dashboards whose files have changed.
+func (m *Manager) reloadChanged(w *dashboardWatcher) {
+	for _, path := range w.changed() {
+		dash, err := dashboard.LoadDashboard(path)
+		if err != nil {
+			if p := m.engine(w.names[path]); p != nil {
+				p.SetConfigError(fmt.Errorf("%s: %w", filepath.Base(path), err))
+			}
+			continue
+		}
+		w.names[path] = dash.Name
+		if p := m.engine(dash.Name); p != nil {
+			if err := p.Reload(dash); err != nil {
+				p.SetConfigError(err)
+			}
+		}
+	}
+}
+
+// engine returns the running engine of the named dashboard, or nil.
+func (m *Manager) engine(name string) *Poller {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.engines[name]
+}
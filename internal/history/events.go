@@ -0,0 +1,83 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// eventExt is the extension of an event log's day files.
+const eventExt = ".jsonl"
+
+// Event is a state change observed while polling, such as an interface
+// going down. It mirrors the engine's event so the two can be converted
+// field for field.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Kind    string    `json:"kind"`
+	Host    string    `json:"host"`
+	Target  string    `json:"target,omitempty"`
+	Subject string    `json:"subject,omitempty"`
+	From    string    `json:"from,omitempty"`
+	To      string    `json:"to,omitempty"`
+	Detail  string    `json:"detail,omitempty"`
+}
+
+// eventsDir returns the directory holding a dashboard's event log.
+func (s *Store) eventsDir(dash string) string {
+	return filepath.Join(s.dir, url.QueryEscape(dash), eventsDir)
+}
+
+// AppendEvent writes an event to the dashboard's event log, one JSON object
+// per line.
+func (s *Store) AppendEvent(dash string, event Event) error {
+	s.maybePrune(event.Time)
+	s.dirs.RLock()
+	defer s.dirs.RUnlock()
+	dir := s.eventsDir(dash)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return appendFile(filepath.Join(dir, event.Time.UTC().Format(dayLayout)+eventExt), append(line, '\n'))
+}
+
+// Events returns the dashboard's events with from <= Time < to, oldest
+// first. Lines that cannot be decoded, such as one cut short by a crash,
+// are skipped.
+func (s *Store) Events(dash string, from, to time.Time) ([]Event, error) {
+	dir := s.eventsDir(dash)
+	var events []Event
+	for day := from.UTC().Truncate(24 * time.Hour); day.Before(to); day = day.Add(24 * time.Hour) {
+		data, err := os.ReadFile(filepath.Join(dir, day.Format(dayLayout)+eventExt))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			var e Event
+			if json.Unmarshal(scanner.Bytes(), &e) != nil {
+				continue
+			}
+			if e.Time.Before(from) || !e.Time.Before(to) {
+				continue
+			}
+			events = append(events, e)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events, nil
+}
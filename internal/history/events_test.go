@@ -0,0 +1,46 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoreEvents(t *testing.T) {
+	store := NewStore(t.TempDir())
+	now := time.Date(2026, 3, 14, 0, 0, 30, 0, time.UTC)
+	events := []Event{
+		{Time: now.Add(-time.Minute), Kind: "interface status", Host: "10.0.0.1", Subject: "Gi0/1", From: "up", To: "down"},
+		{Time: now, Kind: "interface status", Host: "10.0.0.1", Subject: "Gi0/1", From: "down", To: "up", Detail: "bounced between polls"},
+	}
+	for _, e := range events {
+		if err := store.AppendEvent("lab", e); err != nil {
+			t.Fatalf("AppendEvent() error: %v", err)
+		}
+	}
+
+	// A line cut short by a crash is skipped.
+	path := filepath.Join(store.eventsDir("lab"), "2026-03-14.jsonl")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"time":"2026-03-14T00:01:00Z","ki`)
+	f.Close()
+
+	got, err := store.Events("lab", now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Events() error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events across the day boundary, got %d", len(got))
+	}
+	if !got[1].Time.Equal(now) || got[1].Detail != "bounced between polls" || got[0].To != "down" {
+		t.Errorf("unexpected events %+v", got)
+	}
+
+	if series, _ := store.Series("lab"); len(series) != 0 {
+		t.Errorf("the event log should not be listed as a series, got %v", series)
+	}
+}
@@ -0,0 +1,124 @@
+package history
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// pruneInterval is how often appends trigger a background prune.
+const pruneInterval = time.Hour
+
+// SetRetention limits how much history is kept: day files whose day ended
+// more than maxAge ago are removed, and then the oldest days until the store
+// takes no more than maxBytes. The current day is never removed. A zero
+// limit is not enforced.
+func (s *Store) SetRetention(maxAge time.Duration, maxBytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxAge, s.maxBytes = maxAge, maxBytes
+}
+
+// maybePrune starts a background prune if retention limits are set and none
+// has run for pruneInterval.
+func (s *Store) maybePrune(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.maxAge <= 0 && s.maxBytes <= 0 {
+		return
+	}
+	if !s.lastPrune.IsZero() && now.Sub(s.lastPrune) < pruneInterval {
+		return
+	}
+	s.lastPrune = now
+	go s.Prune(now)
+}
+
+// dayFile is a rate or event file of one UTC day.
+type dayFile struct {
+	path string
+	day  time.Time
+	size int64
+}
+
+// Prune removes the day files that fall outside the retention limits, and
+// any directories left empty.
+func (s *Store) Prune(now time.Time) error {
+	s.mu.Lock()
+	maxAge, maxBytes := s.maxAge, s.maxBytes
+	s.mu.Unlock()
+
+	files, err := s.dayFiles()
+	if err != nil {
+		return err
+	}
+	today := now.UTC().Truncate(24 * time.Hour)
+	sort.SliceStable(files, func(i, j int) bool { return files[i].day.Before(files[j].day) })
+
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	var firstErr error
+	for _, f := range files {
+		expired := maxAge > 0 && !f.day.Add(24*time.Hour).After(now.Add(-maxAge))
+		oversize := maxBytes > 0 && total > maxBytes
+		if f.day.Equal(today) || !expired && !oversize {
+			continue
+		}
+		if err := os.Remove(f.path); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		total -= f.size
+		s.dirs.Lock()
+		s.removeEmptyParents(filepath.Dir(f.path))
+		s.dirs.Unlock()
+	}
+	return firstErr
+}
+
+// dayFiles returns every rate and event file in the store.
+func (s *Store) dayFiles() ([]dayFile, error) {
+	var files []dayFile
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == s.dir && os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		name := d.Name()
+		ext := filepath.Ext(name)
+		if d.IsDir() || ext != rateExt && ext != eventExt {
+			return nil
+		}
+		day, err := time.Parse(dayLayout, strings.TrimSuffix(name, ext))
+		if err != nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		files = append(files, dayFile{path: path, day: day, size: info.Size()})
+		return nil
+	})
+	return files, err
+}
+
+// removeEmptyParents removes dir and its parents up to the store root for as
+// long as they are empty.
+func (s *Store) removeEmptyParents(dir string) {
+	for dir != s.dir && strings.HasPrefix(dir, s.dir) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
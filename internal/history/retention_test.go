@@ -0,0 +1,62 @@
+package history
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestStorePrune(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	old := Series{Host: "10.0.0.1", Interface: "Gi0/1"}
+	cur := Series{Host: "10.0.0.1", Interface: "Gi0/2"}
+
+	for day := range 10 {
+		at := now.AddDate(0, 0, -day)
+		if err := store.Append("lab", cur, Sample{Time: at, Interval: time.Second}); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.AppendEvent("lab", Event{Time: at, Kind: "interface status"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Append("lab", old, Sample{Time: now.AddDate(0, 0, -30), Interval: time.Second}); err != nil {
+		t.Fatal(err)
+	}
+
+	store.SetRetention(7*24*time.Hour, 0)
+	if err := store.Prune(now); err != nil {
+		t.Fatalf("Prune() error: %v", err)
+	}
+	samples, _ := store.Read("lab", cur, now.AddDate(0, 0, -30), now.Add(time.Hour))
+	if len(samples) != 8 {
+		t.Errorf("expected the days overlapping the last 7 days to be kept, got %d samples", len(samples))
+	}
+	events, _ := store.Events("lab", now.AddDate(0, 0, -30), now.Add(time.Hour))
+	if len(events) != 8 {
+		t.Errorf("expected 8 days of events to be kept, got %d", len(events))
+	}
+	if _, err := os.Stat(store.seriesDir("lab", old)); !os.IsNotExist(err) {
+		t.Errorf("expected the emptied series directory to be removed, got %v", err)
+	}
+
+	// A size limit removes the oldest days first but keeps today.
+	store.SetRetention(0, 1)
+	if err := store.Prune(now); err != nil {
+		t.Fatalf("Prune() error: %v", err)
+	}
+	samples, _ = store.Read("lab", cur, now.AddDate(0, 0, -30), now.Add(time.Hour))
+	if len(samples) != 1 || !samples[0].Time.Equal(now) {
+		t.Errorf("expected only today to be kept, got %d samples", len(samples))
+	}
+}
+
+func TestStorePruneMissing(t *testing.T) {
+	store := NewStore(t.TempDir() + "/none")
+	store.SetRetention(time.Hour, 1)
+	if err := store.Prune(time.Now()); err != nil {
+		t.Errorf("Prune() on a missing dir: %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+// Package history persists interface rate samples and state changes to disk,
+// so that graphs survive a restart and usage can be reported over periods
+// far longer than the in-memory history.
+package history
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// dayLayout names the file holding one UTC day of a series or event log.
+const dayLayout = "2006-01-02"
+
+// Subdirectories of a dashboard's directory.
+const (
+	ratesDir  = "rates"
+	eventsDir = "events"
+)
+
+// rateExt is the extension of a series' day files.
+const rateExt = ".dat"
+
+// Sample is the average traffic of an interface over the poll interval
+// ending at Time.
+type Sample struct {
+	Time        time.Time
+	Interval    time.Duration
+	InRate      float64 // bits/s
+	OutRate     float64 // bits/s
+	InPkts      Packets
+	OutPkts     Packets
+	InErrors    float64 // errors/s
+	OutErrors   float64 // errors/s
+	InDiscards  float64 // discards/s
+	OutDiscards float64 // discards/s
+}
+
+// Packets holds per-second packet rates by cast type.
+type Packets struct {
+	Unicast   float64
+	Multicast float64
+	Broadcast float64
+}
+
+// sampleFields selects each rate of a Sample, in record order.
+var sampleFields = []func(*Sample) *float64{
+	func(s *Sample) *float64 { return &s.InRate },
+	func(s *Sample) *float64 { return &s.OutRate },
+	func(s *Sample) *float64 { return &s.InPkts.Unicast },
+	func(s *Sample) *float64 { return &s.InPkts.Multicast },
+	func(s *Sample) *float64 { return &s.InPkts.Broadcast },
+	func(s *Sample) *float64 { return &s.OutPkts.Unicast },
+	func(s *Sample) *float64 { return &s.OutPkts.Multicast },
+	func(s *Sample) *float64 { return &s.OutPkts.Broadcast },
+	func(s *Sample) *float64 { return &s.InErrors },
+	func(s *Sample) *float64 { return &s.OutErrors },
+	func(s *Sample) *float64 { return &s.InDiscards },
+	func(s *Sample) *float64 { return &s.OutDiscards },
+}
+
+// recordSize is the size of one encoded sample: the sample time in Unix
+// nanoseconds and the interval in nanoseconds, followed by each rate in
+// sampleFields order.
+var recordSize = 16 + 8*len(sampleFields)
+
+// Series identifies the samples of one interface of a dashboard target.
+type Series struct {
+	Host      string
+	Interface string
+}
+
+// Store is an append-only on-disk store of interface rate samples and state
+// changes, laid out as one file per UTC day:
+//
+//	<dir>/<dashboard>/rates/<host>/<interface>/2006-01-02.dat
+//	<dir>/<dashboard>/events/2006-01-02.jsonl
+//
+// Path components are query-escaped, so interface names like "Gi0/1" are
+// safe. Rate files are sequences of fixed-size little-endian records; a
+// record cut short by a crash is ignored when reading and overwritten by the
+// next append. Each series and event log must have a single writer, which
+// the poller of a dashboard is. Files past the retention limits are removed
+// in the background as new days begin.
+type Store struct {
+	dir string
+
+	// dirs is held for reading while appending and for writing while
+	// removing empty directories, so a prune cannot remove a directory an
+	// append has just created.
+	dirs sync.RWMutex
+
+	mu        sync.Mutex
+	maxAge    time.Duration
+	maxBytes  int64
+	lastPrune time.Time
+}
+
+// NewStore returns a Store rooted at dir. Directories are created as samples
+// are written.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// seriesDir returns the directory holding a series' day files.
+func (s *Store) seriesDir(dash string, series Series) string {
+	return filepath.Join(s.dir, url.QueryEscape(dash), ratesDir, url.QueryEscape(series.Host), url.QueryEscape(series.Interface))
+}
+
+// Append writes a sample to the series.
+func (s *Store) Append(dash string, series Series, sample Sample) error {
+	s.maybePrune(sample.Time)
+	s.dirs.RLock()
+	defer s.dirs.RUnlock()
+	dir := s.seriesDir(dash, series)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	rec := make([]byte, recordSize)
+	binary.LittleEndian.PutUint64(rec[0:], uint64(sample.Time.UnixNano()))
+	binary.LittleEndian.PutUint64(rec[8:], uint64(sample.Interval))
+	for i, field := range sampleFields {
+		binary.LittleEndian.PutUint64(rec[16+8*i:], math.Float64bits(*field(&sample)))
+	}
+	return appendRecord(filepath.Join(dir, sample.Time.UTC().Format(dayLayout)+rateExt), rec)
+}
+
+// appendRecord appends a fixed-size record to a rate file, creating it if
+// needed. A partial record left at the end by a crash or a full disk is
+// overwritten, so later records stay on record boundaries.
+func appendRecord(path string, rec []byte) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err == nil {
+		_, err = f.WriteAt(rec, info.Size()-info.Size()%int64(len(rec)))
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// appendFile appends data to a file, creating it if needed.
+func appendFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Read returns the series' samples with from <= Time < to, oldest first.
+func (s *Store) Read(dash string, series Series, from, to time.Time) ([]Sample, error) {
+	dir := s.seriesDir(dash, series)
+	var samples []Sample
+	for day := from.UTC().Truncate(24 * time.Hour); day.Before(to); day = day.Add(24 * time.Hour) {
+		data, err := os.ReadFile(filepath.Join(dir, day.Format(dayLayout)+rateExt))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for off := 0; off+recordSize <= len(data); off += recordSize {
+			rec := data[off : off+recordSize]
+			t := time.Unix(0, int64(binary.LittleEndian.Uint64(rec[0:])))
+			if t.Before(from) || !t.Before(to) {
+				continue
+			}
+			sample := Sample{Time: t, Interval: time.Duration(binary.LittleEndian.Uint64(rec[8:]))}
+			for i, field := range sampleFields {
+				*field(&sample) = math.Float64frombits(binary.LittleEndian.Uint64(rec[16+8*i:]))
+			}
+			samples = append(samples, sample)
+		}
+	}
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].Time.Before(samples[j].Time) })
+	return samples, nil
+}
+
+// Dashboards returns the names of the dashboards with stored history.
+func (s *Store) Dashboards() ([]string, error) {
+	return s.list(s.dir)
+}
+
+// Series returns the series stored for a dashboard, sorted by host and
+// interface.
+func (s *Store) Series(dash string) ([]Series, error) {
+	rates := filepath.Join(s.dir, url.QueryEscape(dash), ratesDir)
+	hosts, err := s.list(rates)
+	if err != nil {
+		return nil, err
+	}
+	var series []Series
+	for _, host := range hosts {
+		ifaces, err := s.list(filepath.Join(rates, url.QueryEscape(host)))
+		if err != nil {
+			return nil, err
+		}
+		for _, iface := range ifaces {
+			series = append(series, Series{Host: host, Interface: iface})
+		}
+	}
+	return series, nil
+}
+
+// list returns the unescaped names of the subdirectories of dir, sorted. A
+// missing directory has none.
+func (s *Store) list(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if name, err := url.QueryUnescape(e.Name()); err == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,107 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoreAppendRead(t *testing.T) {
+	store := NewStore(t.TempDir())
+	series := Series{Host: "10.0.0.1", Interface: "Gi0/1"}
+	day := time.Date(2026, 3, 14, 23, 59, 50, 0, time.UTC)
+
+	for i := range 4 {
+		sample := Sample{
+			Time:        day.Add(time.Duration(i) * 10 * time.Second),
+			Interval:    10 * time.Second,
+			InRate:      float64(i) * 1000,
+			OutRate:     float64(i) * 2000,
+			InPkts:      Packets{Unicast: 10, Multicast: 2, Broadcast: 1},
+			OutDiscards: 0.5,
+		}
+		if err := store.Append("Core / Edge", series, sample); err != nil {
+			t.Fatalf("Append() error: %v", err)
+		}
+	}
+
+	samples, err := store.Read("Core / Edge", series, day, day.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if len(samples) != 4 {
+		t.Fatalf("expected 4 samples across the day boundary, got %d", len(samples))
+	}
+	if samples[3].OutRate != 6000 || samples[3].Interval != 10*time.Second ||
+		samples[3].InPkts.Multicast != 2 || samples[3].OutDiscards != 0.5 {
+		t.Errorf("unexpected sample %+v", samples[3])
+	}
+	if !samples[0].Time.Equal(day) {
+		t.Errorf("expected first sample at %v, got %v", day, samples[0].Time)
+	}
+
+	samples, _ = store.Read("Core / Edge", series, day.Add(10*time.Second), day.Add(30*time.Second))
+	if len(samples) != 2 {
+		t.Errorf("expected the range to be from-inclusive and to-exclusive, got %d samples", len(samples))
+	}
+
+	dashes, err := store.Dashboards()
+	if err != nil || len(dashes) != 1 || dashes[0] != "Core / Edge" {
+		t.Errorf("Dashboards() = %v, %v", dashes, err)
+	}
+	list, err := store.Series("Core / Edge")
+	if err != nil || len(list) != 1 || list[0] != series {
+		t.Errorf("Series() = %v, %v", list, err)
+	}
+}
+
+func TestStoreTruncatedRecord(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+	series := Series{Host: "r1", Interface: "eth0"}
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	if err := store.Append("lab", series, Sample{Time: now, Interval: time.Second, InRate: 1}); err != nil {
+		t.Fatalf("Append() error: %v", err)
+	}
+
+	path := filepath.Join(store.seriesDir("lab", series), "2026-03-14.dat")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(make([]byte, recordSize/2))
+	f.Close()
+
+	samples, err := store.Read("lab", series, now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if len(samples) != 1 {
+		t.Errorf("expected the partial record to be ignored, got %d samples", len(samples))
+	}
+
+	// Appends after the partial record stay on record boundaries.
+	later := now.Add(time.Second)
+	if err := store.Append("lab", series, Sample{Time: later, Interval: time.Second, InRate: 2}); err != nil {
+		t.Fatalf("Append() error: %v", err)
+	}
+	samples, err = store.Read("lab", series, now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	if len(samples) != 2 || !samples[1].Time.Equal(later) || samples[1].InRate != 2 || samples[1].Interval != time.Second {
+		t.Errorf("expected the sample appended after the partial record, got %+v", samples)
+	}
+}
+
+func TestStoreMissing(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "none"))
+	if dashes, err := store.Dashboards(); err != nil || len(dashes) != 0 {
+		t.Errorf("Dashboards() on a missing dir = %v, %v", dashes, err)
+	}
+	samples, err := store.Read("x", Series{Host: "h", Interface: "i"}, time.Now().Add(-time.Hour), time.Now())
+	if err != nil || len(samples) != 0 {
+		t.Errorf("Read() on a missing series = %v, %v", samples, err)
+	}
+}
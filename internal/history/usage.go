@@ -0,0 +1,137 @@
+package history
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// BucketWidth is the averaging period of the rates that percentiles and
+// peaks are taken over, as used by transit providers for 95th percentile
+// billing.
+const BucketWidth = 5 * time.Minute
+
+// Usage summarizes an interface's traffic over a period. Percentiles and
+// peaks are taken over BucketWidth averages; averages are over the time
+// covered by samples, so periods flo was not running are left out rather
+// than counted as idle.
+type Usage struct {
+	Period   Period
+	Samples  int
+	Covered  time.Duration // total interval covered by samples
+	In95     float64       // bits/s
+	Out95    float64       // bits/s
+	InAvg    float64       // bits/s
+	OutAvg   float64       // bits/s
+	InPeak   float64       // bits/s
+	OutPeak  float64       // bits/s
+	InBytes  float64       // volume received
+	OutBytes float64       // volume sent
+}
+
+// Billable returns the 95th percentile rate a provider bills: the greater of
+// the two directions.
+func (u Usage) Billable() float64 {
+	return max(u.In95, u.Out95)
+}
+
+// Period is a named time range, From inclusive and To exclusive.
+type Period struct {
+	Name string
+	From time.Time
+	To   time.Time
+}
+
+// RollingDays is the length of the rolling billing period.
+const RollingDays = 30
+
+// BillingPeriods returns the rolling period of the last RollingDays days and
+// the current calendar month, both ending at now.
+func BillingPeriods(now time.Time) []Period {
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return []Period{
+		{Name: fmt.Sprintf("Last %d days", RollingDays), From: now.AddDate(0, 0, -RollingDays), To: now},
+		{Name: now.Format("January"), From: month, To: now},
+	}
+}
+
+// Summarize computes the usage of the samples falling within a period.
+func Summarize(period Period, samples []Sample) Usage {
+	u := Usage{Period: period}
+	type bucket struct{ in, out, secs float64 }
+	buckets := make(map[int64]*bucket)
+	for _, s := range samples {
+		secs := s.Interval.Seconds()
+		if secs <= 0 || s.Time.Before(period.From) || !s.Time.Before(period.To) {
+			continue
+		}
+		u.Samples++
+		u.Covered += s.Interval
+		u.InBytes += s.InRate / 8 * secs
+		u.OutBytes += s.OutRate / 8 * secs
+
+		key := s.Time.Truncate(BucketWidth).Unix()
+		b := buckets[key]
+		if b == nil {
+			b = &bucket{}
+			buckets[key] = b
+		}
+		b.in += s.InRate * secs
+		b.out += s.OutRate * secs
+		b.secs += secs
+	}
+	if u.Samples == 0 {
+		return u
+	}
+	u.InAvg = u.InBytes * 8 / u.Covered.Seconds()
+	u.OutAvg = u.OutBytes * 8 / u.Covered.Seconds()
+
+	ins := make([]float64, 0, len(buckets))
+	outs := make([]float64, 0, len(buckets))
+	for _, b := range buckets {
+		ins = append(ins, b.in/b.secs)
+		outs = append(outs, b.out/b.secs)
+	}
+	sort.Float64s(ins)
+	sort.Float64s(outs)
+	u.In95, u.InPeak = percentile(ins, 95), ins[len(ins)-1]
+	u.Out95, u.OutPeak = percentile(outs, 95), outs[len(outs)-1]
+	return u
+}
+
+// percentile returns the nearest-rank pth percentile of sorted values: the
+// smallest value that at least p percent of the values do not exceed.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
+
+// Usage reads a series once over the union of the periods and summarizes
+// each of them.
+func (s *Store) Usage(dash string, series Series, periods []Period) ([]Usage, error) {
+	if len(periods) == 0 {
+		return nil, nil
+	}
+	from, to := periods[0].From, periods[0].To
+	for _, p := range periods[1:] {
+		if p.From.Before(from) {
+			from = p.From
+		}
+		if p.To.After(to) {
+			to = p.To
+		}
+	}
+	samples, err := s.Read(dash, series, from, to)
+	if err != nil {
+		return nil, err
+	}
+	usage := make([]Usage, len(periods))
+	for i, p := range periods {
+		usage[i] = Summarize(p, samples)
+	}
+	return usage, nil
+}
@@ -0,0 +1,78 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	values := make([]float64, 100)
+	for i := range values {
+		values[i] = float64(i + 1)
+	}
+	if p := percentile(values, 95); p != 95 {
+		t.Errorf("95th of 1..100 = %v, want 95", p)
+	}
+	if p := percentile(values[:10], 95); p != 10 {
+		t.Errorf("95th of 1..10 = %v, want 10", p)
+	}
+	if p := percentile(nil, 95); p != 0 {
+		t.Errorf("95th of nothing = %v, want 0", p)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	period := Period{Name: "March", From: start, To: start.Add(24 * time.Hour)}
+
+	// 100 five-minute buckets of one-minute samples at 1..100 Mbps in, with
+	// a constant 10 Mbps out.
+	var samples []Sample
+	for b := range 100 {
+		for m := range 5 {
+			samples = append(samples, Sample{
+				Time:     start.Add(time.Duration(b)*BucketWidth + time.Duration(m)*time.Minute),
+				Interval: time.Minute,
+				InRate:   float64(b+1) * 1e6,
+				OutRate:  10e6,
+			})
+		}
+	}
+	samples = append(samples, Sample{Time: start.Add(-time.Minute), Interval: time.Minute, InRate: 1e12})
+
+	u := Summarize(period, samples)
+	if u.Samples != 500 || u.Covered != 500*time.Minute {
+		t.Fatalf("expected 500 samples covering 500m, got %d over %v", u.Samples, u.Covered)
+	}
+	if u.In95 != 95e6 || u.InPeak != 100e6 {
+		t.Errorf("in 95th/peak = %v/%v, want 95e6/100e6", u.In95, u.InPeak)
+	}
+	if u.Out95 != 10e6 || u.Billable() != 95e6 {
+		t.Errorf("out 95th = %v, billable = %v", u.Out95, u.Billable())
+	}
+	if u.InAvg != 50.5e6 {
+		t.Errorf("in avg = %v, want 50.5e6", u.InAvg)
+	}
+	// 10 Mbps for 500 minutes is 37.5 GB.
+	if u.OutBytes != 10e6/8*500*60 {
+		t.Errorf("out bytes = %v", u.OutBytes)
+	}
+
+	if empty := Summarize(period, nil); empty.Samples != 0 || empty.In95 != 0 {
+		t.Errorf("expected an empty summary, got %+v", empty)
+	}
+}
+
+func TestBillingPeriods(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	periods := BillingPeriods(now)
+	if len(periods) != 2 {
+		t.Fatalf("expected 2 periods, got %d", len(periods))
+	}
+	if !periods[0].From.Equal(now.AddDate(0, 0, -RollingDays)) || !periods[0].To.Equal(now) {
+		t.Errorf("unexpected rolling period %+v", periods[0])
+	}
+	if periods[1].Name != "March" || !periods[1].From.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected month period %+v", periods[1])
+	}
+}
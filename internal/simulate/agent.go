@@ -0,0 +1,214 @@
+package simulate
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// DefaultAddr is where flo simulate listens unless told otherwise. Unlike
+// the standard port 161, port 1161 can be bound without root privileges.
+const DefaultAddr = "127.0.0.1:1161"
+
+// maxBulkVarbinds caps the varbinds in one GETBULK response, keeping it
+// within a UDP datagram.
+const maxBulkVarbinds = 1000
+
+// Agent answers SNMPv1 and SNMPv2c GET, GETNEXT and GETBULK requests for a
+// Device. It is read-only: SET requests are refused. SNMPv3 requests and
+// requests with the wrong community are ignored, as a real agent would,
+// and so is everything while the device is rebooting.
+type Agent struct {
+	device    *Device
+	community string
+}
+
+// NewAgent returns an agent serving device to clients that use community.
+func NewAgent(device *Device, community string) *Agent {
+	return &Agent{device: device, community: community}
+}
+
+// Serve answers requests arriving on conn until conn is closed.
+func (a *Agent) Serve(conn net.PacketConn) error {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		if resp := a.handle(buf[:n], time.Now()); resp != nil {
+			conn.WriteTo(resp, addr)
+		}
+	}
+}
+
+// handle returns the response to a request received at now, or nil if it
+// goes unanswered.
+func (a *Agent) handle(packet []byte, now time.Time) (resp []byte) {
+	// gosnmp's decoder is written for responses from agents and can panic
+	// on malformed or unexpected requests.
+	defer func() {
+		if recover() != nil {
+			resp = nil
+		}
+	}()
+
+	if !a.device.answering(now) {
+		return nil
+	}
+	req, err := (&gosnmp.GoSNMP{}).SnmpDecodePacket(packet)
+	if err != nil || req.Version == gosnmp.Version3 || req.Community != a.community {
+		return nil
+	}
+
+	out := &gosnmp.SnmpPacket{
+		Version:   req.Version,
+		Community: req.Community,
+		PDUType:   gosnmp.GetResponse,
+		RequestID: req.RequestID,
+	}
+	switch req.PDUType {
+	case gosnmp.GetRequest:
+		a.get(req, out, now)
+	case gosnmp.GetNextRequest:
+		a.getNext(req, out, now)
+	case gosnmp.GetBulkRequest:
+		if req.Version == gosnmp.Version1 {
+			return nil
+		}
+		a.getBulk(req, out, now)
+	case gosnmp.SetRequest:
+		status := gosnmp.NotWritable
+		if req.Version == gosnmp.Version1 {
+			status = gosnmp.NoSuchName
+		}
+		fail(req, out, status, 0)
+	default:
+		return nil
+	}
+
+	b, err := out.MarshalMsg()
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// get answers a GET request.
+func (a *Agent) get(req, out *gosnmp.SnmpPacket, now time.Time) {
+	for i, v := range req.Variables {
+		o, err := parseOID(v.Name)
+		if err != nil {
+			fail(req, out, gosnmp.GenErr, i)
+			return
+		}
+		pdu := a.device.get(o, now)
+		if req.Version == gosnmp.Version1 && !v1Value(pdu) {
+			fail(req, out, gosnmp.NoSuchName, i)
+			return
+		}
+		out.Variables = append(out.Variables, pdu)
+	}
+}
+
+// getNext answers a GETNEXT request.
+func (a *Agent) getNext(req, out *gosnmp.SnmpPacket, now time.Time) {
+	for i, v := range req.Variables {
+		o, err := parseOID(v.Name)
+		if err != nil {
+			fail(req, out, gosnmp.GenErr, i)
+			return
+		}
+		pdu, ok := a.next(o, req.Version, now)
+		if !ok {
+			if req.Version == gosnmp.Version1 {
+				fail(req, out, gosnmp.NoSuchName, i)
+				return
+			}
+			pdu = gosnmp.SnmpPDU{Name: o.String(), Type: gosnmp.EndOfMibView}
+		}
+		out.Variables = append(out.Variables, pdu)
+	}
+}
+
+// getBulk answers a GETBULK request: one GETNEXT for each non-repeater,
+// then up to MaxRepetitions for the remaining variables, stopping early
+// once all of them have reached the end of the MIB.
+func (a *Agent) getBulk(req, out *gosnmp.SnmpPacket, now time.Time) {
+	nonRepeaters := min(int(req.NonRepeaters), len(req.Variables))
+	next := make([]oid, len(req.Variables))
+	for i, v := range req.Variables {
+		o, err := parseOID(v.Name)
+		if err != nil {
+			fail(req, out, gosnmp.GenErr, i)
+			return
+		}
+		next[i] = o
+	}
+
+	step := func(o oid) (gosnmp.SnmpPDU, bool) {
+		pdu, ok := a.next(o, req.Version, now)
+		if !ok {
+			return gosnmp.SnmpPDU{Name: o.String(), Type: gosnmp.EndOfMibView}, false
+		}
+		return pdu, true
+	}
+	for _, o := range next[:nonRepeaters] {
+		pdu, _ := step(o)
+		out.Variables = append(out.Variables, pdu)
+	}
+
+	repeating := next[nonRepeaters:]
+	for r := 0; r < int(req.MaxRepetitions) && len(repeating) > 0; r++ {
+		if len(out.Variables)+len(repeating) > maxBulkVarbinds {
+			break
+		}
+		ended := 0
+		for i, o := range repeating {
+			pdu, ok := step(o)
+			out.Variables = append(out.Variables, pdu)
+			if !ok {
+				ended++
+				continue
+			}
+			repeating[i], _ = parseOID(pdu.Name)
+		}
+		if ended == len(repeating) {
+			break
+		}
+	}
+}
+
+// next returns the first object after o that can be sent with the given
+// SNMP version: SNMPv1 has no Counter64, so a v1 walk skips them.
+func (a *Agent) next(o oid, version gosnmp.SnmpVersion, now time.Time) (gosnmp.SnmpPDU, bool) {
+	for {
+		pdu, ok := a.device.next(o, now)
+		if !ok || version != gosnmp.Version1 || v1Value(pdu) {
+			return pdu, ok
+		}
+		o, _ = parseOID(pdu.Name)
+	}
+}
+
+// v1Value reports whether pdu can be sent in an SNMPv1 response.
+func v1Value(pdu gosnmp.SnmpPDU) bool {
+	switch pdu.Type {
+	case gosnmp.Counter64, gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView:
+		return false
+	}
+	return true
+}
+
+// fail turns out into an error response for the variable at index i, which
+// echoes the request's variables as the protocol requires.
+func fail(req, out *gosnmp.SnmpPacket, status gosnmp.SNMPError, i int) {
+	out.Error = status
+	out.ErrorIndex = uint8(i + 1)
+	out.Variables = req.Variables
+}
@@ -0,0 +1,129 @@
+package simulate
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// serve starts an agent for d on a free localhost port and returns a
+// connected client using the given version and community.
+func serve(t *testing.T, d *Device, version gosnmp.SnmpVersion, community string) *gosnmp.GoSNMP {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go NewAgent(d, "public").Serve(conn)
+
+	client := &gosnmp.GoSNMP{
+		Target:         "127.0.0.1",
+		Port:           uint16(conn.LocalAddr().(*net.UDPAddr).Port),
+		Version:        version,
+		Community:      community,
+		Timeout:        500 * time.Millisecond,
+		MaxRepetitions: 10,
+	}
+	if err := client.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Conn.Close() })
+	return client
+}
+
+func TestAgentGet(t *testing.T) {
+	d := newTestDevice(t, 3, Scenario{}, time.Now())
+	client := serve(t, d, gosnmp.Version2c, "public")
+
+	result, err := client.Get([]string{oidSysName, columnOID(oidIfXEntry, colIfHCInOctets, 2), columnOID(oidIfEntry, colIfDescr, 9)})
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	vars := result.Variables
+	if len(vars) != 3 {
+		t.Fatalf("expected 3 variables, got %+v", vars)
+	}
+	if vars[0].Type != gosnmp.OctetString || string(vars[0].Value.([]byte)) != "sim" {
+		t.Errorf("unexpected sysName %+v", vars[0])
+	}
+	if vars[1].Type != gosnmp.Counter64 {
+		t.Errorf("expected a Counter64, got %+v", vars[1])
+	}
+	if vars[2].Type != gosnmp.NoSuchInstance {
+		t.Errorf("expected no such instance, got %+v", vars[2])
+	}
+}
+
+func TestAgentWalk(t *testing.T) {
+	d := newTestDevice(t, 12, Scenario{}, time.Now())
+	client := serve(t, d, gosnmp.Version2c, "public")
+
+	var names []string
+	err := client.BulkWalk(oidIfXEntry+".1", func(pdu gosnmp.SnmpPDU) error {
+		names = append(names, string(pdu.Value.([]byte)))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("BulkWalk() error: %v", err)
+	}
+	if len(names) != 12 || names[0] != "Gi0/1" || names[11] != "Gi0/12" {
+		t.Errorf("expected ifName of 12 interfaces in ifIndex order, got %v", names)
+	}
+
+	// The whole MIB walks to its end.
+	count := 0
+	if err := client.BulkWalk(".1.3", func(gosnmp.SnmpPDU) error { count++; return nil }); err != nil {
+		t.Fatalf("BulkWalk() error: %v", err)
+	}
+	if count != len(d.mib.oids) {
+		t.Errorf("expected %d objects walking the MIB, got %d", len(d.mib.oids), count)
+	}
+}
+
+func TestAgentVersion1(t *testing.T) {
+	d := newTestDevice(t, 2, Scenario{}, time.Now())
+	client := serve(t, d, gosnmp.Version1, "public")
+
+	var walked []string
+	err := client.Walk(oidIfXEntry, func(pdu gosnmp.SnmpPDU) error {
+		if pdu.Type == gosnmp.Counter64 {
+			t.Errorf("SNMPv1 cannot carry %s, a Counter64", pdu.Name)
+		}
+		walked = append(walked, pdu.Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error: %v", err)
+	}
+	if len(walked) == 0 || !strings.HasPrefix(walked[0], "."+oidIfXEntry+".1.") {
+		t.Errorf("expected the ifXTable walked without its 64-bit counters, got %v", walked)
+	}
+
+	result, err := client.Get([]string{oidSysName, columnOID(oidIfEntry, colIfDescr, 9)})
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	if result.Error != gosnmp.NoSuchName || result.ErrorIndex != 2 {
+		t.Errorf("expected noSuchName on the second variable, got %v at %d", result.Error, result.ErrorIndex)
+	}
+}
+
+func TestAgentIgnores(t *testing.T) {
+	d := newTestDevice(t, 1, Scenario{}, time.Now())
+	client := serve(t, d, gosnmp.Version2c, "private")
+	client.Retries = 0
+	if _, err := client.Get([]string{oidSysName}); err == nil {
+		t.Error("a request with the wrong community should go unanswered")
+	}
+
+	client = serve(t, d, gosnmp.Version2c, "public")
+	client.Retries = 0
+	d.Reboot(time.Now())
+	if _, err := client.Get([]string{oidSysName}); err == nil {
+		t.Error("a rebooting device should not answer")
+	}
+}
@@ -0,0 +1,485 @@
+package simulate
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// maxStep is the longest stretch of time integrated at a single traffic
+// level when counters catch up, so a profile's shape survives long gaps
+// between requests.
+const maxStep = time.Minute
+
+// defaultSpeed is the link speed, in bits per second, of an interface
+// whose speed the dump does not give.
+const defaultSpeed = 1e9
+
+// dynamicIfColumns are the ifTable columns computed from the scenario
+// rather than served from the dump.
+var dynamicIfColumns = []int{
+	colIfOperStatus, colIfLastChange,
+	colIfInOctets, colIfInUcast, colIfInNUcast, colIfInDiscards, colIfInErrors,
+	colIfOutOctets, colIfOutUcast, colIfOutNUcast, colIfOutDiscards, colIfOutErrors,
+}
+
+// dynamicIfXColumns are the computed ifXTable columns, served only by
+// devices that have an ifXTable.
+var dynamicIfXColumns = []int{
+	colIfInMulticast, colIfInBroadcast, colIfOutMulticast, colIfOutBroadcast,
+	colIfHCInOctets, colIfHCInUcast, colIfHCInMulticast, colIfHCInBroadcast,
+	colIfHCOutOctets, colIfHCOutUcast, colIfHCOutMulticast, colIfHCOutBroadcast,
+}
+
+// Device is a simulated SNMP device: the objects of a walk dump, with the
+// interface counters, link states and sysUpTime brought to life by a
+// Scenario. It is safe for concurrent use.
+//
+// Time only moves forward: each read first advances the counters to the
+// time it is given, and a read with an earlier time sees the later state.
+type Device struct {
+	mu       sync.Mutex
+	scenario Scenario
+	mib      mib
+	static   map[string]gosnmp.SnmpPDU // objects served as dumped, by OID key
+	cells    map[string]cell           // objects computed from the scenario
+	ifaces   []*simInterface
+
+	bootAt      time.Time // when sysUpTime was last zero
+	silentUntil time.Time // the agent does not answer before this
+	nextReboot  time.Time // zero if the scenario has no reboots
+	updated     time.Time // counters are current up to this time
+}
+
+// simInterface is the simulated state of one interface.
+type simInterface struct {
+	index      int
+	name       string // ifName, or ifDescr when the device has no ifXTable
+	descr      string
+	speed      float64 // bits per second
+	behavior   Behavior
+	offset     time.Duration // staggers this interface's flaps
+	downStatus int           // ifOperStatus of a link that was down in the dump, 0 if it was up
+	in, out    counters
+}
+
+// cell is a computed object: one column of an interface.
+type cell struct {
+	iface *simInterface
+	entry string // oidIfEntry or oidIfXEntry
+	col   int
+}
+
+// NewDevice builds a device from dumped objects, such as those returned by
+// LoadWalk or Synthetic, that behaves as scenario describes from now on.
+// scenario must have been normalized. The dump's sysUpTime and counters
+// are where the device starts; interfaces that were down in the dump stay
+// down.
+func NewDevice(pdus []gosnmp.SnmpPDU, scenario Scenario, now time.Time) (*Device, error) {
+	d := &Device{
+		scenario: scenario,
+		static:   make(map[string]gosnmp.SnmpPDU, len(pdus)),
+		cells:    make(map[string]cell),
+		bootAt:   now,
+		updated:  now,
+	}
+	hasIfX := false
+	indexes := make(map[int]bool)
+	for _, pdu := range pdus {
+		o, err := parseOID(pdu.Name)
+		if err != nil {
+			return nil, err
+		}
+		key := o.key()
+		if _, dup := d.static[key]; dup {
+			continue
+		}
+		d.static[key] = pdu
+		d.mib.add(o)
+
+		if strings.HasPrefix(key, oidIfXTable+".") {
+			hasIfX = true
+		}
+		for _, col := range []string{
+			fmt.Sprintf("%s.%d.", oidIfEntry, colIfIndex),
+			fmt.Sprintf("%s.%d.", oidIfEntry, colIfDescr),
+			fmt.Sprintf("%s.%d.", oidIfXEntry, colIfName),
+		} {
+			if rest, ok := strings.CutPrefix(key, col); ok && !strings.Contains(rest, ".") {
+				var index int
+				if _, err := fmt.Sscan(rest, &index); err == nil {
+					indexes[index] = true
+				}
+			}
+		}
+	}
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("no interfaces found (the dump needs ifDescr or ifName)")
+	}
+
+	if v, ok := d.number(oidSysUpTime); ok {
+		d.bootAt = now.Add(-ticksDuration(uint32(v)))
+	}
+	d.mib.add(mustOID(oidSysUpTime))
+	if _, ok := d.static[oidIfNumber]; !ok {
+		d.setStatic(oidIfNumber, gosnmp.Integer, len(indexes))
+	}
+
+	sorted := slices.Sorted(maps.Keys(indexes))
+	for i, index := range sorted {
+		iface := d.newInterface(index, hasIfX)
+		if iface.behavior.FlapEvery > 0 {
+			iface.offset = iface.behavior.FlapEvery * time.Duration(i) / time.Duration(len(sorted))
+		}
+		d.ifaces = append(d.ifaces, iface)
+
+		for _, col := range dynamicIfColumns {
+			d.addCell(cell{iface: iface, entry: oidIfEntry, col: col})
+		}
+		if hasIfX {
+			for _, col := range dynamicIfXColumns {
+				d.addCell(cell{iface: iface, entry: oidIfXEntry, col: col})
+			}
+		}
+	}
+
+	d.mib.sort()
+
+	if scenario.RebootEvery > 0 {
+		d.nextReboot = now.Add(scenario.RebootEvery)
+	}
+	return d, nil
+}
+
+// newInterface reads an interface's name, speed, link state and starting
+// counters from the dump.
+func (d *Device) newInterface(index int, hasIfX bool) *simInterface {
+	iface := &simInterface{index: index, speed: defaultSpeed}
+	iface.descr = d.text(columnOID(oidIfEntry, colIfDescr, index))
+	iface.name = d.text(columnOID(oidIfXEntry, colIfName, index))
+	if iface.name == "" {
+		iface.name = iface.descr
+	}
+	if iface.name == "" {
+		iface.name = fmt.Sprintf("ifIndex %d", index)
+	}
+	iface.behavior = d.scenario.behavior(iface.name, iface.descr)
+
+	if mbps, ok := d.number(columnOID(oidIfXEntry, colIfHighSpeed, index)); ok && mbps > 0 {
+		iface.speed = float64(mbps) * 1e6
+	} else if bps, ok := d.number(columnOID(oidIfEntry, colIfSpeed, index)); ok {
+		iface.speed = float64(bps)
+	}
+	if status, ok := d.number(columnOID(oidIfEntry, colIfOperStatus, index)); ok && status != 1 {
+		iface.downStatus = int(status)
+	}
+
+	// Start from the dumped counters, preferring the 64-bit ones.
+	start := func(entry string, col int) float64 {
+		v, _ := d.number(columnOID(entry, col, index))
+		return float64(v)
+	}
+	iface.in = counters{
+		octets:   start(oidIfEntry, colIfInOctets),
+		ucast:    start(oidIfEntry, colIfInUcast),
+		mcast:    start(oidIfEntry, colIfInNUcast),
+		discards: start(oidIfEntry, colIfInDiscards),
+		errors:   start(oidIfEntry, colIfInErrors),
+	}
+	iface.out = counters{
+		octets:   start(oidIfEntry, colIfOutOctets),
+		ucast:    start(oidIfEntry, colIfOutUcast),
+		mcast:    start(oidIfEntry, colIfOutNUcast),
+		discards: start(oidIfEntry, colIfOutDiscards),
+		errors:   start(oidIfEntry, colIfOutErrors),
+	}
+	if hasIfX {
+		if _, ok := d.static[columnOID(oidIfXEntry, colIfHCInOctets, index)]; ok {
+			iface.in.octets = start(oidIfXEntry, colIfHCInOctets)
+			iface.in.ucast = start(oidIfXEntry, colIfHCInUcast)
+			iface.in.mcast = start(oidIfXEntry, colIfHCInMulticast)
+			iface.in.bcast = start(oidIfXEntry, colIfHCInBroadcast)
+			iface.out.octets = start(oidIfXEntry, colIfHCOutOctets)
+			iface.out.ucast = start(oidIfXEntry, colIfHCOutUcast)
+			iface.out.mcast = start(oidIfXEntry, colIfHCOutMulticast)
+			iface.out.bcast = start(oidIfXEntry, colIfHCOutBroadcast)
+		}
+	}
+	return iface
+}
+
+// addCell registers a computed object.
+func (d *Device) addCell(c cell) {
+	o := mustOID(columnOID(c.entry, c.col, c.iface.index))
+	d.cells[o.key()] = c
+	d.mib.add(o)
+}
+
+// setStatic adds an object served as is.
+func (d *Device) setStatic(key string, typ gosnmp.Asn1BER, value any) {
+	o := mustOID(key)
+	d.static[key] = gosnmp.SnmpPDU{Name: o.String(), Type: typ, Value: value}
+	d.mib.add(o)
+}
+
+// text returns a dumped string object, or "" if there is none.
+func (d *Device) text(key string) string {
+	pdu, ok := d.static[key]
+	if !ok {
+		return ""
+	}
+	switch v := pdu.Value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	}
+	return ""
+}
+
+// number returns a dumped numeric object.
+func (d *Device) number(key string) (uint64, bool) {
+	pdu, ok := d.static[key]
+	if !ok {
+		return 0, false
+	}
+	switch v := pdu.Value.(type) {
+	case int:
+		return uint64(max(v, 0)), true
+	case uint32:
+		return uint64(v), true
+	case uint64:
+		return v, true
+	}
+	return 0, false
+}
+
+// InterfaceNames returns the names of the device's interfaces in ifIndex
+// order.
+func (d *Device) InterfaceNames() []string {
+	names := make([]string, len(d.ifaces))
+	for i, iface := range d.ifaces {
+		names[i] = iface.name
+	}
+	return names
+}
+
+// Reboot restarts the device at now, as a scheduled reboot would: the agent
+// is silent for the scenario's RebootDown, then comes back with sysUpTime
+// and every counter restarted from zero.
+func (d *Device) Reboot(now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.advanceLocked(now)
+	d.rebootLocked(now)
+}
+
+// answering reports whether the agent responds at now, that is, whether
+// the device is not in the middle of a reboot.
+func (d *Device) answering(now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.advanceLocked(now)
+	return !now.Before(d.silentUntil)
+}
+
+// get returns the object o at now. A missing object is returned with type
+// NoSuchInstance if o is in a table or subtree the device has, or
+// NoSuchObject if not.
+func (d *Device) get(o oid, now time.Time) gosnmp.SnmpPDU {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.advanceLocked(now)
+	if d.mib.contains(o) {
+		return d.valueLocked(o, now)
+	}
+	typ := gosnmp.NoSuchObject
+	if len(o) > 1 {
+		parent := o[:len(o)-1]
+		if next, ok := d.mib.next(parent); ok && len(next) > len(parent) && slices.Equal(next[:len(parent)], parent) {
+			typ = gosnmp.NoSuchInstance
+		}
+	}
+	return gosnmp.SnmpPDU{Name: o.String(), Type: typ}
+}
+
+// next returns the first object after o at now, as GETNEXT does. ok is
+// false at the end of the MIB.
+func (d *Device) next(o oid, now time.Time) (gosnmp.SnmpPDU, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.advanceLocked(now)
+	n, ok := d.mib.next(o)
+	if !ok {
+		return gosnmp.SnmpPDU{}, false
+	}
+	return d.valueLocked(n, now), true
+}
+
+// valueLocked returns the current value of an object in the MIB.
+// Must be called while holding d.mu, after advancing to now.
+func (d *Device) valueLocked(o oid, now time.Time) gosnmp.SnmpPDU {
+	key := o.key()
+	pdu := gosnmp.SnmpPDU{Name: o.String()}
+	if key == oidSysUpTime {
+		pdu.Type, pdu.Value = gosnmp.TimeTicks, durationTicks(now.Sub(d.bootAt))
+		return pdu
+	}
+	c, ok := d.cells[key]
+	if !ok {
+		pdu.Type, pdu.Value = d.static[key].Type, d.static[key].Value
+		return pdu
+	}
+
+	iface := c.iface
+	counter := func(v float64, wide bool) {
+		if wide {
+			pdu.Type, pdu.Value = gosnmp.Counter64, uint64(v)
+		} else {
+			pdu.Type, pdu.Value = gosnmp.Counter32, uint32(uint64(v))
+		}
+	}
+	if c.entry == oidIfXEntry {
+		switch c.col {
+		case colIfInMulticast:
+			counter(iface.in.mcast, false)
+		case colIfInBroadcast:
+			counter(iface.in.bcast, false)
+		case colIfOutMulticast:
+			counter(iface.out.mcast, false)
+		case colIfOutBroadcast:
+			counter(iface.out.bcast, false)
+		case colIfHCInOctets:
+			counter(iface.in.octets, true)
+		case colIfHCInUcast:
+			counter(iface.in.ucast, true)
+		case colIfHCInMulticast:
+			counter(iface.in.mcast, true)
+		case colIfHCInBroadcast:
+			counter(iface.in.bcast, true)
+		case colIfHCOutOctets:
+			counter(iface.out.octets, true)
+		case colIfHCOutUcast:
+			counter(iface.out.ucast, true)
+		case colIfHCOutMulticast:
+			counter(iface.out.mcast, true)
+		case colIfHCOutBroadcast:
+			counter(iface.out.bcast, true)
+		}
+		return pdu
+	}
+
+	switch c.col {
+	case colIfOperStatus:
+		status := iface.downStatus
+		if status == 0 {
+			status = 1
+			if down, _ := d.linkDownLocked(iface, now); down {
+				status = 2
+			}
+		}
+		pdu.Type, pdu.Value = gosnmp.Integer, status
+	case colIfLastChange:
+		var ticks uint32
+		if _, changed := d.linkDownLocked(iface, now); iface.downStatus == 0 && changed.After(d.bootAt) {
+			ticks = durationTicks(changed.Sub(d.bootAt))
+		}
+		pdu.Type, pdu.Value = gosnmp.TimeTicks, ticks
+	case colIfInOctets:
+		counter(iface.in.octets, false)
+	case colIfInUcast:
+		counter(iface.in.ucast, false)
+	case colIfInNUcast:
+		counter(iface.in.mcast+iface.in.bcast, false)
+	case colIfInDiscards:
+		counter(iface.in.discards, false)
+	case colIfInErrors:
+		counter(iface.in.errors, false)
+	case colIfOutOctets:
+		counter(iface.out.octets, false)
+	case colIfOutUcast:
+		counter(iface.out.ucast, false)
+	case colIfOutNUcast:
+		counter(iface.out.mcast+iface.out.bcast, false)
+	case colIfOutDiscards:
+		counter(iface.out.discards, false)
+	case colIfOutErrors:
+		counter(iface.out.errors, false)
+	}
+	return pdu
+}
+
+// linkDownLocked reports whether an interface's link is down at t because
+// of a flap, and when it last changed. Must be called while holding d.mu.
+func (d *Device) linkDownLocked(iface *simInterface, t time.Time) (bool, time.Time) {
+	return iface.behavior.flapState(t, d.bootAt, iface.offset)
+}
+
+// advanceLocked brings the device up to now, carrying out any reboots that
+// were due. Must be called while holding d.mu.
+func (d *Device) advanceLocked(now time.Time) {
+	for !d.nextReboot.IsZero() && !now.Before(d.nextReboot) {
+		at := d.nextReboot
+		d.integrateLocked(at)
+		d.rebootLocked(at)
+		d.nextReboot = at.Add(d.scenario.RebootEvery)
+	}
+	d.integrateLocked(now)
+}
+
+// rebootLocked restarts the device at the given time. Must be called while
+// holding d.mu.
+func (d *Device) rebootLocked(at time.Time) {
+	d.silentUntil = at.Add(d.scenario.RebootDown)
+	d.bootAt = d.silentUntil
+	d.updated = d.bootAt
+	for _, iface := range d.ifaces {
+		iface.in, iface.out = counters{}, counters{}
+	}
+}
+
+// integrateLocked adds the traffic each interface carried up to the given
+// time to its counters. Must be called while holding d.mu.
+func (d *Device) integrateLocked(to time.Time) {
+	for d.updated.Before(to) {
+		step := min(to.Sub(d.updated), maxStep)
+		mid := d.updated.Add(step / 2)
+		for _, iface := range d.ifaces {
+			if iface.downStatus != 0 {
+				continue
+			}
+			if down, _ := d.linkDownLocked(iface, mid); down {
+				continue
+			}
+			in := iface.behavior.utilization(mid, d.scenario.Seed, iface.index, dirIn)
+			out := iface.behavior.utilization(mid, d.scenario.Seed, iface.index, dirOut)
+			iface.in.add(in*iface.speed*step.Seconds()/8, in)
+			iface.out.add(out*iface.speed*step.Seconds()/8, out)
+		}
+		d.updated = d.updated.Add(step)
+	}
+}
+
+// durationTicks converts a duration to TimeTicks, hundredths of a second.
+func durationTicks(d time.Duration) uint32 {
+	return uint32(max(d, 0) / (10 * time.Millisecond))
+}
+
+// ticksDuration converts TimeTicks to a duration.
+func ticksDuration(ticks uint32) time.Duration {
+	return time.Duration(ticks) * 10 * time.Millisecond
+}
+
+// mustOID parses one of the package's OID constants.
+func mustOID(s string) oid {
+	o, err := parseOID(s)
+	if err != nil {
+		panic(err)
+	}
+	return o
+}
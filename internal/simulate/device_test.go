@@ -0,0 +1,177 @@
+package simulate
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// newTestDevice returns a synthetic device with the given number of
+// interfaces, normalizing scenario first.
+func newTestDevice(t *testing.T, interfaces int, scenario Scenario, now time.Time) *Device {
+	t.Helper()
+	if err := scenario.Normalize(); err != nil {
+		t.Fatalf("Normalize() error: %v", err)
+	}
+	d, err := NewDevice(Synthetic("sim", interfaces), scenario, now)
+	if err != nil {
+		t.Fatalf("NewDevice() error: %v", err)
+	}
+	return d
+}
+
+// value reads one object from the device.
+func value(t *testing.T, d *Device, name string, now time.Time) gosnmp.SnmpPDU {
+	t.Helper()
+	return d.get(mustOID(name), now)
+}
+
+// number reads a numeric object from the device.
+func number(t *testing.T, d *Device, name string, now time.Time) uint64 {
+	t.Helper()
+	return gosnmp.ToBigInt(value(t, d, name, now).Value).Uint64()
+}
+
+func TestDeviceTraffic(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	d := newTestDevice(t, 2, Scenario{Behavior: Behavior{Profile: ProfileFlat, Utilization: 0.5}}, start)
+	if got := d.InterfaceNames(); len(got) != 2 || got[0] != "Gi0/1" || got[1] != "Gi0/2" {
+		t.Fatalf("unexpected interfaces %v", got)
+	}
+
+	hcIn := columnOID(oidIfXEntry, colIfHCInOctets, 1)
+	if got := number(t, d, hcIn, start); got != 0 {
+		t.Fatalf("counters should start from the dump, got %d", got)
+	}
+	later := start.Add(10 * time.Second)
+	in := number(t, d, hcIn, later)
+	// Half of 1 Gb/s for 10s is 625 MB, give or take the noise.
+	if in < 560e6 || in > 690e6 {
+		t.Errorf("expected about 625e6 octets in, got %d", in)
+	}
+	out := number(t, d, columnOID(oidIfXEntry, colIfHCOutOctets, 1), later)
+	if out >= in || out == 0 {
+		t.Errorf("expected less traffic out than in, got %d out, %d in", out, in)
+	}
+	if got := number(t, d, columnOID(oidIfEntry, colIfInOctets, 1), later); got != uint64(uint32(in)) {
+		t.Errorf("ifInOctets should be the 64-bit count wrapped, got %d", got)
+	}
+	if got := number(t, d, oidSysUpTime, later); got != 1000 {
+		t.Errorf("expected sysUpTime 1000 ticks, got %d", got)
+	}
+	if again := number(t, d, hcIn, start); again != in {
+		t.Errorf("reading an earlier time should not move the counters back, got %d", again)
+	}
+
+	idle := newTestDevice(t, 1, Scenario{Behavior: Behavior{Profile: ProfileIdle}}, start)
+	if got := number(t, idle, hcIn, later); got != 0 {
+		t.Errorf("an idle interface should carry no traffic, got %d", got)
+	}
+}
+
+func TestDeviceFlaps(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	scenario := Scenario{Behavior: Behavior{Profile: ProfileFlat, FlapEveryStr: "1m", FlapDownStr: "10s"}}
+	d := newTestDevice(t, 2, scenario, start)
+	status := columnOID(oidIfEntry, colIfOperStatus, 1)
+	lastChange := columnOID(oidIfEntry, colIfLastChange, 1)
+
+	if got := number(t, d, status, start.Add(49*time.Second)); got != 1 {
+		t.Errorf("expected Gi0/1 up before its flap, got status %d", got)
+	}
+	if got := number(t, d, status, start.Add(55*time.Second)); got != 2 {
+		t.Errorf("expected Gi0/1 down during its flap, got status %d", got)
+	}
+	if got := number(t, d, lastChange, start.Add(55*time.Second)); got != 5000 {
+		t.Errorf("expected ifLastChange at 50s, got %d", got)
+	}
+	before := number(t, d, columnOID(oidIfXEntry, colIfHCInOctets, 1), start.Add(50*time.Second))
+	after := number(t, d, columnOID(oidIfXEntry, colIfHCInOctets, 1), start.Add(60*time.Second))
+	if after != before {
+		t.Errorf("a down link should carry no traffic, counters went from %d to %d", before, after)
+	}
+	if got := number(t, d, status, start.Add(65*time.Second)); got != 1 {
+		t.Errorf("expected Gi0/1 back up, got status %d", got)
+	}
+	// Gi0/2's cycle is offset by half a period.
+	if got := number(t, d, columnOID(oidIfEntry, colIfOperStatus, 2), start.Add(25*time.Second)); got != 2 {
+		t.Errorf("expected Gi0/2 to flap at a different time, got status %d", got)
+	}
+}
+
+func TestDeviceReboot(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	d := newTestDevice(t, 1, Scenario{RebootEveryStr: "1h", RebootDownStr: "30s"}, start)
+	hcIn := columnOID(oidIfXEntry, colIfHCInOctets, 1)
+
+	before := start.Add(time.Hour - time.Second)
+	if !d.answering(before) || number(t, d, hcIn, before) == 0 {
+		t.Fatal("expected the device answering with traffic before its reboot")
+	}
+	if d.answering(start.Add(time.Hour + 10*time.Second)) {
+		t.Error("the device should not answer while rebooting")
+	}
+	back := start.Add(time.Hour + 40*time.Second)
+	if !d.answering(back) {
+		t.Fatal("the device should answer once rebooted")
+	}
+	if got := number(t, d, oidSysUpTime, back); got != 1000 {
+		t.Errorf("expected sysUpTime restarted 10s ago, got %d ticks", got)
+	}
+	if got := number(t, d, hcIn, back); got == 0 || got > 10*1e9/8 {
+		t.Errorf("expected counters restarted from zero, got %d", got)
+	}
+
+	d.Reboot(back)
+	if d.answering(back.Add(time.Second)) {
+		t.Error("a manual reboot should silence the device")
+	}
+}
+
+func TestNewDeviceFromWalk(t *testing.T) {
+	dump := `.1.3.6.1.2.1.1.3.0 = Timeticks: (360000) 1:00:00.00
+.1.3.6.1.2.1.2.2.1.2.1 = STRING: "Ethernet1"
+.1.3.6.1.2.1.2.2.1.2.2 = STRING: "Ethernet2"
+.1.3.6.1.2.1.2.2.1.5.1 = Gauge32: 100000000
+.1.3.6.1.2.1.2.2.1.5.2 = Gauge32: 100000000
+.1.3.6.1.2.1.2.2.1.8.1 = INTEGER: up(1)
+.1.3.6.1.2.1.2.2.1.8.2 = INTEGER: lowerLayerDown(7)
+.1.3.6.1.2.1.2.2.1.10.1 = Counter32: 1000
+`
+	pdus, err := ParseWalk(strings.NewReader(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scenario := Scenario{Behavior: Behavior{Profile: ProfileFlat}}
+	if err := scenario.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	d, err := NewDevice(pdus, scenario, start)
+	if err != nil {
+		t.Fatalf("NewDevice() error: %v", err)
+	}
+
+	if got := number(t, d, oidSysUpTime, start); got != 360000 {
+		t.Errorf("sysUpTime should continue from the dump, got %d", got)
+	}
+	if got := number(t, d, columnOID(oidIfEntry, colIfInOctets, 1), start); got != 1000 {
+		t.Errorf("counters should continue from the dump, got %d", got)
+	}
+	if got := number(t, d, columnOID(oidIfEntry, colIfOperStatus, 2), start.Add(time.Minute)); got != 7 {
+		t.Errorf("a link down in the dump should stay down, got status %d", got)
+	}
+	// A device without an ifXTable does not grow one.
+	if got := value(t, d, columnOID(oidIfXEntry, colIfHCInOctets, 1), start); got.Type != gosnmp.NoSuchObject {
+		t.Errorf("expected no 64-bit counters, got %v", got.Type)
+	}
+	if got := value(t, d, columnOID(oidIfEntry, colIfInOctets, 9), start); got.Type != gosnmp.NoSuchInstance {
+		t.Errorf("expected no such instance for an unknown ifIndex, got %v", got.Type)
+	}
+
+	if _, err := NewDevice(pdus[:1], scenario, start); err == nil {
+		t.Error("expected an error for a dump without interfaces")
+	}
+}
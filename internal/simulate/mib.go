@@ -0,0 +1,152 @@
+// Package simulate runs a local SNMP agent that imitates a network device,
+// for demos, training and reproducing poller bugs without real equipment.
+// The device is loaded from an snmpwalk dump or generated, and its
+// interface counters, link states and uptime change over time according to
+// a Scenario.
+package simulate
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MIB-II and IF-MIB objects the simulator keeps up to date. The engine has
+// its own copies; these are repeated here so that engine tests can run
+// against the simulator without an import cycle.
+const (
+	oidSysDescr    = "1.3.6.1.2.1.1.1.0"
+	oidSysObjectID = "1.3.6.1.2.1.1.2.0"
+	oidSysUpTime   = "1.3.6.1.2.1.1.3.0"
+	oidSysName     = "1.3.6.1.2.1.1.5.0"
+	oidIfNumber    = "1.3.6.1.2.1.2.1.0"
+	oidIfEntry     = "1.3.6.1.2.1.2.2.1"
+	oidIfXTable    = "1.3.6.1.2.1.31.1.1"
+	oidIfXEntry    = "1.3.6.1.2.1.31.1.1.1"
+)
+
+// ifTable columns, under oidIfEntry.
+const (
+	colIfIndex       = 1
+	colIfDescr       = 2
+	colIfType        = 3
+	colIfMtu         = 4
+	colIfSpeed       = 5
+	colIfPhysAddress = 6
+	colIfAdminStatus = 7
+	colIfOperStatus  = 8
+	colIfLastChange  = 9
+	colIfInOctets    = 10
+	colIfInUcast     = 11
+	colIfInNUcast    = 12
+	colIfInDiscards  = 13
+	colIfInErrors    = 14
+	colIfOutOctets   = 16
+	colIfOutUcast    = 17
+	colIfOutNUcast   = 18
+	colIfOutDiscards = 19
+	colIfOutErrors   = 20
+)
+
+// ifXTable columns, under oidIfXEntry.
+const (
+	colIfName                 = 1
+	colIfInMulticast          = 2
+	colIfInBroadcast          = 3
+	colIfOutMulticast         = 4
+	colIfOutBroadcast         = 5
+	colIfHCInOctets           = 6
+	colIfHCInUcast            = 7
+	colIfHCInMulticast        = 8
+	colIfHCInBroadcast        = 9
+	colIfHCOutOctets          = 10
+	colIfHCOutUcast           = 11
+	colIfHCOutMulticast       = 12
+	colIfHCOutBroadcast       = 13
+	colIfHighSpeed            = 15
+	colIfAlias                = 18
+	colIfCounterDiscontinuity = 19
+)
+
+// oid is a parsed object identifier, compared component by component.
+type oid []uint32
+
+// parseOID parses a dotted object identifier, with or without a leading dot.
+func parseOID(s string) (oid, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), ".")
+	if s == "" {
+		return nil, fmt.Errorf("empty OID")
+	}
+	parts := strings.Split(s, ".")
+	o := make(oid, len(parts))
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OID %q", s)
+		}
+		o[i] = uint32(n)
+	}
+	return o, nil
+}
+
+// String returns the OID in the dotted form gosnmp uses, with a leading dot.
+func (o oid) String() string {
+	var b strings.Builder
+	for _, n := range o {
+		b.WriteByte('.')
+		b.WriteString(strconv.FormatUint(uint64(n), 10))
+	}
+	return b.String()
+}
+
+// key returns the OID without a leading dot, as used to index the MIB.
+func (o oid) key() string {
+	return o.String()[1:]
+}
+
+// columnOID returns the OID of a table cell.
+func columnOID(entry string, column, index int) string {
+	return fmt.Sprintf("%s.%d.%d", entry, column, index)
+}
+
+// mib is the sorted set of OIDs a device answers for. OIDs are added in
+// any order, then sorted once before the set is used.
+type mib struct {
+	oids []oid
+}
+
+// add adds an OID to the set.
+func (m *mib) add(o oid) {
+	m.oids = append(m.oids, o)
+}
+
+// sort puts the OIDs in walk order and removes duplicates.
+func (m *mib) sort() {
+	slices.SortFunc(m.oids, compareOID)
+	m.oids = slices.CompactFunc(m.oids, func(a, b oid) bool { return compareOID(a, b) == 0 })
+}
+
+// contains reports whether o is in the set.
+func (m *mib) contains(o oid) bool {
+	_, found := slices.BinarySearchFunc(m.oids, o, compareOID)
+	return found
+}
+
+// next returns the first OID after o in lexicographic order, as GETNEXT
+// does. ok is false at the end of the MIB.
+func (m *mib) next(o oid) (oid, bool) {
+	i, found := slices.BinarySearchFunc(m.oids, o, compareOID)
+	if found {
+		i++
+	}
+	if i >= len(m.oids) {
+		return nil, false
+	}
+	return m.oids[i], true
+}
+
+// compareOID orders OIDs the way SNMP walks them.
+func compareOID(a, b oid) int {
+	return slices.Compare(a, b)
+}
@@ -0,0 +1,188 @@
+package simulate
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Profile is the shape of an interface's traffic over time.
+type Profile string
+
+// Traffic profiles.
+const (
+	ProfileFlat    Profile = "flat"    // steady, with a little noise
+	ProfileDiurnal Profile = "diurnal" // quiet at night, peaking mid-afternoon
+	ProfileBursty  Profile = "bursty"  // a low baseline with short bursts
+	ProfileIdle    Profile = "idle"    // no traffic
+)
+
+// Profiles lists the traffic profiles, in the order they are documented.
+var Profiles = []Profile{ProfileFlat, ProfileDiurnal, ProfileBursty, ProfileIdle}
+
+// Defaults applied to a Scenario by Normalize.
+const (
+	DefaultProfile     = ProfileDiurnal
+	DefaultUtilization = 0.3
+	DefaultFlapDown    = 10 * time.Second
+	DefaultRebootDown  = 20 * time.Second
+)
+
+// Behavior is how an interface's traffic and link state change over time.
+// Durations are read from TOML as strings such as "15m".
+type Behavior struct {
+	Profile      Profile       `toml:"profile"`
+	Utilization  float64       `toml:"utilization"` // average fraction of link speed, 0 to 1
+	FlapEveryStr string        `toml:"flap_every"`
+	FlapEvery    time.Duration `toml:"-"` // 0 for a link that never flaps
+	FlapDownStr  string        `toml:"flap_down"`
+	FlapDown     time.Duration `toml:"-"` // how long each flap keeps the link down
+}
+
+// InterfaceScenario overrides the default behavior for one interface,
+// matched by ifName or ifDescr. Fields left empty keep the default.
+type InterfaceScenario struct {
+	Name string `toml:"name"`
+	Behavior
+}
+
+// Scenario describes how a simulated device behaves: the traffic and link
+// flaps of its interfaces, and how often the whole device reboots. A
+// reboot makes the agent silent for RebootDown, then restarts sysUpTime
+// and every counter from zero.
+type Scenario struct {
+	Behavior
+	RebootEveryStr string              `toml:"reboot_every"`
+	RebootEvery    time.Duration       `toml:"-"` // 0 for a device that never reboots
+	RebootDownStr  string              `toml:"reboot_down"`
+	RebootDown     time.Duration       `toml:"-"`
+	Seed           int64               `toml:"seed"` // varies the traffic noise between devices
+	Interfaces     []InterfaceScenario `toml:"interfaces"`
+}
+
+// LoadScenario reads a scenario from a TOML file and normalizes it.
+func LoadScenario(path string) (*Scenario, error) {
+	var s Scenario
+	if _, err := toml.DecodeFile(path, &s); err != nil {
+		return nil, err
+	}
+	if err := s.Normalize(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Normalize parses the duration strings, applies defaults and checks that
+// the scenario is valid.
+func (s *Scenario) Normalize() error {
+	if s.Profile == "" {
+		s.Profile = DefaultProfile
+	}
+	if s.Utilization == 0 {
+		s.Utilization = DefaultUtilization
+	}
+	if err := s.Behavior.parse(); err != nil {
+		return err
+	}
+	if s.FlapDown == 0 {
+		s.FlapDown = DefaultFlapDown
+	}
+	if err := s.Behavior.validate(); err != nil {
+		return err
+	}
+
+	if err := parseDuration(s.RebootEveryStr, &s.RebootEvery, "reboot_every"); err != nil {
+		return err
+	}
+	if err := parseDuration(s.RebootDownStr, &s.RebootDown, "reboot_down"); err != nil {
+		return err
+	}
+	if s.RebootDownStr == "" {
+		s.RebootDown = DefaultRebootDown
+	}
+	if s.RebootEvery > 0 && s.RebootDown >= s.RebootEvery {
+		return fmt.Errorf("reboot_down %v must be shorter than reboot_every %v", s.RebootDown, s.RebootEvery)
+	}
+
+	for i := range s.Interfaces {
+		iface := &s.Interfaces[i]
+		if iface.Name == "" {
+			return fmt.Errorf("interface %d: name is required", i+1)
+		}
+		if err := iface.parse(); err != nil {
+			return fmt.Errorf("interface %q: %w", iface.Name, err)
+		}
+	}
+	for _, iface := range s.Interfaces {
+		b := s.behavior(iface.Name, iface.Name)
+		if err := b.validate(); err != nil {
+			return fmt.Errorf("interface %q: %w", iface.Name, err)
+		}
+	}
+	return nil
+}
+
+// behavior returns the behavior of the interface with the given ifName and
+// ifDescr: the scenario's defaults with any matching interface overrides
+// applied.
+func (s *Scenario) behavior(name, descr string) Behavior {
+	b := s.Behavior
+	for _, iface := range s.Interfaces {
+		if iface.Name != name && iface.Name != descr {
+			continue
+		}
+		if iface.Profile != "" {
+			b.Profile = iface.Profile
+		}
+		if iface.Utilization != 0 {
+			b.Utilization = iface.Utilization
+		}
+		if iface.FlapEveryStr != "" {
+			b.FlapEvery = iface.FlapEvery
+		}
+		if iface.FlapDownStr != "" {
+			b.FlapDown = iface.FlapDown
+		}
+	}
+	return b
+}
+
+// parse converts the duration strings into durations.
+func (b *Behavior) parse() error {
+	if err := parseDuration(b.FlapEveryStr, &b.FlapEvery, "flap_every"); err != nil {
+		return err
+	}
+	if err := parseDuration(b.FlapDownStr, &b.FlapDown, "flap_down"); err != nil {
+		return err
+	}
+	return b.validate()
+}
+
+// validate checks the fields that are set.
+func (b *Behavior) validate() error {
+	if b.Profile != "" && !slices.Contains(Profiles, b.Profile) {
+		return fmt.Errorf("unknown profile %q", b.Profile)
+	}
+	if b.Utilization < 0 || b.Utilization > 1 {
+		return fmt.Errorf("utilization %v must be between 0 and 1", b.Utilization)
+	}
+	if b.FlapEvery > 0 && b.FlapDown >= b.FlapEvery {
+		return fmt.Errorf("flap_down %v must be shorter than flap_every %v", b.FlapDown, b.FlapEvery)
+	}
+	return nil
+}
+
+// parseDuration parses s into d, leaving d alone if s is empty.
+func parseDuration(s string, d *time.Duration, field string) error {
+	if s == "" {
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil || v < 0 {
+		return fmt.Errorf("invalid %s %q", field, s)
+	}
+	*d = v
+	return nil
+}
@@ -0,0 +1,69 @@
+package simulate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadScenario(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lab.toml")
+	err := os.WriteFile(path, []byte(`profile = "flat"
+utilization = 0.5
+flap_every = "10m"
+reboot_every = "6h"
+
+[[interfaces]]
+name = "Gi0/2"
+profile = "bursty"
+flap_every = "2m"
+flap_down = "30s"
+
+[[interfaces]]
+name = "GigabitEthernet0/3"
+utilization = 0.9
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadScenario(path)
+	if err != nil {
+		t.Fatalf("LoadScenario() error: %v", err)
+	}
+	if s.FlapEvery != 10*time.Minute || s.FlapDown != DefaultFlapDown {
+		t.Errorf("expected a 10m flap with the default downtime, got %v/%v", s.FlapEvery, s.FlapDown)
+	}
+	if s.RebootEvery != 6*time.Hour || s.RebootDown != DefaultRebootDown {
+		t.Errorf("expected a 6h reboot with the default downtime, got %v/%v", s.RebootEvery, s.RebootDown)
+	}
+
+	if b := s.behavior("Gi0/1", "GigabitEthernet0/1"); b != s.Behavior {
+		t.Errorf("an interface without overrides should get the defaults, got %+v", b)
+	}
+	b := s.behavior("Gi0/2", "GigabitEthernet0/2")
+	if b.Profile != ProfileBursty || b.Utilization != 0.5 || b.FlapEvery != 2*time.Minute || b.FlapDown != 30*time.Second {
+		t.Errorf("unexpected behavior for Gi0/2: %+v", b)
+	}
+	b = s.behavior("Gi0/3", "GigabitEthernet0/3")
+	if b.Profile != ProfileFlat || b.Utilization != 0.9 {
+		t.Errorf("an interface should also match by ifDescr, got %+v", b)
+	}
+}
+
+func TestScenarioNormalizeErrors(t *testing.T) {
+	for name, s := range map[string]Scenario{
+		"unknown profile":       {Behavior: Behavior{Profile: "spiky"}},
+		"utilization over 1":    {Behavior: Behavior{Utilization: 1.5}},
+		"bad duration":          {Behavior: Behavior{FlapEveryStr: "often"}},
+		"flap longer than gap":  {Behavior: Behavior{FlapEveryStr: "5s"}},
+		"reboot longer than up": {RebootEveryStr: "10s", RebootDownStr: "1m"},
+		"unnamed interface":     {Interfaces: []InterfaceScenario{{}}},
+		"interface override":    {Interfaces: []InterfaceScenario{{Name: "Gi0/1", Behavior: Behavior{FlapEveryStr: "5s"}}}},
+	} {
+		if err := s.Normalize(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
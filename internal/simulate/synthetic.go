@@ -0,0 +1,43 @@
+package simulate
+
+import (
+	"fmt"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// syntheticSysObjectID identifies synthetic devices; it is net-snmp's
+// generic Linux agent OID.
+const syntheticSysObjectID = ".1.3.6.1.4.1.8072.3.2.10"
+
+// Synthetic generates the objects of a switch named name with the given
+// number of gigabit interfaces, GigabitEthernet0/1 (Gi0/1) onwards, all up
+// and with counters at zero. It can be passed to NewDevice in place of a
+// walk dump.
+func Synthetic(name string, interfaces int) []gosnmp.SnmpPDU {
+	pdus := []gosnmp.SnmpPDU{
+		{Name: "." + oidSysDescr, Type: gosnmp.OctetString, Value: []byte("flo simulated switch")},
+		{Name: "." + oidSysObjectID, Type: gosnmp.ObjectIdentifier, Value: syntheticSysObjectID},
+		{Name: "." + oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(0)},
+		{Name: "." + oidSysName, Type: gosnmp.OctetString, Value: []byte(name)},
+		{Name: "." + oidIfNumber, Type: gosnmp.Integer, Value: interfaces},
+	}
+	add := func(entry string, col, index int, typ gosnmp.Asn1BER, value any) {
+		pdus = append(pdus, gosnmp.SnmpPDU{Name: "." + columnOID(entry, col, index), Type: typ, Value: value})
+	}
+	for i := 1; i <= interfaces; i++ {
+		add(oidIfEntry, colIfIndex, i, gosnmp.Integer, i)
+		add(oidIfEntry, colIfDescr, i, gosnmp.OctetString, []byte(fmt.Sprintf("GigabitEthernet0/%d", i)))
+		add(oidIfEntry, colIfType, i, gosnmp.Integer, 6) // ethernetCsmacd
+		add(oidIfEntry, colIfMtu, i, gosnmp.Integer, 1500)
+		add(oidIfEntry, colIfSpeed, i, gosnmp.Gauge32, uint32(1_000_000_000))
+		add(oidIfEntry, colIfPhysAddress, i, gosnmp.OctetString, []byte{0x02, 0, 0, 0, byte(i >> 8), byte(i)})
+		add(oidIfEntry, colIfAdminStatus, i, gosnmp.Integer, 1)
+		add(oidIfEntry, colIfOperStatus, i, gosnmp.Integer, 1)
+		add(oidIfXEntry, colIfName, i, gosnmp.OctetString, []byte(fmt.Sprintf("Gi0/%d", i)))
+		add(oidIfXEntry, colIfHighSpeed, i, gosnmp.Gauge32, uint32(1000))
+		add(oidIfXEntry, colIfAlias, i, gosnmp.OctetString, []byte{})
+		add(oidIfXEntry, colIfCounterDiscontinuity, i, gosnmp.TimeTicks, uint32(0))
+	}
+	return pdus
+}
@@ -0,0 +1,111 @@
+package simulate
+
+import (
+	"math"
+	"time"
+)
+
+// Traffic direction, used to vary the noise between the two directions of
+// an interface.
+const (
+	dirIn  = 0
+	dirOut = 1
+)
+
+// Shape of the simulated traffic.
+const (
+	noiseSlot      = 10 * time.Second // how long a noise value lasts
+	outboundFactor = 0.6              // outbound traffic relative to inbound
+	avgPacketSize  = 800              // bytes
+	multicastShare = 0.02             // of packets
+	broadcastShare = 0.005            // of packets
+	errorRate      = 1e-7             // errored packets per packet
+	discardRate    = 1e-6             // discarded packets per packet, below congestion
+	congestion     = 0.9              // utilization above which discards climb
+)
+
+// level returns the traffic at time t relative to the profile's average,
+// given a noise value in [0, 1).
+func (p Profile) level(t time.Time, noise float64) float64 {
+	jitter := 0.9 + 0.2*noise
+	switch p {
+	case ProfileFlat:
+		return jitter
+	case ProfileDiurnal:
+		hour := float64(t.Hour()) + float64(t.Minute())/60
+		return (0.1 + 0.9*(1-math.Cos(2*math.Pi*(hour-4)/24))) * jitter
+	case ProfileBursty:
+		if noise >= 0.9 {
+			return 5.5
+		}
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+// utilization returns the fraction of link speed an interface uses in one
+// direction at time t.
+func (b Behavior) utilization(t time.Time, seed int64, ifIndex, dir int) float64 {
+	u := b.Utilization * b.Profile.level(t, noise(seed, ifIndex, dir, t))
+	if dir == dirOut {
+		u *= outboundFactor
+	}
+	return min(u, 1)
+}
+
+// flapState reports whether a flapping link is down at time t, and when it
+// last changed state. Each cycle of FlapEvery ends with FlapDown of
+// downtime; offset staggers the cycles of different interfaces so they do
+// not all flap at once.
+func (b Behavior) flapState(t, since time.Time, offset time.Duration) (down bool, changed time.Time) {
+	if b.FlapEvery <= 0 {
+		return false, since
+	}
+	pos := (t.Sub(since) + offset) % b.FlapEvery
+	upFor := b.FlapEvery - b.FlapDown
+	if pos >= upFor {
+		return true, t.Add(-(pos - upFor))
+	}
+	return false, t.Add(-pos)
+}
+
+// noise returns a pseudo-random number in [0, 1) that depends only on its
+// arguments and the noiseSlot that t falls in, so that a scenario always
+// produces the same traffic.
+func noise(seed int64, ifIndex, dir int, t time.Time) float64 {
+	x := uint64(seed)
+	for _, v := range []uint64{uint64(ifIndex), uint64(dir), uint64(t.Unix() / int64(noiseSlot/time.Second))} {
+		x = splitmix(x ^ v)
+	}
+	return float64(x>>11) / (1 << 53)
+}
+
+// splitmix is the SplitMix64 finalizer, a fast well-mixed hash of x.
+func splitmix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// counters are an interface's running totals in one direction. They are
+// kept as floats so that fractions of a packet carry over between updates.
+type counters struct {
+	octets, ucast, mcast, bcast, errors, discards float64
+}
+
+// add accounts for bytes of traffic at the given utilization.
+func (c *counters) add(bytes, utilization float64) {
+	c.octets += bytes
+	packets := bytes / avgPacketSize
+	c.mcast += packets * multicastShare
+	c.bcast += packets * broadcastShare
+	c.ucast += packets * (1 - multicastShare - broadcastShare)
+	c.errors += packets * errorRate
+	drop := discardRate
+	if utilization > congestion {
+		drop += 0.01 * (utilization - congestion) / (1 - congestion)
+	}
+	c.discards += packets * drop
+}
@@ -0,0 +1,201 @@
+package simulate
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// LoadWalk reads an snmpwalk dump file. See ParseWalk for the format.
+func LoadWalk(path string) ([]gosnmp.SnmpPDU, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseWalk(f)
+}
+
+// ParseWalk reads the output of net-snmp's snmpwalk run with numeric OIDs
+// (-On), one "OID = TYPE: value" line per object, for example:
+//
+//	.1.3.6.1.2.1.2.2.1.2.1 = STRING: "GigabitEthernet0/1"
+//	.1.3.6.1.2.1.2.2.1.8.1 = INTEGER: up(1)
+//
+// Strings may continue over several lines. Objects of types the simulator
+// cannot serve, and the "No Such Object" and "No more variables" lines
+// snmpwalk prints at the end of a subtree, are skipped.
+func ParseWalk(r io.Reader) ([]gosnmp.SnmpPDU, error) {
+	var pdus []gosnmp.SnmpPDU
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, " = ")
+		if !ok {
+			// A bare "OID =" is an empty value.
+			name, ok = strings.CutSuffix(line, " =")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected \"OID = TYPE: value\"", lineNo)
+			}
+		}
+		o, err := parseOID(name)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w (dump with snmpwalk -On for numeric OIDs)", lineNo, err)
+		}
+
+		// A quoted string that is not closed on this line continues on the
+		// following ones.
+		if rest, ok := strings.CutPrefix(value, "STRING: \""); ok && !closesQuote(rest) {
+			for scanner.Scan() {
+				lineNo++
+				value += "\n" + strings.TrimRight(scanner.Text(), "\r")
+				if closesQuote(scanner.Text()) {
+					break
+				}
+			}
+		}
+
+		pdu, ok, err := parseWalkValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if ok {
+			pdu.Name = o.String()
+			pdus = append(pdus, pdu)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return pdus, nil
+}
+
+// closesQuote reports whether s ends a quoted string, that is, ends in a
+// quote that is not escaped.
+func closesQuote(s string) bool {
+	s = strings.TrimRight(s, "\r")
+	if !strings.HasSuffix(s, "\"") {
+		return false
+	}
+	backslashes := 0
+	for i := len(s) - 2; i >= 0 && s[i] == '\\'; i-- {
+		backslashes++
+	}
+	return backslashes%2 == 0
+}
+
+// parseWalkValue decodes the "TYPE: value" part of a dump line. ok is false
+// for values that are skipped.
+func parseWalkValue(s string) (pdu gosnmp.SnmpPDU, ok bool, err error) {
+	if s == "" || s == `""` {
+		return gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte{}}, true, nil
+	}
+	typ, value, found := strings.Cut(s, ": ")
+	if !found {
+		typ, found = strings.CutSuffix(s, ":")
+		if !found {
+			// "No Such Object available on this agent at this OID" and
+			// similar notes.
+			return pdu, false, nil
+		}
+	}
+
+	switch typ {
+	case "STRING":
+		pdu.Type = gosnmp.OctetString
+		pdu.Value = []byte(unquote(value))
+	case "Hex-STRING":
+		b, err := hex.DecodeString(strings.Join(strings.Fields(value), ""))
+		if err != nil {
+			return pdu, false, fmt.Errorf("invalid Hex-STRING %q", value)
+		}
+		pdu.Type = gosnmp.OctetString
+		pdu.Value = b
+	case "INTEGER":
+		n, err := strconv.Atoi(enumValue(value))
+		if err != nil {
+			return pdu, false, fmt.Errorf("invalid INTEGER %q", value)
+		}
+		pdu.Type = gosnmp.Integer
+		pdu.Value = n
+	case "Counter32", "Gauge32", "Timeticks", "UInteger32", "Unsigned32":
+		field := value
+		if typ == "Timeticks" {
+			field = enumValue(value)
+		}
+		n, err := strconv.ParseUint(firstField(field), 10, 32)
+		if err != nil {
+			return pdu, false, fmt.Errorf("invalid %s %q", typ, value)
+		}
+		pdu.Type = map[string]gosnmp.Asn1BER{
+			"Counter32":  gosnmp.Counter32,
+			"Gauge32":    gosnmp.Gauge32,
+			"Timeticks":  gosnmp.TimeTicks,
+			"UInteger32": gosnmp.Gauge32,
+			"Unsigned32": gosnmp.Gauge32,
+		}[typ]
+		pdu.Value = uint32(n)
+	case "Counter64":
+		n, err := strconv.ParseUint(firstField(value), 10, 64)
+		if err != nil {
+			return pdu, false, fmt.Errorf("invalid Counter64 %q", value)
+		}
+		pdu.Type = gosnmp.Counter64
+		pdu.Value = n
+	case "OID":
+		o, err := parseOID(value)
+		if err != nil {
+			return pdu, false, err
+		}
+		pdu.Type = gosnmp.ObjectIdentifier
+		pdu.Value = o.String()
+	case "IpAddress":
+		pdu.Type = gosnmp.IPAddress
+		pdu.Value = strings.TrimSpace(value)
+	default:
+		return pdu, false, nil
+	}
+	return pdu, true, nil
+}
+
+// unquote strips the quotes snmpwalk puts around a string and undoes its
+// escaping.
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+		s = strings.ReplaceAll(s, `\"`, `"`)
+		s = strings.ReplaceAll(s, `\\`, `\`)
+	}
+	return s
+}
+
+// enumValue returns the number in an enumerated or tick value such as
+// "up(1)" or "(12345) 0:02:03.45", or s itself if it has none.
+func enumValue(s string) string {
+	open := strings.IndexByte(s, '(')
+	end := strings.IndexByte(s, ')')
+	if open >= 0 && end > open {
+		return s[open+1 : end]
+	}
+	return strings.TrimSpace(s)
+}
+
+// firstField returns the first word of s, dropping units such as "octets".
+func firstField(s string) string {
+	if fields := strings.Fields(s); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
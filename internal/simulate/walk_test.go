@@ -0,0 +1,74 @@
+package simulate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+func TestParseWalk(t *testing.T) {
+	dump := `.1.3.6.1.2.1.1.1.0 = STRING: "Cisco IOS Software,
+Version 15.2, \"lab\""
+.1.3.6.1.2.1.1.2.0 = OID: .1.3.6.1.4.1.9.1.1208
+.1.3.6.1.2.1.1.3.0 = Timeticks: (12345) 0:02:03.45
+.1.3.6.1.2.1.2.2.1.2.1 = STRING: GigabitEthernet0/1
+.1.3.6.1.2.1.2.2.1.6.1 = Hex-STRING: 00 1A 2B 3C 4D 5E
+.1.3.6.1.2.1.2.2.1.8.1 = INTEGER: up(1)
+.1.3.6.1.2.1.2.2.1.10.1 = Counter32: 4000000000
+.1.3.6.1.2.1.31.1.1.1.6.1 = Counter64: 12345678901234
+.1.3.6.1.2.1.31.1.1.1.15.1 = Gauge32: 1000
+.1.3.6.1.2.1.31.1.1.1.18.1 = ""
+.1.3.6.1.2.1.4.20.1.1.10.0.0.1 = IpAddress: 10.0.0.1
+.1.3.6.1.2.1.4.22.1.2.1.10.0.0.2 = Network Address: 0A:00:00:02
+.1.3.6.1.2.1.99 = No Such Object available on this agent at this OID
+`
+	pdus, err := ParseWalk(strings.NewReader(dump))
+	if err != nil {
+		t.Fatalf("ParseWalk() error: %v", err)
+	}
+	want := []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("Cisco IOS Software,\nVersion 15.2, \"lab\"")},
+		{Name: ".1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.9.1.1208"},
+		{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(12345)},
+		{Name: ".1.3.6.1.2.1.2.2.1.2.1", Type: gosnmp.OctetString, Value: []byte("GigabitEthernet0/1")},
+		{Name: ".1.3.6.1.2.1.2.2.1.6.1", Type: gosnmp.OctetString, Value: []byte{0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}},
+		{Name: ".1.3.6.1.2.1.2.2.1.8.1", Type: gosnmp.Integer, Value: 1},
+		{Name: ".1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint32(4000000000)},
+		{Name: ".1.3.6.1.2.1.31.1.1.1.6.1", Type: gosnmp.Counter64, Value: uint64(12345678901234)},
+		{Name: ".1.3.6.1.2.1.31.1.1.1.15.1", Type: gosnmp.Gauge32, Value: uint32(1000)},
+		{Name: ".1.3.6.1.2.1.31.1.1.1.18.1", Type: gosnmp.OctetString, Value: []byte{}},
+		{Name: ".1.3.6.1.2.1.4.20.1.1.10.0.0.1", Type: gosnmp.IPAddress, Value: "10.0.0.1"},
+	}
+	if len(pdus) != len(want) {
+		t.Fatalf("expected %d objects, got %d: %+v", len(want), len(pdus), pdus)
+	}
+	for i := range want {
+		got, exp := pdus[i], want[i]
+		if got.Name != exp.Name || got.Type != exp.Type {
+			t.Errorf("object %d: got %s %v, want %s %v", i, got.Name, got.Type, exp.Name, exp.Type)
+			continue
+		}
+		if b, ok := exp.Value.([]byte); ok {
+			if !bytes.Equal(got.Value.([]byte), b) {
+				t.Errorf("%s: got %q, want %q", got.Name, got.Value, b)
+			}
+		} else if got.Value != exp.Value {
+			t.Errorf("%s: got %v (%T), want %v (%T)", got.Name, got.Value, got.Value, exp.Value, exp.Value)
+		}
+	}
+}
+
+func TestParseWalkErrors(t *testing.T) {
+	for _, dump := range []string{
+		"IF-MIB::ifDescr.1 = STRING: Gi0/1",
+		".1.3.6.1.2.1.2.2.1.8.1 = INTEGER: up",
+		".1.3.6.1.2.1.2.2.1.6.1 = Hex-STRING: 0G",
+		"just some text",
+	} {
+		if _, err := ParseWalk(strings.NewReader(dump)); err == nil {
+			t.Errorf("expected an error parsing %q", dump)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -11,6 +13,7 @@ import (
 	"github.com/tonhe/flo/cmd"
 	"github.com/tonhe/flo/internal/config"
 	"github.com/tonhe/flo/internal/engine"
+	"github.com/tonhe/flo/internal/history"
 	"github.com/tonhe/flo/internal/identity"
 	"github.com/tonhe/flo/tui"
 	"github.com/tonhe/flo/tui/styles"
@@ -25,8 +28,9 @@ func main() {
 
 	args := os.Args[1:]
 
-	// Parse TUI flags (--dashboard, --theme, --help) before subcommand check
-	var dashboardFlag, themeFlag string
+	// Parse TUI flags (--dashboard, --theme, --replay, --speed, --help)
+	// before subcommand check
+	var dashboardFlag, themeFlag, replayFlag, speedFlag string
 	var filtered []string
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
@@ -40,6 +44,16 @@ func main() {
 				themeFlag = args[i+1]
 				i++
 			}
+		case "--replay":
+			if i+1 < len(args) {
+				replayFlag = args[i+1]
+				i++
+			}
+		case "--speed":
+			if i+1 < len(args) {
+				speedFlag = args[i+1]
+				i++
+			}
 		case "--help", "-h":
 			cmd.Execute([]string{"help"})
 			return
@@ -118,7 +132,25 @@ func main() {
 	}
 
 	mgr := engine.NewManager()
-	model := tui.NewAppModel(cfg, mgr, provider, dashboardFlag, storePath)
+	if historyDir, err := config.GetHistoryDir(); err == nil {
+		store := history.NewStore(historyDir)
+		store.SetRetention(time.Duration(cfg.HistoryDays)*24*time.Hour, int64(cfg.HistoryMaxMB)<<20)
+		mgr.SetStore(store)
+	}
+	if dashDir, err := config.GetDashboardsDir(); err == nil {
+		mgr.Watch(dashDir)
+	}
+	// Play a recording back instead of starting a dashboard
+	startDash := dashboardFlag
+	if replayFlag != "" {
+		name, err := startReplay(mgr, replayFlag, speedFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		startDash = name
+	}
+	model := tui.NewAppModel(cfg, mgr, provider, startDash, storePath)
 
 	p := tea.NewProgram(model, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
@@ -126,3 +158,25 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// startReplay starts an engine playing back the recording at path, which
+// may also name a file in the recordings directory, at the given speed
+// (1 if empty), and returns its name.
+func startReplay(mgr *engine.Manager, path, speedFlag string) (string, error) {
+	speed := 1.0
+	if speedFlag != "" {
+		var err error
+		speed, err = strconv.ParseFloat(speedFlag, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid speed %q", speedFlag)
+		}
+	}
+	if _, err := os.Stat(path); err != nil {
+		if dir, dirErr := config.GetRecordingsDir(); dirErr == nil {
+			if _, statErr := os.Stat(filepath.Join(dir, path)); statErr == nil {
+				path = filepath.Join(dir, path)
+			}
+		}
+	}
+	return mgr.Replay(path, speed)
+}
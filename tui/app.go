@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"strconv"
@@ -13,6 +14,7 @@ import (
 	"github.com/tonhe/flo/internal/config"
 	"github.com/tonhe/flo/internal/dashboard"
 	"github.com/tonhe/flo/internal/engine"
+	"github.com/tonhe/flo/internal/history"
 	"github.com/tonhe/flo/internal/identity"
 	"github.com/tonhe/flo/internal/version"
 	"github.com/tonhe/flo/tui/components"
@@ -32,6 +34,7 @@ const (
 	StateBuilder
 	StateSettings
 	StateEditor
+	StateDevice
 )
 
 // TickMsg triggers a periodic UI refresh to pick up new poll data.
@@ -47,6 +50,7 @@ type AppModel struct {
 	dashboard  views.DashboardView
 	switcher   views.SwitcherView
 	detail     views.DetailView
+	device     views.DeviceView
 	identity   views.IdentityView
 	builder    views.BuilderView
 	editor     views.EditorView
@@ -57,6 +61,9 @@ type AppModel struct {
 	startDashName string // auto-start dashboard from --dashboard flag
 	storePath     string
 	confirmQuit   bool
+	usageAt       time.Time // when the detail view's usage was last read
+	recordNote    string    // outcome of the last recording started or stopped
+	recordNoteAt  time.Time
 }
 
 // NewAppModel creates a new AppModel with the given config, engine manager,
@@ -80,12 +87,74 @@ func NewAppModel(cfg *config.Config, mgr *engine.Manager, provider identity.Prov
 		dashboard:     dashView,
 		switcher:      views.NewSwitcherView(theme),
 		detail:        detailView,
+		device:        views.NewDeviceView(theme),
 		identity:      views.NewIdentityView(theme, provider),
 		startDashName: startDash,
 		storePath:     storePath,
 	}
 }
 
+// usageRefresh is how often the detail view's billing statistics are
+// re-read from the on-disk history.
+const usageRefresh = 5 * time.Minute
+
+// usageMsg carries billing statistics read for the detail view.
+type usageMsg struct {
+	series history.Series
+	usage  []history.Usage
+}
+
+// usageCmd reads the billing statistics of the selected interface from the
+// on-disk history in the background. It returns nil when there is no
+// history or the selection is not a device interface.
+func (m *AppModel) usageCmd() tea.Cmd {
+	store := m.manager.Store()
+	t := m.dashboard.SelectedTarget()
+	_, iface := m.dashboard.SelectedInterface()
+	if store == nil || t == nil || iface == nil {
+		return nil
+	}
+	m.usageAt = time.Now()
+	dash, series := m.activeDash, history.Series{Host: t.Host, Interface: iface.Name}
+	return func() tea.Msg {
+		usage, err := store.Usage(dash, series, history.BillingPeriods(time.Now()))
+		if err != nil {
+			return nil
+		}
+		return usageMsg{series: series, usage: usage}
+	}
+}
+
+// stopTimeout bounds how long stopping an engine waits for its polls in
+// flight to return and its sessions to close.
+const stopTimeout = 3 * time.Second
+
+// stopEngine stops the named dashboard's engine and waits for it to shut
+// down.
+func (m *AppModel) stopEngine(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	_ = m.manager.Stop(ctx, name)
+}
+
+// engineStoppedMsg is sent when an engine stopped from the switcher has
+// shut down.
+type engineStoppedMsg struct {
+	name string
+}
+
+// stopCmd stops the named dashboard's engine in the background, so that
+// the switcher can show it stopping.
+func (m *AppModel) stopCmd(name string) tea.Cmd {
+	mgr := m.manager
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+		defer cancel()
+		_ = mgr.Stop(ctx, name)
+		return engineStoppedMsg{name: name}
+	}
+}
+
 // autoStartMsg is sent after Init to trigger dashboard auto-loading.
 type autoStartMsg struct {
 	name string
@@ -113,7 +182,34 @@ func tickCmd() tea.Cmd {
 // Update handles messages and dispatches to the active view.
 func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case usageMsg:
+		if t := m.dashboard.SelectedTarget(); t != nil {
+			if _, iface := m.dashboard.SelectedInterface(); iface != nil &&
+				msg.series == (history.Series{Host: t.Host, Interface: iface.Name}) {
+				m.detail.SetUsage(msg.usage)
+			}
+		}
+		return m, nil
+
+	case engineStoppedMsg:
+		if m.activeDash == msg.name {
+			m.activeDash = ""
+			m.dashboard.SetSnapshot(nil)
+		}
+		if m.state == StateSwitcher {
+			m.refreshSwitcher()
+		}
+		return m, nil
+
 	case autoStartMsg:
+		// An engine already running under the name, such as a replay
+		// started from the command line, is just shown.
+		for _, info := range m.manager.TryListEngines() {
+			if info.Name == msg.name {
+				m.activeDash = msg.name
+				return m, nil
+			}
+		}
 		dashDir, err := config.GetDashboardsDir()
 		if err != nil {
 			return m, nil
@@ -136,6 +232,7 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		bodyHeight := msg.Height - 3
 		m.dashboard.SetSize(msg.Width, bodyHeight)
 		m.detail.SetSize(msg.Width, bodyHeight)
+		m.device.SetSize(msg.Width, bodyHeight)
 		m.identity.SetSize(msg.Width, bodyHeight)
 		m.builder.SetSize(msg.Width, bodyHeight)
 		m.editor.SetSize(msg.Width, bodyHeight)
@@ -153,10 +250,22 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					label, iface := m.dashboard.SelectedInterface()
 					if iface != nil {
 						m.detail.SetInterface(label, iface)
+						m.detail.SetDevice(m.dashboard.SelectedDevice())
+						m.detail.SetNow(replayTime(snap))
+					}
+					if time.Since(m.usageAt) >= usageRefresh {
+						return m, tea.Batch(tickCmd(), m.usageCmd())
 					}
 				}
+				if m.state == StateDevice {
+					m.device.SetSnapshot(snap)
+				}
 			}
 		}
+		if m.state == StateSwitcher {
+			// Engine states change as they start, fail and stop
+			m.refreshSwitcher()
+		}
 		return m, tickCmd()
 
 	case tea.KeyMsg:
@@ -164,7 +273,9 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.confirmQuit {
 			switch msg.String() {
 			case "y":
-				m.manager.StopAll()
+				ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+				_ = m.manager.StopAll(ctx)
+				cancel()
 				return m, tea.Quit
 			case "n", "esc":
 				m.confirmQuit = false
@@ -213,12 +324,30 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 					return m, nil
 				}
+				// Start or stop recording on 'R'
+				if key.Matches(msg, keys.DefaultKeyMap.Record) {
+					m.toggleRecording()
+					return m, nil
+				}
 				// Open detail view on Enter
 				if key.Matches(msg, keys.DefaultKeyMap.Enter) {
 					label, iface := m.dashboard.SelectedInterface()
 					if iface != nil {
 						m.detail.SetInterface(label, iface)
+						m.detail.SetDevice(m.dashboard.SelectedDevice())
+						m.detail.SetNow(replayTime(m.manager.TryGetSnapshot(m.activeDash)))
+						m.detail.SetUsage(nil)
 						m.state = StateDetail
+						return m, m.usageCmd()
+					}
+					return m, nil
+				}
+				// Open the device panel for the selected target on 'c'
+				if key.Matches(msg, keys.DefaultKeyMap.Chassis) {
+					if t := m.dashboard.SelectedTarget(); t != nil {
+						m.device.SetHost(t.Host)
+						m.device.SetSnapshot(m.manager.TryGetSnapshot(m.activeDash))
+						m.state = StateDevice
 					}
 					return m, nil
 				}
@@ -240,6 +369,19 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, cmd
 
+		case StateDevice:
+			if msg.String() == "q" {
+				return m.tryQuit()
+			}
+			var cmd tea.Cmd
+			var goBack bool
+			m.device, cmd, goBack = m.device.Update(msg)
+			if goBack {
+				m.state = StateDashboard
+				return m, nil
+			}
+			return m, cmd
+
 		case StateIdentity:
 			var cmd tea.Cmd
 			var goBack bool
@@ -278,12 +420,7 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			case views.ActionStop:
 				if item := m.switcher.SelectedItem(); item != nil && item.Running {
-					_ = m.manager.Stop(item.Name)
-					if m.activeDash == item.Name {
-						m.activeDash = ""
-						m.dashboard.SetSnapshot(nil)
-					}
-					m.refreshSwitcher()
+					return m, m.stopCmd(item.Name)
 				}
 				return m, nil
 
@@ -316,7 +453,7 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if dash, err := dashboard.LoadDashboard(path); err == nil {
 					// Stop old engine if editing an active dashboard
 					if m.activeDash != "" {
-						_ = m.manager.Stop(m.activeDash)
+						m.stopEngine(m.activeDash)
 					}
 					if m.provider != nil {
 						_ = m.manager.Start(dash, m.provider)
@@ -367,11 +504,15 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case views.EditorActionSaved:
 				path := m.editor.SavedPath
 				if dash, loadErr := dashboard.LoadDashboard(path); loadErr == nil {
-					if m.activeDash != "" {
-						_ = m.manager.Stop(m.activeDash)
-					}
-					if m.provider != nil {
-						_ = m.manager.Start(dash, m.provider)
+					// Apply the edit in place, keeping history, unless
+					// the dashboard is not running under this name
+					if err := m.manager.Update(dash); err != nil {
+						if m.activeDash != "" {
+							m.stopEngine(m.activeDash)
+						}
+						if m.provider != nil {
+							_ = m.manager.Start(dash, m.provider)
+						}
 					}
 					m.activeDash = dash.Name
 				}
@@ -396,7 +537,7 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				path := m.builder.SavedPath
 				if dash, err := dashboard.LoadDashboard(path); err == nil {
 					if m.activeDash != "" {
-						_ = m.manager.Stop(m.activeDash)
+						m.stopEngine(m.activeDash)
 					}
 					if m.provider != nil {
 						_ = m.manager.Start(dash, m.provider)
@@ -416,11 +557,13 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if action == views.EditorActionSaved {
 				path := m.editor.SavedPath
 				if dash, loadErr := dashboard.LoadDashboard(path); loadErr == nil {
-					if m.activeDash != "" {
-						_ = m.manager.Stop(m.activeDash)
-					}
-					if m.provider != nil {
-						_ = m.manager.Start(dash, m.provider)
+					if err := m.manager.Update(dash); err != nil {
+						if m.activeDash != "" {
+							m.stopEngine(m.activeDash)
+						}
+						if m.provider != nil {
+							_ = m.manager.Start(dash, m.provider)
+						}
 					}
 					m.activeDash = dash.Name
 				}
@@ -499,6 +642,66 @@ func (m *AppModel) editActiveDashboard() {
 	}
 }
 
+// recordNoteTime is how long the outcome of starting or stopping a
+// recording stays in the status bar.
+const recordNoteTime = 10 * time.Second
+
+// toggleRecording starts recording the active dashboard to a new file in
+// the recordings directory, or stops the recording under way. Replays
+// cannot be recorded.
+func (m *AppModel) toggleRecording() {
+	snap := m.manager.TryGetSnapshot(m.activeDash)
+	if snap == nil || snap.Replay != nil {
+		return
+	}
+	m.recordNoteAt = time.Now()
+	if rec := snap.Recording; rec != nil {
+		m.recordNote = "saved " + rec.File
+		if err := m.manager.StopRecording(m.activeDash); err != nil {
+			m.recordNote = "recording failed: " + err.Error()
+		}
+		return
+	}
+	dir, err := config.GetRecordingsDir()
+	if err == nil {
+		err = m.manager.Record(m.activeDash, filepath.Join(dir, engine.RecordingName(m.activeDash, time.Now())))
+	}
+	m.recordNote = ""
+	if err != nil {
+		m.recordNote = "recording failed: " + err.Error()
+	}
+}
+
+// recordingNotice describes the recording a dashboard is writing or
+// playing back, for the status bar.
+func recordingNotice(snap *engine.DashboardSnapshot) string {
+	if r := snap.Replay; r != nil {
+		switch {
+		case r.Error != "":
+			return fmt.Sprintf("REPLAY %s stopped: %s", r.File, r.Error)
+		case r.Done:
+			return fmt.Sprintf("REPLAY %s ended at %s", r.File, r.Position.Format("Jan 2 15:04:05"))
+		}
+		return fmt.Sprintf("REPLAY %s %s (%gx)", r.File, r.Position.Format("Jan 2 15:04:05"), r.Speed)
+	}
+	if r := snap.Recording; r != nil {
+		if r.Error != "" {
+			return fmt.Sprintf("REC %s failed: %s", r.File, r.Error)
+		}
+		return fmt.Sprintf("REC %s %s", r.File, time.Since(r.Since).Truncate(time.Second))
+	}
+	return ""
+}
+
+// replayTime returns how far a replay has got into its recording, or the
+// zero time for a live dashboard.
+func replayTime(snap *engine.DashboardSnapshot) time.Time {
+	if snap == nil || snap.Replay == nil {
+		return time.Time{}
+	}
+	return snap.Replay.Position
+}
+
 // tryQuit either quits immediately (no engines running) or shows a confirmation dialog.
 func (m AppModel) tryQuit() (tea.Model, tea.Cmd) {
 	if len(m.manager.TryListEngines()) == 0 {
@@ -566,6 +769,8 @@ func (m AppModel) View() string {
 		body = m.dashboard.View()
 	case StateDetail:
 		body = m.detail.View()
+	case StateDevice:
+		body = m.device.View()
 	case StateIdentity:
 		body = m.identity.View()
 	case StateBuilder:
@@ -581,15 +786,22 @@ func (m AppModel) View() string {
 	// Gather status bar metrics (non-blocking)
 	var lastPoll time.Time
 	var interval time.Duration
+	var notice string
+	replaying := false
 	okCount, totalCount := 0, 0
 	if m.activeDash != "" {
 		if snap := m.manager.TryGetSnapshot(m.activeDash); snap != nil {
 			lastPoll = snap.LastPoll
+			notice = snap.ConfigError
+			replaying = snap.Replay != nil
+			if rec := recordingNotice(snap); rec != "" {
+				notice = strings.TrimSuffix(rec+"; "+notice, "; ")
+			}
 			for _, g := range snap.Groups {
 				for _, t := range g.Targets {
 					for _, iface := range t.Interfaces {
 						totalCount++
-						if iface.PollError == nil {
+						if iface.PollError == nil && !iface.NotFound {
 							okCount++
 						}
 					}
@@ -598,50 +810,59 @@ func (m AppModel) View() string {
 		}
 		interval = m.config.PollInterval
 	}
+	if m.recordNote != "" && time.Since(m.recordNoteAt) < recordNoteTime {
+		notice = strings.TrimSuffix(m.recordNote+"; "+notice, "; ")
+	}
 
 	// Per-state key hints for the status bar
 	var hints []components.KeyHint
 	switch m.state {
 	case StateDashboard:
 		hints = []components.KeyHint{
-			{"d", "dashboards"}, {"s", "settings"},
+			{Key: "d", Desc: "dashboards"}, {Key: "s", Desc: "settings"},
 		}
 		if m.activeDash != "" {
 			hints = append(hints,
 				components.KeyHint{Key: "enter", Desc: "detail"},
-				components.KeyHint{Key: "e", Desc: "edit"},
-				components.KeyHint{Key: "r", Desc: "refresh"},
+				components.KeyHint{Key: "c", Desc: "device"},
 			)
+			if !replaying {
+				hints = append(hints, components.KeyHint{Key: "e", Desc: "edit"})
+			}
+			hints = append(hints, components.KeyHint{Key: "r", Desc: "refresh"})
+			if !replaying {
+				hints = append(hints, components.KeyHint{Key: "R", Desc: "record"})
+			}
 		}
 		hints = append(hints, components.KeyHint{Key: "q", Desc: "quit"})
-	case StateDetail:
+	case StateDetail, StateDevice:
 		hints = []components.KeyHint{
-			{"esc", "back"}, {"q", "quit"},
+			{Key: "esc", Desc: "back"}, {Key: "q", Desc: "quit"},
 		}
 	case StateSwitcher:
 		hints = []components.KeyHint{
-			{"enter", "switch"}, {"n", "new"}, {"e", "edit"},
-			{"x", "stop"}, {"esc", "close"}, {"q", "quit"},
+			{Key: "enter", Desc: "switch"}, {Key: "n", Desc: "new"}, {Key: "e", Desc: "edit"},
+			{Key: "x", Desc: "stop"}, {Key: "esc", Desc: "close"}, {Key: "q", Desc: "quit"},
 		}
 	case StateIdentity:
 		hints = []components.KeyHint{
-			{"esc", "back"}, {"ctrl+c", "quit"},
+			{Key: "esc", Desc: "back"}, {Key: "ctrl+c", Desc: "quit"},
 		}
 	case StateBuilder:
 		hints = []components.KeyHint{
-			{"esc", "cancel"}, {"ctrl+c", "quit"},
+			{Key: "esc", Desc: "cancel"}, {Key: "ctrl+c", Desc: "quit"},
 		}
 	case StateEditor:
 		hints = []components.KeyHint{
-			{"esc", "save & close"}, {"ctrl+c", "quit"},
+			{Key: "esc", Desc: "save & close"}, {Key: "ctrl+c", Desc: "quit"},
 		}
 	case StateSettings:
 		hints = []components.KeyHint{
-			{"esc", "back"}, {"ctrl+c", "quit"},
+			{Key: "esc", Desc: "back"}, {Key: "ctrl+c", Desc: "quit"},
 		}
 	}
 
-	statusBar := components.RenderStatusBar(renderTheme, interval, lastPoll, okCount, totalCount, m.width, hints)
+	statusBar := components.RenderStatusBar(renderTheme, interval, lastPoll, okCount, totalCount, m.width, notice, hints)
 
 	// Fill body to the available height between header and status bar
 	bodyHeight := m.height - 1 - 2 // 1 header line, 2 status bar lines
@@ -158,9 +158,19 @@ func centerText(s string, width int) string {
 
 // ChartOptions holds optional configuration for enhanced chart rendering.
 type ChartOptions struct {
-	Timestamps []time.Time // timestamps corresponding to data points (for X-axis)
-	TimeFormat string      // "relative", "absolute", or "both"
-	Label      string      // short label like "In" or "Out" (used in stats title)
+	Timestamps []time.Time          // timestamps corresponding to data points (for X-axis)
+	TimeFormat string               // "relative", "absolute", or "both"
+	Now        time.Time            // time relative labels count back from; the wall clock if zero
+	Label      string               // short label like "In" or "Out" (used in stats title)
+	Format     func(float64) string // value formatter for labels; defaults to FormatRate
+}
+
+// formatter returns the value formatter for the chart, defaulting to bit rates.
+func (o ChartOptions) formatter() func(float64) string {
+	if o.Format != nil {
+		return o.Format
+	}
+	return FormatRate
 }
 
 // FormatTimeLabel formats a timestamp as a time label for the X-axis.
@@ -270,11 +280,12 @@ func RenderChartWithOptions(data []float64, width, height int, colors ChartColor
 	}
 
 	spread := maxVal - minVal
+	format := opts.formatter()
 
 	// Build the chart grid from top to bottom
 	for row := chartHeight - 1; row >= 0; row-- {
 		rowTopVal := minVal + spread*float64(row+1)/float64(chartHeight)
-		label := fmt.Sprintf("%7s ", FormatRate(rowTopVal))
+		label := fmt.Sprintf("%7s ", format(rowTopVal))
 		if len(label) > labelWidth {
 			label = label[len(label)-labelWidth:]
 		}
@@ -326,7 +337,7 @@ func RenderChartWithOptions(data []float64, width, height int, colors ChartColor
 		if len(timestamps) > len(trimmedData) {
 			timestamps = timestamps[len(timestamps)-len(trimmedData):]
 		}
-		axisLine := renderTimeAxis(timestamps, chartWidth, labelWidth, width, opts.TimeFormat, labelStyle)
+		axisLine := renderTimeAxis(timestamps, opts.Now, chartWidth, labelWidth, width, opts.TimeFormat, labelStyle)
 		lines = append(lines, axisLine)
 	}
 
@@ -357,9 +368,10 @@ func renderStatsTitle(data []float64, width int, colors ChartColors, opts ChartO
 	avg := sum / float64(len(data))
 
 	// Build the stats title: "  In: 1.2G  peak: 3.8G  avg: 1.1G"
-	titleParts := barStyle.Render("  "+opts.Label+": "+FormatRate(current)) +
-		labelStyle.Render("  peak: "+FormatRate(peak)) +
-		labelStyle.Render("  avg: "+FormatRate(avg))
+	format := opts.formatter()
+	titleParts := barStyle.Render("  "+opts.Label+": "+format(current)) +
+		labelStyle.Render("  peak: "+format(peak)) +
+		labelStyle.Render("  avg: "+format(avg))
 
 	// Pad to full width
 	titleWidth := lipgloss.Width(titleParts)
@@ -370,9 +382,12 @@ func renderStatsTitle(data []float64, width int, colors ChartColors, opts ChartO
 	return titleParts
 }
 
-// renderTimeAxis builds the time axis row with evenly-spaced time labels.
-func renderTimeAxis(timestamps []time.Time, chartWidth, labelWidth, totalWidth int, format string, labelStyle lipgloss.Style) string {
-	now := time.Now()
+// renderTimeAxis builds the time axis row with evenly-spaced time labels,
+// relative to now, or to the wall clock if now is zero.
+func renderTimeAxis(timestamps []time.Time, now time.Time, chartWidth, labelWidth, totalWidth int, format string, labelStyle lipgloss.Style) string {
+	if now.IsZero() {
+		now = time.Now()
+	}
 
 	// Determine number of labels based on chart width
 	numLabels := 4
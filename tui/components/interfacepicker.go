@@ -729,6 +729,22 @@ func (m InterfacePickerModel) renderDetailPanel(width int) []string {
 		lines = append(lines, row("MAC Address", iface.MACAddress))
 	}
 
+	// Link aggregate members
+	if len(iface.Members) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, sectionStyle.Render(" LAG Members"))
+		for _, idx := range iface.Members {
+			name := fmt.Sprintf("ifIndex %d", idx)
+			for _, other := range m.allInterfaces {
+				if other.IfIndex == idx {
+					name = other.Name
+					break
+				}
+			}
+			lines = append(lines, "   "+valStyle.Render(name))
+		}
+	}
+
 	// IP Addresses
 	if len(iface.IPAddresses) > 0 {
 		lines = append(lines, "")
@@ -61,3 +61,23 @@ func FormatRate(bps float64) string {
 		return fmt.Sprintf("%.0fb", bps)
 	}
 }
+
+// FormatCount formats a per-second event or packet count with a K/M/G suffix.
+// Values below 10 keep one decimal place so small but non-zero error rates
+// remain visible.
+func FormatCount(v float64) string {
+	switch {
+	case v == 0:
+		return "0"
+	case v >= 1_000_000_000:
+		return fmt.Sprintf("%.1fG", v/1_000_000_000)
+	case v >= 1_000_000:
+		return fmt.Sprintf("%.1fM", v/1_000_000)
+	case v >= 1_000:
+		return fmt.Sprintf("%.1fK", v/1_000)
+	case v >= 10:
+		return fmt.Sprintf("%.0f", v)
+	default:
+		return fmt.Sprintf("%.1f", v)
+	}
+}
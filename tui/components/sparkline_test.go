@@ -43,3 +43,23 @@ func TestFormatRate(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatCount(t *testing.T) {
+	tests := []struct {
+		v        float64
+		expected string
+	}{
+		{0, "0"},
+		{0.25, "0.2"},
+		{3.5, "3.5"},
+		{42, "42"},
+		{1500, "1.5K"},
+		{2_500_000, "2.5M"},
+	}
+	for _, tt := range tests {
+		got := FormatCount(tt.v)
+		if got != tt.expected {
+			t.Errorf("FormatCount(%f) = %q, want %q", tt.v, got, tt.expected)
+		}
+	}
+}
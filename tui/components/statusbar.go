@@ -17,8 +17,10 @@ type KeyHint struct {
 
 // RenderStatusBar renders the two-line status/footer bar showing poll info,
 // health status, and key bindings. The hints parameter controls which key
-// bindings are displayed, allowing per-view customization.
-func RenderStatusBar(theme styles.Theme, interval time.Duration, lastPoll time.Time, okCount, totalCount, width int, hints []KeyHint) string {
+// bindings are displayed, allowing per-view customization. A non-empty
+// notice, such as a dashboard file that failed to reload, is shown after
+// the health status, cut to fit.
+func RenderStatusBar(theme styles.Theme, interval time.Duration, lastPoll time.Time, okCount, totalCount, width int, notice string, hints []KeyHint) string {
 	bg := theme.Base01
 	bgStyle := lipgloss.NewStyle().Background(bg)
 	sep := lipgloss.NewStyle().Foreground(theme.Base03).Background(bg).Render(" | ")
@@ -38,6 +40,13 @@ func RenderStatusBar(theme styles.Theme, interval time.Duration, lastPoll time.T
 		Render(fmt.Sprintf("%d/%d OK", okCount, totalCount))
 
 	topContent := bgStyle.Render(" ") + pollSeg + sep + lastSeg + sep + healthSeg
+	if notice != "" {
+		topContent += sep
+		if room := width - lipgloss.Width(topContent) - 1; room > 0 {
+			topContent += lipgloss.NewStyle().Foreground(theme.Base08).Background(bg).
+				MaxWidth(room).Render(notice)
+		}
+	}
 	topWidth := lipgloss.Width(topContent)
 	if topWidth < width {
 		topContent += bgStyle.Render(strings.Repeat(" ", width-topWidth))
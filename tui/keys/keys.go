@@ -13,7 +13,10 @@ type KeyMap struct {
 	Identity  key.Binding
 	Edit      key.Binding
 	Settings  key.Binding
+	Chassis   key.Binding
 	Refresh   key.Binding
+	Record    key.Binding
+	Range     key.Binding
 	Help      key.Binding
 	Left      key.Binding
 	Right     key.Binding
@@ -31,7 +34,10 @@ var DefaultKeyMap = KeyMap{
 	Identity:  key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "identities")),
 	Edit:      key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit")),
 	Settings:  key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "settings")),
+	Chassis:   key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "device")),
 	Refresh:   key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
+	Record:    key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "record")),
+	Range:     key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "chart range")),
 	Help:      key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
 	Left:      key.NewBinding(key.WithKeys("left"), key.WithHelp("left", "left")),
 	Right:     key.NewBinding(key.WithKeys("right"), key.WithHelp("right", "right")),
@@ -70,8 +70,10 @@ type BuilderView struct {
 	SavedPath string // path to the saved TOML file after save
 	err       string
 
-	editMode bool   // true when editing an existing dashboard
-	editPath string // file path to overwrite in edit mode
+	editMode   bool                  // true when editing an existing dashboard
+	editPath   string                // file path to overwrite in edit mode
+	columns    []string              // optional columns carried over in edit mode
+	aggregates []dashboard.Aggregate // aggregate rows carried over in edit mode
 
 	// Identity picker overlay
 	showPicker   bool
@@ -167,6 +169,8 @@ func (b *BuilderView) SetSize(width, height int) {
 func (b *BuilderView) LoadDashboard(dash *dashboard.Dashboard, path string) {
 	b.editMode = true
 	b.editPath = path
+	b.columns = dash.Columns
+	b.aggregates = dash.Aggregates
 
 	// Step 1 fields
 	b.nameInput.SetValue(dash.Name)
@@ -174,12 +178,7 @@ func (b *BuilderView) LoadDashboard(dash *dashboard.Dashboard, path string) {
 	b.intervalInput.SetValue(dash.Interval.String())
 
 	// Step 2: flatten all groups' targets
-	b.targets = nil
-	for _, g := range dash.Groups {
-		for _, t := range g.Targets {
-			b.targets = append(b.targets, t)
-		}
-	}
+	b.targets = dash.FlatTargets()
 
 	// If there are targets, start at target list (not adding mode)
 	if len(b.targets) > 0 {
@@ -471,6 +470,10 @@ func (b *BuilderView) commitCurrentTarget() bool {
 	}
 
 	if b.editingIndex >= 0 {
+		// Poll overrides and custom OIDs are not editable here; keep any
+		// set in the TOML.
+		t.PollOptions = b.targets[b.editingIndex].PollOptions
+		t.OIDs = b.targets[b.editingIndex].OIDs
 		b.targets[b.editingIndex] = t
 	} else {
 		b.targets = append(b.targets, t)
@@ -971,12 +974,14 @@ func (b BuilderView) saveDashboard() (BuilderView, tea.Cmd, BuilderAction) {
 		DefaultIdentity: defaultIdentity,
 		Interval:        interval,
 		MaxHistory:      360,
+		Columns:         b.columns,
 		Groups: []dashboard.Group{
 			{
 				Name:    "Default",
 				Targets: targets,
 			},
 		},
+		Aggregates: b.aggregates,
 	}
 
 	var path string
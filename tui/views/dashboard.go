@@ -2,12 +2,15 @@ package views
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/tonhe/flo/internal/dashboard"
 	"github.com/tonhe/flo/internal/engine"
 	"github.com/tonhe/flo/tui/components"
 	"github.com/tonhe/flo/tui/keys"
@@ -22,6 +25,7 @@ const (
 	colIn        = 10
 	colOut       = 10
 	colUtil      = 8
+	colOptional  = 10
 	colSparkMin  = 12
 )
 
@@ -43,6 +47,7 @@ type DashboardView struct {
 	totalRows  int
 	offset     int // scroll offset for vertical scrolling
 	timeFormat string
+	expanded   map[string]bool // link aggregates showing their members, by lagKey
 }
 
 // NewDashboardView creates a new DashboardView with the given theme.
@@ -51,6 +56,7 @@ func NewDashboardView(theme styles.Theme) DashboardView {
 		theme:      theme,
 		sty:        styles.NewStyles(theme),
 		timeFormat: "relative",
+		expanded:   make(map[string]bool),
 	}
 }
 
@@ -74,27 +80,187 @@ func (v DashboardView) Update(msg tea.Msg) (DashboardView, tea.Cmd) {
 				v.cursor++
 				v.ensureVisible()
 			}
+		case key.Matches(msg, keys.DefaultKeyMap.Right):
+			v.setExpanded(true)
+		case key.Matches(msg, keys.DefaultKeyMap.Left):
+			v.setExpanded(false)
 		}
 	}
 	return v, nil
 }
 
+// setExpanded expands or collapses the link aggregate at the cursor. On a
+// member row, collapsing moves the cursor back to the aggregate.
+func (v *DashboardView) setExpanded(expand bool) {
+	t, i := v.rowAt(v.cursor)
+	if t == nil || i < 0 {
+		return
+	}
+	rows := v.interfaceRows(*t)
+	if i >= len(rows) {
+		return
+	}
+	r := rows[i]
+	switch {
+	case r.parent != nil && !expand:
+		for rows[i].parent != nil {
+			i--
+			v.cursor--
+		}
+		delete(v.expanded, lagKey(t.Host, r.parent.Name))
+	case r.parent == nil && len(r.iface.Members) > 0:
+		if expand {
+			v.expanded[lagKey(t.Host, r.iface.Name)] = true
+		} else {
+			delete(v.expanded, lagKey(t.Host, r.iface.Name))
+		}
+	default:
+		return
+	}
+	v.countRows()
+	v.ensureVisible()
+}
+
+// lagKey identifies a link aggregate in DashboardView.expanded.
+func lagKey(host, name string) string {
+	return host + "/" + name
+}
+
+// interfaceRow is an interface table row: a configured interface, or the
+// member of an expanded link aggregate.
+type interfaceRow struct {
+	iface  *engine.InterfaceStats
+	parent *engine.InterfaceStats // the aggregate, for member rows
+}
+
+// interfaceRows returns a target's interface rows in display order, with
+// the members of expanded link aggregates below them.
+func (v DashboardView) interfaceRows(t engine.TargetStats) []interfaceRow {
+	rows := make([]interfaceRow, 0, len(t.Interfaces))
+	for i := range t.Interfaces {
+		iface := &t.Interfaces[i]
+		rows = append(rows, interfaceRow{iface: iface})
+		if v.expanded[lagKey(t.Host, iface.Name)] {
+			for j := range iface.Members {
+				rows = append(rows, interfaceRow{iface: &iface.Members[j], parent: iface})
+			}
+		}
+	}
+	return rows
+}
+
 // SetSnapshot updates the dashboard data. It recalculates the total row count
-// and clamps the cursor if needed.
+// and keeps the cursor on the same row when rows above it collapse or
+// expand, clamping it if needed.
 func (v *DashboardView) SetSnapshot(snap *engine.DashboardSnapshot) {
-	v.snapshot = snap
+	host, sub := "", 0
+	if t, i := v.rowAt(v.cursor); t != nil {
+		host, sub = t.Host, i
+	}
+
+	v.snapshot = withAggregates(snap)
+	v.countRows()
+	if host != "" {
+		if row, ok := v.rowIndex(host, sub); ok {
+			v.cursor = row
+		}
+	}
+	if v.cursor >= v.totalRows && v.totalRows > 0 {
+		v.cursor = v.totalRows - 1
+	}
+}
+
+// aggregateLabel is shown in the device column of aggregate rows.
+const aggregateLabel = "aggregate"
+
+// withAggregates returns the snapshot with its aggregate rows appended as a
+// final group holding a single host-less target, so that they are navigated,
+// rendered and graphed like any other interface.
+func withAggregates(snap *engine.DashboardSnapshot) *engine.DashboardSnapshot {
+	if snap == nil || len(snap.Aggregates) == 0 {
+		return snap
+	}
+	s := *snap
+	s.Groups = append(slices.Clip(snap.Groups), engine.GroupSnapshot{
+		Name:    "Aggregates",
+		Targets: []engine.TargetStats{{Label: aggregateLabel, Interfaces: snap.Aggregates}},
+	})
+	return &s
+}
+
+// countRows recalculates the total number of selectable rows.
+func (v *DashboardView) countRows() {
 	total := 0
-	if snap != nil {
-		for _, g := range snap.Groups {
+	if v.snapshot != nil {
+		for _, g := range v.snapshot.Groups {
 			for _, t := range g.Targets {
-				total += len(t.Interfaces)
+				total += v.targetRowCount(t)
 			}
 		}
 	}
 	v.totalRows = total
-	if v.cursor >= v.totalRows && v.totalRows > 0 {
-		v.cursor = v.totalRows - 1
+}
+
+// collapsed reports whether a target is shown as a single summary row rather
+// than one row per interface. Unreachable targets are collapsed so their
+// stale interface rows don't fill the table.
+func collapsed(t engine.TargetStats) bool {
+	return t.Health == engine.TargetUnreachable
+}
+
+// targetRowCount returns the number of table rows a target occupies: one
+// per interface and expanded LAG member, followed by one per custom OID.
+func (v DashboardView) targetRowCount(t engine.TargetStats) int {
+	if collapsed(t) {
+		return 1
 	}
+	return len(v.interfaceRows(t)) + len(t.Metrics)
+}
+
+// rowAt returns the target shown at a flat row index and the index of the
+// row within it (interface rows first, then custom OIDs), or -1 for a collapsed
+// target's summary row.
+func (v DashboardView) rowAt(row int) (*engine.TargetStats, int) {
+	if v.snapshot == nil {
+		return nil, 0
+	}
+	idx := 0
+	for gi := range v.snapshot.Groups {
+		for ti := range v.snapshot.Groups[gi].Targets {
+			t := &v.snapshot.Groups[gi].Targets[ti]
+			n := v.targetRowCount(*t)
+			if row < idx+n {
+				if collapsed(*t) {
+					return t, -1
+				}
+				return t, row - idx
+			}
+			idx += n
+		}
+	}
+	return nil, 0
+}
+
+// rowIndex returns the flat row index showing the given row of a target, or
+// the target's summary row when it is collapsed.
+func (v DashboardView) rowIndex(host string, sub int) (int, bool) {
+	if v.snapshot == nil {
+		return 0, false
+	}
+	idx := 0
+	for _, g := range v.snapshot.Groups {
+		for _, t := range g.Targets {
+			if t.Host == host {
+				if collapsed(t) {
+					return idx, true
+				}
+				n := v.targetRowCount(t)
+				return idx + min(max(sub, 0), n-1), n > 0
+			}
+			idx += v.targetRowCount(t)
+		}
+	}
+	return 0, false
 }
 
 // SetSize updates the available dimensions for the view.
@@ -103,29 +269,58 @@ func (v *DashboardView) SetSize(width, height int) {
 	v.height = height
 }
 
-// Cursor returns the current cursor position (flat interface index).
+// Cursor returns the current cursor position (flat row index).
 func (v DashboardView) Cursor() int {
 	return v.cursor
 }
 
 // SelectedInterface returns the target label and InterfaceStats at the current
-// cursor position, or empty values if nothing is selected.
+// cursor position, or empty values if nothing is selected. On a collapsed
+// target's summary row or a custom OID row only the label is returned.
 func (v DashboardView) SelectedInterface() (label string, iface *engine.InterfaceStats) {
-	if v.snapshot == nil {
+	t, i := v.rowAt(v.cursor)
+	if t == nil {
 		return "", nil
 	}
-	idx := 0
-	for _, g := range v.snapshot.Groups {
-		for _, t := range g.Targets {
-			for i := range t.Interfaces {
-				if idx == v.cursor {
-					return t.Label, &t.Interfaces[i]
-				}
-				idx++
-			}
-		}
+	rows := v.interfaceRows(*t)
+	if i < 0 || i >= len(rows) {
+		return t.Label, nil
 	}
-	return "", nil
+	return t.Label, rows[i].iface
+}
+
+// SelectedMetric returns the custom OID at the current cursor position, or
+// nil if the cursor is not on a custom OID row.
+func (v DashboardView) SelectedMetric() *engine.MetricStats {
+	t, i := v.rowAt(v.cursor)
+	if t == nil {
+		return nil
+	}
+	n := len(v.interfaceRows(*t))
+	if i < n {
+		return nil
+	}
+	return &t.Metrics[i-n]
+}
+
+// SelectedTarget returns the target at the current cursor position, or nil
+// if nothing or an aggregate row is selected.
+func (v DashboardView) SelectedTarget() *engine.TargetStats {
+	t, _ := v.rowAt(v.cursor)
+	if t == nil || t.Host == "" {
+		return nil
+	}
+	return t
+}
+
+// SelectedDevice returns the device health of the target at the current
+// cursor position, or nil if the target reports none.
+func (v DashboardView) SelectedDevice() *engine.DeviceStats {
+	t, _ := v.rowAt(v.cursor)
+	if t == nil || !t.Device.Available() {
+		return nil
+	}
+	return &t.Device
 }
 
 // View renders the dashboard view with an optional graph panel below the table.
@@ -207,6 +402,7 @@ func (v DashboardView) columnWidths() (device, iface, status, inCol, outCol, uti
 	util = colUtil
 
 	fixed := device + iface + status + inCol + outCol + util
+	fixed += len(v.optionalColumns()) * colOptional
 	spark = v.width - fixed
 	if spark < colSparkMin {
 		spark = colSparkMin
@@ -214,6 +410,37 @@ func (v DashboardView) columnWidths() (device, iface, status, inCol, outCol, uti
 	return
 }
 
+// optionalColumns returns the optional columns enabled for the current
+// dashboard, in configured order, skipping names the view does not know.
+func (v DashboardView) optionalColumns() []string {
+	if v.snapshot == nil {
+		return nil
+	}
+	var cols []string
+	for _, c := range v.snapshot.Columns {
+		switch c {
+		case dashboard.ColumnErrors, dashboard.ColumnDiscards, dashboard.ColumnPPS, dashboard.ColumnFlaps:
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
+// optionalColumnTitle returns the table header for an optional column.
+func optionalColumnTitle(col string) string {
+	switch col {
+	case dashboard.ColumnErrors:
+		return "Err/s"
+	case dashboard.ColumnDiscards:
+		return "Disc/s"
+	case dashboard.ColumnPPS:
+		return "PPS"
+	case dashboard.ColumnFlaps:
+		return "Flaps"
+	}
+	return col
+}
+
 // renderTableWithHeight renders the full dashboard table with group headers and
 // interface rows, constrained to the given height.
 func (v DashboardView) renderTableWithHeight(tableHeight int) string {
@@ -223,14 +450,19 @@ func (v DashboardView) renderTableWithHeight(tableHeight int) string {
 
 	// Table header row
 	headerStyle := v.sty.TableHeader
+	var optHeader string
+	for _, col := range v.optionalColumns() {
+		optHeader += headerStyle.Render(padLeft(optionalColumnTitle(col), colOptional))
+	}
 	header := fmt.Sprintf(
-		"%s%s%s%s%s%s%s",
+		"%s%s%s%s%s%s%s%s",
 		headerStyle.Render(padRight("Device", wDevice)),
 		headerStyle.Render(padRight("Interface", wIface)),
 		headerStyle.Render(padRight("Status", wStatus)),
 		headerStyle.Render(padLeft("In", wIn)),
 		headerStyle.Render(padLeft("Out", wOut)),
 		headerStyle.Render(padLeft("Util", wUtil)),
+		optHeader,
 		headerStyle.Render(padRight("Trend", wSpark)),
 	)
 	lines = append(lines, header)
@@ -252,9 +484,26 @@ func (v DashboardView) renderTableWithHeight(tableHeight int) string {
 		rows = append(rows, row{isGroup: true, text: groupLine})
 
 		for _, t := range g.Targets {
-			for _, iface := range t.Interfaces {
+			if collapsed(t) {
+				rows = append(rows, row{isGroup: false, text: v.renderUnreachableRow(t, wDevice, rowIdx == v.cursor)})
+				rowIdx++
+				continue
+			}
+			if t.Device.Available() {
+				rows = append(rows, row{isGroup: true, text: v.renderDeviceRow(t, wDevice)})
+			}
+			for _, r := range v.interfaceRows(t) {
 				rowText := v.renderInterfaceRow(
-					t.Label, iface,
+					t.Label, t.Health, *r.iface, v.rowLAGState(t.Host, r),
+					wDevice, wIface, wStatus, wIn, wOut, wUtil, wSpark,
+					rowIdx == v.cursor,
+				)
+				rows = append(rows, row{isGroup: false, text: rowText})
+				rowIdx++
+			}
+			for _, m := range t.Metrics {
+				rowText := v.renderMetricRow(
+					t.Label, t.Health, m,
 					wDevice, wIface, wStatus, wIn, wOut, wUtil, wSpark,
 					rowIdx == v.cursor,
 				)
@@ -319,7 +568,9 @@ func (v DashboardView) renderTableWithHeight(tableHeight int) string {
 // renderInterfaceRow renders a single interface metrics row.
 func (v DashboardView) renderInterfaceRow(
 	deviceLabel string,
+	health engine.TargetHealth,
 	iface engine.InterfaceStats,
+	lag lagState,
 	wDevice, wIface, wStatus, wIn, wOut, wUtil, wSpark int,
 	selected bool,
 ) string {
@@ -330,35 +581,78 @@ func (v DashboardView) renderInterfaceRow(
 		rowStyle = v.sty.TableRowSel
 	}
 
-	// Device label with cursor indicator
+	// Device label with cursor indicator; degraded targets are highlighted
+	deviceStyle := rowStyle
+	if health == engine.TargetDegraded {
+		deviceStyle = v.sty.StatusWarn
+		if selected {
+			deviceStyle = deviceStyle.Background(selBg)
+		}
+	}
 	var device string
 	if selected {
 		indicator := lipgloss.NewStyle().Foreground(v.theme.Base0D).Background(selBg).Render("▸")
-		device = indicator + rowStyle.Render(padRight(truncate(deviceLabel, wDevice-2), wDevice-1))
+		device = indicator + deviceStyle.Render(padRight(truncate(deviceLabel, wDevice-2), wDevice-1))
 	} else {
-		device = rowStyle.Render(padRight(" "+truncate(deviceLabel, wDevice-2), wDevice))
+		device = deviceStyle.Render(padRight(" "+truncate(deviceLabel, wDevice-2), wDevice))
 	}
 
-	// Interface name
-	ifName := rowStyle.Render(padRight(truncate(iface.Name, wIface-1), wIface))
+	// Interface name, with the expand marker and imbalance of an aggregate
+	// and member rows indented below it
+	var prefix, suffix string
+	suffixStyle := v.sty.TableCellDim
+	switch lag {
+	case lagCollapsed:
+		prefix = "+"
+	case lagExpanded:
+		prefix = "-"
+	case lagMember:
+		prefix = "  "
+	}
+	if lag == lagCollapsed || lag == lagExpanded {
+		if pct, ok := iface.Imbalance(); ok {
+			suffix = fmt.Sprintf(" ±%.0f%%", pct)
+			if pct >= lagImbalanceWarn {
+				suffixStyle = v.sty.StatusWarn
+			}
+		}
+	}
+	if selected {
+		suffixStyle = suffixStyle.Background(selBg)
+	}
+	nameText := truncate(prefix+iface.Name, wIface-1-lipgloss.Width(suffix))
+	ifName := rowStyle.Render(nameText) + suffixStyle.Render(suffix) +
+		rowStyle.Render(strings.Repeat(" ", max(wIface-len(nameText)-lipgloss.Width(suffix), 0)))
 
 	// Status with color
-	notPolled := iface.Status == ""
+	notPolled := iface.Status == "" || iface.NotFound
 	var statusStr string
-	switch iface.Status {
-	case "":
+	switch {
+	case iface.NotFound:
+		st := v.sty.StatusDown
+		if selected {
+			st = st.Background(selBg)
+		}
+		statusStr = st.Render(padRight("missing", wStatus))
+	case iface.Status == "":
 		st := lipgloss.NewStyle().Foreground(v.theme.Base04)
 		if selected {
 			st = st.Background(selBg)
 		}
 		statusStr = st.Render(padRight("...", wStatus))
-	case "up":
+	case iface.Flapping:
+		st := v.sty.StatusWarn
+		if selected {
+			st = st.Background(selBg)
+		}
+		statusStr = st.Render(padRight(fmt.Sprintf("%s %dx", iface.Status, iface.Flaps), wStatus))
+	case iface.Status == "up":
 		st := v.sty.StatusUp
 		if selected {
 			st = st.Background(selBg)
 		}
 		statusStr = st.Render(padRight("up", wStatus))
-	case "down":
+	case iface.Status == "down":
 		st := v.sty.StatusDown
 		if selected {
 			st = st.Background(selBg)
@@ -410,6 +704,56 @@ func (v DashboardView) renderInterfaceRow(
 		}
 	}
 
+	// Optional columns; errors and discards are highlighted when non-zero
+	var optStr string
+	for _, col := range v.optionalColumns() {
+		var value float64
+		switch col {
+		case dashboard.ColumnPPS:
+			value = iface.InPPS.Total() + iface.OutPPS.Total()
+			if notPolled {
+				optStr += rowStyle.Render(padLeft("---", colOptional))
+			} else {
+				optStr += rowStyle.Render(padLeft(components.FormatCount(value), colOptional))
+			}
+			continue
+		case dashboard.ColumnFlaps:
+			// Flap count within the window and the age of the last change
+			text := "---"
+			if !notPolled {
+				text = strconv.Itoa(iface.Flaps)
+				if !iface.LastChange.IsZero() {
+					text += " " + formatAge(v.snapshot.Now().Sub(iface.LastChange))
+				}
+			}
+			st := rowStyle
+			if iface.Flapping {
+				st = v.sty.StatusWarn
+				if selected {
+					st = st.Background(selBg)
+				}
+			}
+			optStr += st.Render(padLeft(text, colOptional))
+			continue
+		case dashboard.ColumnErrors:
+			value = iface.InErrors + iface.OutErrors
+		case dashboard.ColumnDiscards:
+			value = iface.InDiscards + iface.OutDiscards
+		}
+		switch {
+		case notPolled:
+			optStr += rowStyle.Render(padLeft("---", colOptional))
+		case value > 0:
+			st := v.sty.StatusWarn
+			if selected {
+				st = st.Background(selBg)
+			}
+			optStr += st.Render(padLeft(components.FormatCount(value), colOptional))
+		default:
+			optStr += rowStyle.Render(padLeft("0", colOptional))
+		}
+	}
+
 	// Sparkline from history
 	sparkData := extractSparkData(iface.History, wSpark)
 	sparkStr := components.Sparkline(sparkData, wSpark)
@@ -419,13 +763,254 @@ func (v DashboardView) renderInterfaceRow(
 	}
 	sparkRendered := sparkStyle.Render(sparkStr)
 
-	return fmt.Sprintf("%s%s%s%s%s%s%s",
-		device, ifName, statusStr, inStr, outStr, utilStr, sparkRendered,
+	return fmt.Sprintf("%s%s%s%s%s%s%s%s",
+		device, ifName, statusStr, inStr, outStr, utilStr, optStr, sparkRendered,
 	)
 }
 
-// renderGraphPanel renders the In/Out traffic charts for the selected interface.
+// lagState describes how an interface row relates to link aggregation.
+type lagState int
+
+const (
+	lagNone      lagState = iota // not an aggregate
+	lagCollapsed                 // an aggregate with its members hidden
+	lagExpanded                  // an aggregate with its members shown
+	lagMember                    // a member of an expanded aggregate
+)
+
+// lagImbalanceWarn is the member imbalance, in percent, from which an
+// aggregate's imbalance is highlighted.
+const lagImbalanceWarn = 25
+
+// rowLAGState returns the aggregation state of an interface row.
+func (v DashboardView) rowLAGState(host string, r interfaceRow) lagState {
+	switch {
+	case r.parent != nil:
+		return lagMember
+	case len(r.iface.Members) == 0:
+		return lagNone
+	case v.expanded[lagKey(host, r.iface.Name)]:
+		return lagExpanded
+	}
+	return lagCollapsed
+}
+
+// renderMetricRow renders a custom OID row. The value spans the In and Out
+// columns; the Util and optional columns are left blank.
+func (v DashboardView) renderMetricRow(
+	deviceLabel string,
+	health engine.TargetHealth,
+	m engine.MetricStats,
+	wDevice, wIface, wStatus, wIn, wOut, wUtil, wSpark int,
+	selected bool,
+) string {
+	rowStyle := v.sty.TableRow
+	selBg := v.theme.Base01
+	if selected {
+		rowStyle = v.sty.TableRowSel
+	}
+
+	deviceStyle := rowStyle
+	if health == engine.TargetDegraded {
+		deviceStyle = v.sty.StatusWarn
+		if selected {
+			deviceStyle = deviceStyle.Background(selBg)
+		}
+	}
+	var device string
+	if selected {
+		indicator := lipgloss.NewStyle().Foreground(v.theme.Base0D).Background(selBg).Render("▸")
+		device = indicator + deviceStyle.Render(padRight(truncate(deviceLabel, wDevice-2), wDevice-1))
+	} else {
+		device = deviceStyle.Render(padRight(" "+truncate(deviceLabel, wDevice-2), wDevice))
+	}
+
+	label := rowStyle.Render(padRight(truncate(m.Label, wIface-1), wIface))
+
+	var statusStr, valueStr string
+	notPolled := m.LastPoll.IsZero()
+	switch {
+	case m.PollError != nil:
+		st := v.sty.StatusWarn
+		if selected {
+			st = st.Background(selBg)
+		}
+		statusStr = st.Render(padRight("error", wStatus))
+	case notPolled:
+		st := lipgloss.NewStyle().Foreground(v.theme.Base04)
+		if selected {
+			st = st.Background(selBg)
+		}
+		statusStr = st.Render(padRight("...", wStatus))
+	default:
+		st := v.sty.StatusUp
+		if selected {
+			st = st.Background(selBg)
+		}
+		statusStr = st.Render(padRight(m.Kind, wStatus))
+	}
+	if notPolled || m.History.Len() == 0 {
+		valueStr = rowStyle.Render(padLeft("---", wIn+wOut))
+	} else {
+		valueStr = rowStyle.Render(padLeft(truncate(formatMetric(m), wIn+wOut-1), wIn+wOut))
+	}
+
+	blank := rowStyle.Render(strings.Repeat(" ", wUtil+len(v.optionalColumns())*colOptional))
+
+	sparkStyle := v.sty.SparklineStyle
+	if selected {
+		sparkStyle = sparkStyle.Background(selBg)
+	}
+	spark := sparkStyle.Render(components.Sparkline(extractMetricSparkData(m.History, wSpark), wSpark))
+
+	return device + label + statusStr + valueStr + blank + spark
+}
+
+// formatMetric formats a custom OID's current value with its unit. Counter
+// values are rates and get a "/s" suffix.
+func formatMetric(m engine.MetricStats) string {
+	s := components.FormatCount(m.Value)
+	if m.Unit != "" {
+		s += " " + m.Unit
+	}
+	if m.Kind == dashboard.OIDKindCounter {
+		s += "/s"
+	}
+	return s
+}
+
+// renderDeviceRow renders a target's CPU, memory and storage summary on a
+// line above its interfaces. Percentages use the Util column's thresholds;
+// segments that do not fit the width are dropped.
+func (v DashboardView) renderDeviceRow(t engine.TargetStats, wDevice int) string {
+	dim := v.sty.TableCellDim
+	d := t.Device
+
+	type segment struct {
+		label string
+		pct   float64
+		extra string
+	}
+	var segs []segment
+	if d.HasCPU {
+		segs = append(segs, segment{"CPU", d.CPU, ""})
+	}
+	if d.HasMemory {
+		var extra string
+		if d.MemTotal > 0 {
+			extra = fmt.Sprintf("of %s", formatBytes(d.MemTotal))
+		}
+		segs = append(segs, segment{"Mem", d.Memory, extra})
+	}
+	for _, s := range d.Storage {
+		segs = append(segs, segment{truncate(s.Descr, 20), s.Percent(), ""})
+	}
+
+	line := dim.Render(padRight(" "+truncate(t.Label, wDevice-2), wDevice))
+	width := wDevice
+	for _, seg := range segs {
+		pct := fmt.Sprintf("%.0f%%", seg.pct)
+		w := len(seg.label) + 1 + len(pct) + 3
+		if seg.extra != "" {
+			w += 1 + len(seg.extra)
+		}
+		if width+w > v.width {
+			break
+		}
+		line += dim.Render(seg.label+" ") + v.utilStyle(seg.pct).Render(pct)
+		if seg.extra != "" {
+			line += dim.Render(" " + seg.extra)
+		}
+		line += dim.Render("   ")
+		width += w
+	}
+	if d.PollError != nil && width+6 <= v.width {
+		line += v.sty.StatusWarn.Render("stale")
+		width += 5
+	}
+	return line + dim.Render(strings.Repeat(" ", max(v.width-width, 0)))
+}
+
+// utilStyle returns the style for a utilization percentage.
+func (v DashboardView) utilStyle(pct float64) lipgloss.Style {
+	switch {
+	case pct >= 80:
+		return v.sty.UtilHigh
+	case pct >= 50:
+		return v.sty.UtilMid
+	default:
+		return v.sty.UtilLow
+	}
+}
+
+// formatBytes formats a byte count with a binary K/M/G/T suffix.
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+	v := float64(b)
+	for _, suffix := range []string{"K", "M", "G", "T"} {
+		v /= unit
+		if v < unit || suffix == "T" {
+			return fmt.Sprintf("%.1f%s", v, suffix)
+		}
+	}
+	return ""
+}
+
+// formatAge formats a duration compactly in its largest whole unit, e.g.
+// "45s", "12m", "3h" or "2d".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", max(int(d.Seconds()), 0))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+// renderUnreachableRow renders the single summary row shown in place of an
+// unreachable target's interfaces.
+func (v DashboardView) renderUnreachableRow(t engine.TargetStats, wDevice int, selected bool) string {
+	rowStyle := v.sty.TableRow
+	st := v.sty.StatusDown
+	if selected {
+		rowStyle = v.sty.TableRowSel
+		st = st.Background(v.theme.Base01)
+	}
+
+	var device string
+	if selected {
+		indicator := lipgloss.NewStyle().Foreground(v.theme.Base0D).Background(v.theme.Base01).Render("▸")
+		device = indicator + rowStyle.Render(padRight(truncate(t.Label, wDevice-2), wDevice-1))
+	} else {
+		device = rowStyle.Render(padRight(" "+truncate(t.Label, wDevice-2), wDevice))
+	}
+
+	msg := fmt.Sprintf("unreachable since %s (%d interfaces)", t.UnreachableSince.Format("15:04"), len(t.Interfaces))
+	if t.PollError != nil {
+		msg += ": " + t.PollError.Error()
+	}
+	if wait := time.Until(t.NextAttempt); wait > 0 {
+		msg += fmt.Sprintf(", retry in %s", wait.Round(time.Second))
+	}
+	rest := v.width - wDevice
+	if rest < 1 {
+		rest = 1
+	}
+	return device + st.Render(padRight(truncate(msg, rest-1), rest))
+}
+
+// renderGraphPanel renders the In/Out traffic charts for the selected
+// interface, or a single chart for a selected custom OID.
 func (v DashboardView) renderGraphPanel(panelHeight int) string {
+	if m := v.SelectedMetric(); m != nil {
+		return v.renderMetricGraph(m, panelHeight)
+	}
 	_, iface := v.SelectedInterface()
 	if iface == nil || iface.History == nil {
 		emptyStyle := lipgloss.NewStyle().
@@ -477,11 +1062,13 @@ func (v DashboardView) renderGraphPanel(panelHeight int) string {
 	inOpts := components.ChartOptions{
 		Timestamps: timestamps,
 		TimeFormat: v.timeFormat,
+		Now:        v.snapshot.Now(),
 		Label:      "In",
 	}
 	outOpts := components.ChartOptions{
 		Timestamps: timestamps,
 		TimeFormat: v.timeFormat,
+		Now:        v.snapshot.Now(),
 		Label:      "Out",
 	}
 
@@ -498,6 +1085,43 @@ func (v DashboardView) renderGraphPanel(panelHeight int) string {
 	return lipgloss.JoinHorizontal(lipgloss.Top, inChart, sep, outChart)
 }
 
+// renderMetricGraph renders a full-width chart of a custom OID's history.
+func (v DashboardView) renderMetricGraph(m *engine.MetricStats, panelHeight int) string {
+	samples := m.History.All()
+	if len(samples) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Foreground(v.theme.Base04).
+			Background(v.theme.Base00)
+		msg := emptyStyle.Render("Waiting for data...")
+		return lipgloss.Place(v.width, panelHeight, lipgloss.Center, lipgloss.Center, msg,
+			lipgloss.WithWhitespaceBackground(v.theme.Base00))
+	}
+
+	data := make([]float64, len(samples))
+	timestamps := make([]time.Time, len(samples))
+	for i, s := range samples {
+		data[i] = s.Value
+		timestamps[i] = s.Timestamp
+	}
+
+	colors := components.ChartColors{
+		BarFg:   v.theme.Base0E,
+		LabelFg: v.theme.Base04,
+		TitleFg: v.theme.Base0D,
+		Bg:      v.theme.Base00,
+	}
+	opts := components.ChartOptions{
+		Timestamps: timestamps,
+		TimeFormat: v.timeFormat,
+		Now:        v.snapshot.Now(),
+		Label:      m.Label,
+		Format: func(f float64) string {
+			return formatMetric(engine.MetricStats{Value: f, Unit: m.Unit, Kind: m.Kind})
+		},
+	}
+	return components.RenderChartWithOptions(data, v.width, panelHeight, colors, opts)
+}
+
 // renderEmpty renders a centered message when no dashboard is loaded.
 func (v DashboardView) renderEmpty() string {
 	msgStyle := lipgloss.NewStyle().
@@ -550,6 +1174,23 @@ func extractSparkData(history *engine.RingBuffer[engine.RateSample], maxWidth in
 	return data
 }
 
+// extractMetricSparkData returns the most recent custom OID values that fit
+// in a sparkline of the given width.
+func extractMetricSparkData(history *engine.RingBuffer[engine.MetricSample], maxWidth int) []float64 {
+	if history == nil {
+		return nil
+	}
+	samples := history.All()
+	if len(samples) > maxWidth {
+		samples = samples[len(samples)-maxWidth:]
+	}
+	data := make([]float64, len(samples))
+	for i, s := range samples {
+		data[i] = s.Value
+	}
+	return data
+}
+
 // padRight pads s with spaces on the right to the given width.
 func padRight(s string, width int) string {
 	if len(s) >= width {
@@ -9,11 +9,29 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/tonhe/flo/internal/engine"
+	"github.com/tonhe/flo/internal/history"
 	"github.com/tonhe/flo/tui/components"
 	"github.com/tonhe/flo/tui/keys"
 	"github.com/tonhe/flo/tui/styles"
 )
 
+// minDetailChartRows is the smallest chart height worth rendering; the
+// packet-rate and device CPU/memory charts are only shown when their rows
+// fit at this height.
+const minDetailChartRows = 6
+
+// infoColumnWidth is the width of each column in the detail info panel.
+const infoColumnWidth = 48
+
+// chartLabelWidth is the width of a chart's value labels, which leaves the
+// rest of the chart for data points.
+const chartLabelWidth = 8
+
+// chartRanges are the time spans the traffic charts cycle through. Zero
+// charts the raw samples as polled; longer spans chart the averages of the
+// interface's consolidated history.
+var chartRanges = []time.Duration{0, time.Hour, 6 * time.Hour, 24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour}
+
 // DetailView is a split-screen view showing interface information at the top
 // and In/Out traffic charts at the bottom.
 type DetailView struct {
@@ -21,9 +39,13 @@ type DetailView struct {
 	sty         *styles.Styles
 	targetLabel string
 	ifaceStats  *engine.InterfaceStats
+	device      *engine.DeviceStats
+	usage       []history.Usage // billing statistics; nil until read
+	rangeIdx    int             // index into chartRanges
 	width       int
 	height      int
 	timeFormat  string
+	now         time.Time // time of the data for a replay; zero for the wall clock
 }
 
 // NewDetailView creates a new DetailView with the given theme.
@@ -46,6 +68,32 @@ func (v *DetailView) SetInterface(label string, stats *engine.InterfaceStats) {
 	v.ifaceStats = stats
 }
 
+// SetNow sets the time the interface data is current as of, which ages and
+// chart ranges count back from. A zero time uses the wall clock.
+func (v *DetailView) SetNow(now time.Time) {
+	v.now = now
+}
+
+// clock returns the time ages and chart ranges count back from.
+func (v DetailView) clock() time.Time {
+	if v.now.IsZero() {
+		return time.Now()
+	}
+	return v.now
+}
+
+// SetDevice updates the CPU and memory of the interface's device, or clears
+// them when device is nil.
+func (v *DetailView) SetDevice(device *engine.DeviceStats) {
+	v.device = device
+}
+
+// SetUsage updates the interface's billing statistics, or clears them when
+// usage is nil.
+func (v *DetailView) SetUsage(usage []history.Usage) {
+	v.usage = usage
+}
+
 // SetSize updates the available dimensions for the view.
 func (v *DetailView) SetSize(width, height int) {
 	v.width = width
@@ -60,6 +108,8 @@ func (v DetailView) Update(msg tea.Msg) (DetailView, tea.Cmd, bool) {
 		switch {
 		case key.Matches(msg, keys.DefaultKeyMap.Escape):
 			return v, nil, true
+		case key.Matches(msg, keys.DefaultKeyMap.Range):
+			v.rangeIdx = (v.rangeIdx + 1) % len(chartRanges)
 		}
 	}
 	return v, nil, false
@@ -92,7 +142,7 @@ func (v DetailView) renderDetail() string {
 
 	// --- Bottom section: two charts side by side ---
 	// Calculate chart dimensions
-	infoPanelHeight := 10 // fixed info panel height
+	infoPanelHeight := lipgloss.Height(infoPanel) + 1 // panel + spacer line
 	chartHeight := v.height - infoPanelHeight
 	if chartHeight < 6 {
 		chartHeight = 6
@@ -102,9 +152,49 @@ func (v DetailView) renderDetail() string {
 		chartWidth = 15
 	}
 
-	// Extract rate data from history
-	inData, outData, timestamps := v.extractRateData()
+	// Extract rate data from the raw or consolidated history
+	var inData, outData, inPPS, outPPS []float64
+	var timestamps []time.Time
+	var resolution time.Duration
+	if chartRanges[v.rangeIdx] == 0 {
+		inData, outData, timestamps = v.extractRateData()
+		inPPS, outPPS = v.extractPacketData()
+	} else {
+		inData, outData, inPPS, outPPS, timestamps, resolution = v.extractRangeData(chartWidth - chartLabelWidth)
+	}
+
+	// Split the chart area into bps, pps and device CPU/memory rows, as
+	// many as fit
+	rowCount := 1
+	for _, want := range []bool{true, v.hasDeviceHistory()} {
+		if !want || chartHeight < (rowCount+1)*minDetailChartRows+rowCount {
+			break
+		}
+		rowCount++
+	}
+	rowHeight := (chartHeight - (rowCount - 1)) / rowCount
+	bpsHeight := chartHeight - (rowCount-1)*(rowHeight+1)
+
+	chartsSection := v.renderChartPair(inData, outData, timestamps, chartWidth, bpsHeight, "In", "Out", components.FormatRate)
+	if rowCount > 1 {
+		ppsSection := v.renderChartPair(inPPS, outPPS, timestamps, chartWidth, rowHeight, "In pps", "Out pps", components.FormatCount)
+		chartsSection = lipgloss.JoinVertical(lipgloss.Left, chartsSection, "", ppsSection)
+	}
+	if rowCount > 2 {
+		cpu, mem, deviceTimes := v.extractDeviceData()
+		deviceSection := v.renderChartPair(cpu, mem, deviceTimes, chartWidth, rowHeight, "CPU", "Memory", formatPercent)
+		chartsSection = lipgloss.JoinVertical(lipgloss.Left, chartsSection, "", deviceSection)
+	}
 
+	// Compose final layout: info panel on top, charts on bottom
+	helpLine := v.renderHelp(resolution)
+	full := lipgloss.JoinVertical(lipgloss.Left, infoPanel, "", chartsSection, helpLine)
+
+	return full
+}
+
+// renderChartPair renders matching In/Out charts side by side.
+func (v DetailView) renderChartPair(inData, outData []float64, timestamps []time.Time, chartWidth, chartHeight int, inLabel, outLabel string, format func(float64) string) string {
 	// Render charts with proper per-element coloring
 	inColors := components.ChartColors{
 		BarFg:   v.theme.Base0B, // green for in
@@ -121,12 +211,16 @@ func (v DetailView) renderDetail() string {
 	inOpts := components.ChartOptions{
 		Timestamps: timestamps,
 		TimeFormat: v.timeFormat,
-		Label:      "In",
+		Now:        v.clock(),
+		Label:      inLabel,
+		Format:     format,
 	}
 	outOpts := components.ChartOptions{
 		Timestamps: timestamps,
 		TimeFormat: v.timeFormat,
-		Label:      "Out",
+		Now:        v.clock(),
+		Label:      outLabel,
+		Format:     format,
 	}
 	inChart := components.RenderChartWithOptions(inData, chartWidth, chartHeight, inColors, inOpts)
 	outChart := components.RenderChartWithOptions(outData, chartWidth, chartHeight, outColors, outOpts)
@@ -138,13 +232,7 @@ func (v DetailView) renderDetail() string {
 		sepLines[i] = sepStyle.Render(" | ")
 	}
 	sep := strings.Join(sepLines, "\n")
-	chartsSection := lipgloss.JoinHorizontal(lipgloss.Top, inChart, sep, outChart)
-
-	// Compose final layout: info panel on top, charts on bottom
-	helpLine := v.renderHelp()
-	full := lipgloss.JoinVertical(lipgloss.Left, infoPanel, "", chartsSection, helpLine)
-
-	return full
+	return lipgloss.JoinHorizontal(lipgloss.Top, inChart, sep, outChart)
 }
 
 // renderInfoPanel renders the interface information section at the top.
@@ -171,6 +259,12 @@ func (v DetailView) renderInfoPanel(iface *engine.InterfaceStats) string {
 		statusStyle = lipgloss.NewStyle().Foreground(v.theme.Base08).Background(bg)
 	}
 
+	status := iface.Status
+	if iface.NotFound {
+		status = "not found on device"
+		statusStyle = lipgloss.NewStyle().Foreground(v.theme.Base08).Background(bg)
+	}
+
 	// Speed formatting
 	speedStr := formatSpeed(iface.Speed)
 
@@ -183,28 +277,355 @@ func (v DetailView) renderInfoPanel(iface *engine.InterfaceStats) string {
 		utilStyle = lipgloss.NewStyle().Foreground(v.theme.Base0A).Background(bg)
 	}
 
+	flapStyle := valueStyle
+	if iface.Flapping {
+		flapStyle = lipgloss.NewStyle().Foreground(v.theme.Base0A).Background(bg)
+	}
+
 	// Build info rows
 	pad := lipgloss.NewStyle().Background(bg).Render("  ")
 	rows := []string{
 		"",
 		pad + labelStyle.Render("Device:") + highlightStyle.Render(v.targetLabel),
 		pad + labelStyle.Render("Interface:") + highlightStyle.Render(iface.Name),
-		pad + labelStyle.Render("Description:") + valueStyle.Render(iface.Description),
-		pad + labelStyle.Render("Status:") + statusStyle.Render(iface.Status),
+		pad + labelStyle.Render("Description:") + valueStyle.Render(truncate(iface.Description, infoColumnWidth-18)),
+		pad + labelStyle.Render("Status:") + statusStyle.Render(status),
+		pad + labelStyle.Render("Last Change:") + valueStyle.Render(formatLastChange(iface.LastChange, v.clock())),
+		pad + labelStyle.Render("Flaps:") + flapStyle.Render(fmt.Sprintf("%d in %s", iface.Flaps, formatAge(engine.FlapWindow))),
 		pad + labelStyle.Render("Speed:") + valueStyle.Render(speedStr),
+		pad + labelStyle.Render("Counters:") + v.renderCounterBits(iface.CounterBits),
 		pad + labelStyle.Render("Current In:") + valueStyle.Render(components.FormatRate(iface.InRate)),
 		pad + labelStyle.Render("Current Out:") + valueStyle.Render(components.FormatRate(iface.OutRate)),
 		pad + labelStyle.Render("Utilization:") + utilStyle.Render(fmt.Sprintf("%.1f%%", iface.Utilization)),
 	}
 
-	return strings.Join(rows, "\n")
+	// Error and discard counters in a second column
+	warnStyle := lipgloss.NewStyle().Foreground(v.theme.Base0A).Background(bg)
+	errStyle := func(rate float64) lipgloss.Style {
+		if rate > 0 {
+			return warnStyle
+		}
+		return valueStyle
+	}
+	errValue := func(rate, pct float64) string {
+		return fmt.Sprintf("%s/s (%.2f%%)", components.FormatCount(rate), pct)
+	}
+	right := []string{
+		"",
+		labelStyle.Render("In PPS:") + valueStyle.Render(formatPacketRates(iface.InPPS)),
+		labelStyle.Render("Out PPS:") + valueStyle.Render(formatPacketRates(iface.OutPPS)),
+		labelStyle.Render("Avg Pkt Size:") + valueStyle.Render(fmt.Sprintf("%.0fB in / %.0fB out", iface.InAvgSize, iface.OutAvgSize)),
+		labelStyle.Render("In Errors:") + errStyle(iface.InErrors).Render(errValue(iface.InErrors, iface.InErrorPct)),
+		labelStyle.Render("Out Errors:") + errStyle(iface.OutErrors).Render(errValue(iface.OutErrors, iface.OutErrorPct)),
+		labelStyle.Render("In Discards:") + errStyle(iface.InDiscards).Render(components.FormatCount(iface.InDiscards)+"/s"),
+		labelStyle.Render("Out Discards:") + errStyle(iface.OutDiscards).Render(components.FormatCount(iface.OutDiscards)+"/s"),
+		labelStyle.Render("Counter Reset:") + errStyle(float64(iface.CounterResets)).Render(formatCounterReset(iface)),
+	}
+	if v.device != nil {
+		right = append(right, labelStyle.Render("Device CPU/Mem:")+valueStyle.Render(formatDeviceHealth(v.device)))
+	}
+
+	left := lipgloss.NewStyle().Background(bg).Width(infoColumnWidth).Render(strings.Join(rows, "\n"))
+	panel := lipgloss.JoinHorizontal(lipgloss.Top, left, strings.Join(right, "\n"))
+	if len(iface.Members) > 0 {
+		panel = lipgloss.JoinVertical(lipgloss.Left, panel, "", v.renderMembers(iface))
+	}
+	if len(iface.Optics) > 0 {
+		panel = lipgloss.JoinVertical(lipgloss.Left, panel, "", v.renderOptics(iface.Optics))
+	}
+	if len(v.usage) > 0 {
+		panel = lipgloss.JoinVertical(lipgloss.Left, panel, "", v.renderUsage())
+	}
+	return panel
 }
 
-// renderHelp renders a help line at the bottom of the detail view.
-func (v DetailView) renderHelp() string {
+// memberColumnWidth is the width of the rate and share columns of the LAG
+// member table.
+const memberColumnWidth = 12
+
+// renderMembers renders a link aggregate's member ports with their rates
+// and each member's share of the bundle's traffic. Shares are colored when
+// the bundle is imbalanced.
+func (v DetailView) renderMembers(iface *engine.InterfaceStats) string {
+	bg := v.theme.Base00
+	labelStyle := lipgloss.NewStyle().Foreground(v.theme.Base04).Background(bg).Width(16)
+	valueStyle := lipgloss.NewStyle().Foreground(v.theme.Base05).Background(bg)
+	dimStyle := lipgloss.NewStyle().Foreground(v.theme.Base04).Background(bg)
+	warnStyle := lipgloss.NewStyle().Foreground(v.theme.Base0A).Background(bg)
+	pad := lipgloss.NewStyle().Background(bg).Render("  ")
+
+	imbalance := "n/a"
+	pct, imbalanced := iface.Imbalance()
+	if imbalanced {
+		imbalance = fmt.Sprintf("%.0f%% above an even share", pct)
+	}
+	imbalanceStyle := valueStyle
+	if imbalanced && pct >= lagImbalanceWarn {
+		imbalanceStyle = warnStyle
+	}
+
+	header := pad + labelStyle.Render("LAG Members") + dimStyle.Render(padRight("Status", 10))
+	for _, title := range []string{"In", "Out", "Util", "Share In", "Share Out"} {
+		header += dimStyle.Render(padLeft(title, memberColumnWidth))
+	}
+	lines := []string{
+		pad + labelStyle.Render("Imbalance:") + imbalanceStyle.Render(imbalance),
+		header,
+	}
+
+	var totalIn, totalOut float64
+	up := 0
+	for _, m := range iface.Members {
+		totalIn += m.InRate
+		totalOut += m.OutRate
+		if m.Status == "up" {
+			up++
+		}
+	}
+	share := func(rate, total float64) string {
+		if total <= 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.0f%%", rate/total*100)
+	}
+	shareStyle := func(rate, total float64) lipgloss.Style {
+		even := 100 / float64(max(up, 1))
+		if imbalanced && total > 0 && rate/total*100 >= even*(1+lagImbalanceWarn/100.0) {
+			return warnStyle
+		}
+		return valueStyle
+	}
+	for _, m := range iface.Members {
+		statusStyle := warnStyle
+		switch m.Status {
+		case "up":
+			statusStyle = lipgloss.NewStyle().Foreground(v.theme.Base0B).Background(bg)
+		case "down":
+			statusStyle = lipgloss.NewStyle().Foreground(v.theme.Base08).Background(bg)
+		}
+		lines = append(lines, pad+
+			labelStyle.Render(truncate(m.Name, 15))+
+			statusStyle.Render(padRight(m.Status, 10))+
+			valueStyle.Render(padLeft(components.FormatRate(m.InRate), memberColumnWidth))+
+			valueStyle.Render(padLeft(components.FormatRate(m.OutRate), memberColumnWidth))+
+			valueStyle.Render(padLeft(fmt.Sprintf("%.1f%%", m.Utilization), memberColumnWidth))+
+			shareStyle(m.InRate, totalIn).Render(padLeft(share(m.InRate, totalIn), memberColumnWidth))+
+			shareStyle(m.OutRate, totalOut).Render(padLeft(share(m.OutRate, totalOut), memberColumnWidth)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// usageColumnWidth is the width of each column of the usage table.
+const usageColumnWidth = 11
+
+// renderUsage renders the interface's 95th percentile, average and peak
+// rates and traffic volume over each billing period, from the on-disk
+// history.
+func (v DetailView) renderUsage() string {
+	bg := v.theme.Base00
+	labelStyle := lipgloss.NewStyle().Foreground(v.theme.Base04).Background(bg).Width(16)
+	valueStyle := lipgloss.NewStyle().Foreground(v.theme.Base05).Background(bg)
+	highlightStyle := lipgloss.NewStyle().Foreground(v.theme.Base0D).Background(bg).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(v.theme.Base04).Background(bg)
+	pad := lipgloss.NewStyle().Background(bg).Render("  ")
+
+	header := pad + labelStyle.Render("Usage")
+	for _, title := range []string{"95th In", "95th Out", "Avg In", "Avg Out", "Peak In", "Peak Out", "Volume In", "Volume Out"} {
+		header += dimStyle.Render(padLeft(title, usageColumnWidth))
+	}
+	lines := []string{header}
+	for _, u := range v.usage {
+		line := pad + labelStyle.Render(truncate(u.Period.Name, 15))
+		if u.Samples == 0 {
+			lines = append(lines, line+dimStyle.Render("no stored samples"))
+			continue
+		}
+		rate := func(bps float64) string {
+			return padLeft(components.FormatRate(bps), usageColumnWidth)
+		}
+		in95, out95 := valueStyle, valueStyle
+		if u.In95 >= u.Out95 {
+			in95 = highlightStyle
+		} else {
+			out95 = highlightStyle
+		}
+		lines = append(lines, line+
+			in95.Render(rate(u.In95))+out95.Render(rate(u.Out95))+
+			valueStyle.Render(rate(u.InAvg)+rate(u.OutAvg)+rate(u.InPeak)+rate(u.OutPeak)+
+				padLeft(formatBytes(uint64(u.InBytes)), usageColumnWidth)+
+				padLeft(formatBytes(uint64(u.OutBytes)), usageColumnWidth)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// opticRows lists the transceiver sensor rows of the optics table in
+// display order.
+var opticRows = []struct {
+	role, label, format string
+}{
+	{engine.SensorRxPower, "Rx Power:", "%.1f"},
+	{engine.SensorTxPower, "Tx Power:", "%.1f"},
+	{engine.SensorTemperature, "Temperature:", "%.1f"},
+	{engine.SensorCurrent, "Bias Current:", "%.1f"},
+	{engine.SensorVoltage, "Voltage:", "%.2f"},
+}
+
+// opticValueWidth is the width of the value column of the optics table,
+// enough for four lanes of light levels.
+const opticValueWidth = 32
+
+// opticLimitWidth is the width of each threshold column of the optics table.
+const opticLimitWidth = 12
+
+// renderOptics renders a transceiver's DOM readings with the vendor alarm
+// and warning thresholds. Multi-lane optics show one value per lane on the
+// same row, each colored by its own threshold state.
+func (v DetailView) renderOptics(optics []engine.SensorReading) string {
+	bg := v.theme.Base00
+	labelStyle := lipgloss.NewStyle().Foreground(v.theme.Base04).Background(bg).Width(16)
+	dimStyle := lipgloss.NewStyle().Foreground(v.theme.Base04).Background(bg)
+	pad := lipgloss.NewStyle().Background(bg).Render("  ")
+
+	header := pad + labelStyle.Render("Optics") + dimStyle.Render(padRight("", opticValueWidth))
+	for _, title := range []string{"Low Alarm", "Low Warn", "High Warn", "High Alarm"} {
+		header += dimStyle.Render(padLeft(title, opticLimitWidth))
+	}
+	lines := []string{header}
+
+	for _, row := range opticRows {
+		var sensors []engine.SensorReading
+		for _, s := range optics {
+			if s.Role == row.role {
+				sensors = append(sensors, s)
+			}
+		}
+		if len(sensors) == 0 {
+			continue
+		}
+
+		var texts, values []string
+		for _, s := range sensors {
+			text := "n/a"
+			if s.Status != engine.SensorUnavailable {
+				text = fmt.Sprintf(row.format, s.Value)
+			}
+			texts = append(texts, text)
+			values = append(values, v.sensorStyle(s.Status).Render(text))
+		}
+		unit := " " + sensors[0].Unit
+		plain := strings.Join(texts, " ") + unit
+		valueCol := strings.Join(values, dimStyle.Render(" ")) +
+			dimStyle.Render(unit+strings.Repeat(" ", max(opticValueWidth-lipgloss.Width(plain), 1)))
+
+		line := pad + labelStyle.Render(row.label) + valueCol
+		limits := sensors[0].Thresholds
+		for _, col := range []struct {
+			high     bool
+			severity string
+		}{{false, engine.SensorAlarm}, {false, engine.SensorWarning}, {true, engine.SensorWarning}, {true, engine.SensorAlarm}} {
+			text := "-"
+			if limit, ok := sensorLimit(limits, col.high, col.severity); ok {
+				text = fmt.Sprintf(row.format, limit)
+			}
+			line += dimStyle.Render(padLeft(text, opticLimitWidth))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sensorStyle returns the style for a sensor value in the given state.
+func (v DetailView) sensorStyle(status string) lipgloss.Style {
+	fg := v.theme.Base05
+	switch status {
+	case engine.SensorWarning:
+		fg = v.theme.Base0A
+	case engine.SensorAlarm:
+		fg = v.theme.Base08
+	case engine.SensorUnavailable:
+		fg = v.theme.Base04
+	}
+	return lipgloss.NewStyle().Foreground(fg).Background(v.theme.Base00)
+}
+
+// sensorLimit returns the threshold of the given direction and severity.
+func sensorLimit(thresholds []engine.SensorThreshold, high bool, severity string) (float64, bool) {
+	for _, t := range thresholds {
+		if t.High == high && t.Severity == severity {
+			return t.Value, true
+		}
+	}
+	return 0, false
+}
+
+// renderCounterBits describes which counter width the interface is polled
+// with. 32-bit counters are highlighted since they can wrap more than once
+// per poll interval on fast links.
+func (v DetailView) renderCounterBits(bits int) string {
+	bg := v.theme.Base00
+	switch bits {
+	case 64:
+		return lipgloss.NewStyle().Foreground(v.theme.Base05).Background(bg).Render("64-bit (ifXTable)")
+	case 32:
+		return lipgloss.NewStyle().Foreground(v.theme.Base0A).Background(bg).Render("32-bit (ifTable)")
+	default:
+		return lipgloss.NewStyle().Foreground(v.theme.Base04).Background(bg).Render("unknown")
+	}
+}
+
+// formatCounterReset describes the most recent counter reset on an interface.
+func formatCounterReset(iface *engine.InterfaceStats) string {
+	if iface.CounterResets == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%s (%s), %d total",
+		iface.LastCounterReset.Format("Jan 2 15:04:05"), iface.CounterResetReason, iface.CounterResets)
+}
+
+// formatLastChange describes when an interface last changed status, as
+// of now.
+func formatLastChange(t, now time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s (%s ago)", t.Format("Jan 2 15:04:05"), formatAge(now.Sub(t)))
+}
+
+// formatDeviceHealth summarizes a device's CPU and memory utilization, e.g.
+// "12% / 43% of 2.8G".
+func formatDeviceHealth(d *engine.DeviceStats) string {
+	cpu, mem := "n/a", "n/a"
+	if d.HasCPU {
+		cpu = formatPercent(d.CPU)
+	}
+	if d.HasMemory {
+		mem = formatPercent(d.Memory)
+		if d.MemTotal > 0 {
+			mem += " of " + formatBytes(d.MemTotal)
+		}
+	}
+	return cpu + " / " + mem
+}
+
+// formatPercent formats a percentage without decimals.
+func formatPercent(pct float64) string {
+	return fmt.Sprintf("%.0f%%", pct)
+}
+
+// renderHelp renders a help line at the bottom of the detail view, with the
+// chart range and the resolution of its points.
+func (v DetailView) renderHelp(resolution time.Duration) string {
 	helpStyle := lipgloss.NewStyle().Foreground(v.theme.Base04).Background(v.theme.Base00)
 	keyStyle := lipgloss.NewStyle().Foreground(v.theme.Base0D).Background(v.theme.Base00).Bold(true)
-	return helpStyle.Render(fmt.Sprintf("  %s to go back", keyStyle.Render("[esc]")))
+	span := "live"
+	if r := chartRanges[v.rangeIdx]; r > 0 {
+		span = "last " + formatAge(r)
+		if resolution > 0 {
+			span += fmt.Sprintf(", %s averages", formatAge(resolution))
+		}
+	}
+	return helpStyle.Render(fmt.Sprintf("  %s to go back  %s chart range: %s",
+		keyStyle.Render("[esc]"), keyStyle.Render("[t]"), span))
 }
 
 // extractRateData pulls InRate, OutRate, and Timestamp slices from the interface history.
@@ -229,6 +650,88 @@ func (v DetailView) extractRateData() (inData, outData []float64, timestamps []t
 	return inData, outData, timestamps
 }
 
+// extractRangeData pulls the average In/Out bit and packet rates over the
+// selected chart range from the interface history, as at most points
+// points, along with the time each point covers.
+func (v DetailView) extractRangeData(points int) (inData, outData, inPPS, outPPS []float64, timestamps []time.Time, resolution time.Duration) {
+	if v.ifaceStats == nil {
+		return nil, nil, nil, nil, nil, 0
+	}
+	now := v.clock()
+	buckets := v.ifaceStats.RateRange(now.Add(-chartRanges[v.rangeIdx]), now, points)
+	if len(buckets) == 0 {
+		return nil, nil, nil, nil, nil, 0
+	}
+
+	inData = make([]float64, len(buckets))
+	outData = make([]float64, len(buckets))
+	inPPS = make([]float64, len(buckets))
+	outPPS = make([]float64, len(buckets))
+	timestamps = make([]time.Time, len(buckets))
+	for i, b := range buckets {
+		inData[i] = b.InRate.Avg
+		outData[i] = b.OutRate.Avg
+		inPPS[i] = b.InPPS.Avg
+		outPPS[i] = b.OutPPS.Avg
+		timestamps[i] = b.Start
+	}
+	return inData, outData, inPPS, outPPS, timestamps, buckets[0].Width
+}
+
+// extractPacketData pulls total In/Out packet rates from the interface history.
+func (v DetailView) extractPacketData() (inData, outData []float64) {
+	if v.ifaceStats == nil || v.ifaceStats.History == nil {
+		return nil, nil
+	}
+
+	samples := v.ifaceStats.History.All()
+	inData = make([]float64, len(samples))
+	outData = make([]float64, len(samples))
+	for i, s := range samples {
+		inData[i] = s.InPPS.Total()
+		outData[i] = s.OutPPS.Total()
+	}
+	return inData, outData
+}
+
+// hasDeviceHistory reports whether there is device CPU or memory history to
+// chart.
+func (v DetailView) hasDeviceHistory() bool {
+	return v.device != nil && (v.device.HasCPU || v.device.HasMemory) &&
+		v.device.History != nil && v.device.History.Len() > 0
+}
+
+// extractDeviceData pulls CPU and memory utilization from the device
+// history. A series the device does not report is left empty.
+func (v DetailView) extractDeviceData() (cpu, mem []float64, timestamps []time.Time) {
+	if v.device == nil || v.device.History == nil {
+		return nil, nil, nil
+	}
+
+	samples := v.device.History.All()
+	timestamps = make([]time.Time, len(samples))
+	for i, s := range samples {
+		if v.device.HasCPU {
+			cpu = append(cpu, s.CPU)
+		}
+		if v.device.HasMemory {
+			mem = append(mem, s.Memory)
+		}
+		timestamps[i] = s.Timestamp
+	}
+	return cpu, mem, timestamps
+}
+
+// formatPacketRates renders a packet rate with its multicast and broadcast
+// share, e.g. "1.2K (m 10 / b 3.0)".
+func formatPacketRates(r engine.PacketRates) string {
+	return fmt.Sprintf("%s (m %s / b %s)",
+		components.FormatCount(r.Total()),
+		components.FormatCount(r.Multicast),
+		components.FormatCount(r.Broadcast),
+	)
+}
+
 // formatSpeed converts an interface speed in Mbps to a human-readable string.
 func formatSpeed(speedMbps uint64) string {
 	switch {
@@ -0,0 +1,251 @@
+package views
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tonhe/flo/internal/engine"
+	"github.com/tonhe/flo/tui/keys"
+	"github.com/tonhe/flo/tui/styles"
+)
+
+// Column widths of the device view's sensor and event tables.
+const (
+	colSensorName  = 28
+	colSensorRole  = 14
+	colSensorValue = 12
+	colEventTime   = 16
+	colEventKind   = 8
+)
+
+// DeviceView is a scrollable per-target panel showing device health,
+// chassis sensors, power supplies and fans, and the target's recent state
+// changes.
+type DeviceView struct {
+	theme    styles.Theme
+	sty      *styles.Styles
+	host     string
+	target   *engine.TargetStats
+	events   []engine.Event // the target's events, newest first
+	width    int
+	height   int
+	offset   int // scroll offset in lines
+	maxLines int // number of content lines at the last render
+}
+
+// NewDeviceView creates a new DeviceView with the given theme.
+func NewDeviceView(theme styles.Theme) DeviceView {
+	return DeviceView{
+		theme: theme,
+		sty:   styles.NewStyles(theme),
+	}
+}
+
+// SetHost selects the target to show and scrolls back to the top.
+func (v *DeviceView) SetHost(host string) {
+	v.host = host
+	v.offset = 0
+}
+
+// SetSnapshot updates the view with the selected target's latest data.
+func (v *DeviceView) SetSnapshot(snap *engine.DashboardSnapshot) {
+	v.target = nil
+	v.events = nil
+	if snap == nil {
+		return
+	}
+	for gi := range snap.Groups {
+		for ti := range snap.Groups[gi].Targets {
+			if t := &snap.Groups[gi].Targets[ti]; t.Host == v.host {
+				v.target = t
+			}
+		}
+	}
+	for _, e := range slices.Backward(snap.Events) {
+		if e.Host == v.host {
+			v.events = append(v.events, e)
+		}
+	}
+	v.maxLines = len(v.lines())
+}
+
+// SetSize updates the available dimensions for the view.
+func (v *DeviceView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+// Update handles key messages for scrolling. The third return value
+// indicates whether the user wants to go back (Esc pressed).
+func (v DeviceView) Update(msg tea.Msg) (DeviceView, tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.DefaultKeyMap.Escape):
+			return v, nil, true
+		case key.Matches(msg, keys.DefaultKeyMap.Up):
+			if v.offset > 0 {
+				v.offset--
+			}
+		case key.Matches(msg, keys.DefaultKeyMap.Down):
+			if v.offset < v.maxLines-v.visibleLines() {
+				v.offset++
+			}
+		}
+	}
+	return v, nil, false
+}
+
+// visibleLines returns how many content lines fit above the help line.
+func (v DeviceView) visibleLines() int {
+	return max(v.height-1, 1)
+}
+
+// View renders the visible part of the device panel and a help line.
+func (v DeviceView) View() string {
+	if v.target == nil {
+		msg := lipgloss.NewStyle().
+			Foreground(v.theme.Base04).
+			Align(lipgloss.Center).
+			Render("No device selected")
+		return lipgloss.Place(v.width, v.height, lipgloss.Center, lipgloss.Center, msg,
+			lipgloss.WithWhitespaceBackground(v.theme.Base00))
+	}
+
+	lines := v.lines()
+	visible := v.visibleLines()
+	offset := min(v.offset, max(len(lines)-visible, 0))
+	lines = lines[offset:min(offset+visible, len(lines))]
+	for len(lines) < visible {
+		lines = append(lines, "")
+	}
+
+	helpStyle := lipgloss.NewStyle().Foreground(v.theme.Base04).Background(v.theme.Base00)
+	keyStyle := lipgloss.NewStyle().Foreground(v.theme.Base0D).Background(v.theme.Base00).Bold(true)
+	help := helpStyle.Render(fmt.Sprintf("  %s scroll  %s to go back",
+		keyStyle.Render("[up/down]"), keyStyle.Render("[esc]")))
+	return strings.Join(append(lines, help), "\n")
+}
+
+// lines renders the whole panel, one string per line.
+func (v DeviceView) lines() []string {
+	t := v.target
+	if t == nil {
+		return nil
+	}
+	bg := v.theme.Base00
+	labelStyle := lipgloss.NewStyle().Foreground(v.theme.Base04).Background(bg).Width(16)
+	valueStyle := lipgloss.NewStyle().Foreground(v.theme.Base05).Background(bg)
+	highlightStyle := lipgloss.NewStyle().Foreground(v.theme.Base0D).Background(bg).Bold(true)
+	sectionStyle := lipgloss.NewStyle().Foreground(v.theme.Base0E).Background(bg).Bold(true)
+	dimStyle := v.sty.TableCellDim
+	pad := lipgloss.NewStyle().Background(bg).Render("  ")
+
+	healthStyle := v.sty.StatusUp
+	switch t.Health {
+	case engine.TargetDegraded:
+		healthStyle = v.sty.StatusWarn
+	case engine.TargetUnreachable:
+		healthStyle = v.sty.StatusDown
+	}
+
+	lines := []string{
+		"",
+		pad + labelStyle.Render("Device:") + highlightStyle.Render(t.Label),
+		pad + labelStyle.Render("Host:") + valueStyle.Render(t.Host),
+		pad + labelStyle.Render("Health:") + healthStyle.Render(t.Health.String()),
+	}
+	if d := &t.Device; d.Available() {
+		lines = append(lines, pad+labelStyle.Render("CPU/Memory:")+valueStyle.Render(formatDeviceHealth(d)))
+		for _, s := range d.Storage {
+			lines = append(lines, pad+labelStyle.Render(truncate(strings.TrimSuffix(s.Descr, ":"), 14)+":")+
+				valueStyle.Render(fmt.Sprintf("%s of %s", formatPercent(s.Percent()), formatBytes(s.Size))))
+		}
+	}
+
+	lines = append(lines, "", pad+sectionStyle.Render("Sensors"))
+	if len(t.Sensors) == 0 {
+		lines = append(lines, pad+dimStyle.Render("No chassis sensors reported"))
+	} else {
+		lines = append(lines, pad+dimStyle.Render(padRight("Name", colSensorName)+
+			padRight("Type", colSensorRole)+padLeft("Value", colSensorValue)+"  Status"))
+		for _, s := range t.Sensors {
+			value := "-"
+			switch {
+			case s.Status == engine.SensorUnavailable:
+				value = "n/a"
+			case s.Unit != "":
+				value = formatSensorValue(s)
+			}
+			status := s.Status
+			if s.State != "" {
+				status += " (" + s.State + ")"
+			}
+			lines = append(lines, pad+
+				valueStyle.Render(padRight(truncate(s.Name, colSensorName-2), colSensorName))+
+				dimStyle.Render(padRight(s.Role, colSensorRole))+
+				v.sensorStyle(s.Status).Render(strings.Repeat(" ", max(colSensorValue-lipgloss.Width(value), 0))+value+"  "+status))
+		}
+	}
+
+	lines = append(lines, "", pad+sectionStyle.Render("Recent Events"))
+	if len(v.events) == 0 {
+		lines = append(lines, pad+dimStyle.Render("No state changes since the dashboard started"))
+	}
+	for _, e := range v.events {
+		kind := "iface"
+		if e.Kind == engine.EventSensorState {
+			kind = "sensor"
+		}
+		change := fmt.Sprintf("%s: %s -> %s", e.Subject, e.From, e.To)
+		if e.Detail != "" {
+			change += " (" + e.Detail + ")"
+		}
+		lines = append(lines, pad+
+			dimStyle.Render(padRight(e.Time.Format("Jan 2 15:04:05"), colEventTime)+padRight(kind, colEventKind))+
+			v.eventStyle(e).Render(change))
+	}
+	return lines
+}
+
+// formatSensorValue formats a sensor reading with its unit.
+func formatSensorValue(s engine.SensorReading) string {
+	switch s.Unit {
+	case "RPM", "W":
+		return fmt.Sprintf("%.0f %s", s.Value, s.Unit)
+	case "V", "mA":
+		return fmt.Sprintf("%.2f %s", s.Value, s.Unit)
+	}
+	return fmt.Sprintf("%.1f %s", s.Value, s.Unit)
+}
+
+// sensorStyle returns the style for a sensor in the given state.
+func (v DeviceView) sensorStyle(status string) lipgloss.Style {
+	switch status {
+	case engine.SensorOK:
+		return v.sty.StatusUp
+	case engine.SensorWarning:
+		return v.sty.StatusWarn
+	case engine.SensorAlarm:
+		return v.sty.StatusDown
+	}
+	return v.sty.TableCellDim
+}
+
+// eventStyle colors an event by the state it changed to.
+func (v DeviceView) eventStyle(e engine.Event) lipgloss.Style {
+	if e.Kind == engine.EventSensorState {
+		return v.sensorStyle(e.To)
+	}
+	switch e.To {
+	case "up":
+		return v.sty.StatusUp
+	case "down":
+		return v.sty.StatusDown
+	}
+	return v.sty.StatusWarn
+}
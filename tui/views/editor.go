@@ -74,6 +74,8 @@ type EditorView struct {
 	dashName        string
 	defaultIdentity string
 	intervalStr     string
+	columns         []string              // optional columns, carried over unedited
+	aggregates      []dashboard.Aggregate // aggregate rows, carried over unedited
 	targets         []dashboard.Target
 	editPath        string
 
@@ -130,12 +132,9 @@ func (e *EditorView) LoadDashboard(dash *dashboard.Dashboard, path string) {
 	e.dashName = dash.Name
 	e.defaultIdentity = dash.DefaultIdentity
 	e.intervalStr = dash.Interval.String()
-	e.targets = nil
-	for _, g := range dash.Groups {
-		for _, t := range g.Targets {
-			e.targets = append(e.targets, t)
-		}
-	}
+	e.columns = dash.Columns
+	e.aggregates = dash.Aggregates
+	e.targets = dash.FlatTargets()
 }
 
 // SetSize updates the available dimensions for the editor view.
@@ -598,9 +597,11 @@ func (e *EditorView) save() error {
 		DefaultIdentity: e.defaultIdentity,
 		Interval:        interval,
 		MaxHistory:      360,
+		Columns:         e.columns,
 		Groups: []dashboard.Group{
 			{Name: "Default", Targets: targets},
 		},
+		Aggregates: e.aggregates,
 	}
 	path := e.editPath
 	if path == "" {
@@ -88,11 +88,14 @@ func (v HelpView) View() string {
 	lines = append(lines, bindingLine("q", "Quit"))
 	lines = append(lines, bindingLine("Up / Down", "Navigate interfaces"))
 	lines = append(lines, bindingLine("Enter", "Detail view"))
+	lines = append(lines, bindingLine("Right / Left", "Expand / collapse LAG"))
+	lines = append(lines, bindingLine("c", "Device sensors & events"))
 	lines = append(lines, bindingLine("d", "Dashboard switcher"))
 	lines = append(lines, bindingLine("e", "Edit active dashboard"))
 	lines = append(lines, bindingLine("i", "Identity manager"))
 	lines = append(lines, bindingLine("s", "Settings"))
 	lines = append(lines, bindingLine("r", "Force refresh"))
+	lines = append(lines, bindingLine("R", "Start / stop recording"))
 	lines = append(lines, "")
 
 	// Switcher section
@@ -106,6 +109,13 @@ func (v HelpView) View() string {
 
 	// Detail View section
 	lines = append(lines, sectionStyle.Render("Detail View"))
+	lines = append(lines, bindingLine("t", "Cycle chart range"))
+	lines = append(lines, bindingLine("Esc", "Back to dashboard"))
+	lines = append(lines, "")
+
+	// Device View section
+	lines = append(lines, sectionStyle.Render("Device View"))
+	lines = append(lines, bindingLine("Up / Down", "Scroll"))
 	lines = append(lines, bindingLine("Esc", "Back to dashboard"))
 	lines = append(lines, "")
 
@@ -2,7 +2,9 @@ package views
 
 import (
 	"fmt"
+	"maps"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -63,10 +65,8 @@ func NewSwitcherView(theme styles.Theme) SwitcherView {
 func (v *SwitcherView) Refresh(dashDir string, mgr *engine.Manager) {
 	v.items = nil
 
-	names, err := dashboard.ListDashboards(dashDir)
-	if err != nil {
-		return
-	}
+	// Without a dashboards directory, only running engines are listed.
+	names, _ := dashboard.ListDashboards(dashDir)
 
 	runningEngines := mgr.TryListEngines()
 	runningMap := make(map[string]engine.EngineInfo, len(runningEngines))
@@ -82,9 +82,14 @@ func (v *SwitcherView) Refresh(dashDir string, mgr *engine.Manager) {
 		if info, ok := runningMap[name]; ok {
 			item.Running = true
 			item.Info = info
+			delete(runningMap, name)
 		}
 		v.items = append(v.items, item)
 	}
+	// Engines without a dashboard file, such as replays of a recording.
+	for _, name := range slices.Sorted(maps.Keys(runningMap)) {
+		v.items = append(v.items, SwitcherItem{Name: name, Running: true, Info: runningMap[name]})
+	}
 
 	// Clamp cursor
 	if v.cursor >= len(v.items) {
@@ -181,7 +186,8 @@ func (v SwitcherView) View() string {
 		lines = append(lines, dimStyle.Render("Press [n] to create one."))
 	} else {
 		for i, item := range v.items {
-			line := v.renderItem(item, i == v.cursor, innerWidth)
+			// Width below includes the horizontal padding
+			line := v.renderItem(item, i == v.cursor, innerWidth-4)
 			lines = append(lines, line)
 		}
 	}
@@ -244,26 +250,15 @@ func (v SwitcherView) renderItem(item SwitcherItem, selected bool, width int) st
 	}
 
 	// Status indicator and text
-	var statusStr string
+	statusText, statusColor := "o stopped", v.theme.Base03
 	if item.Running {
-		dotStyle := lipgloss.NewStyle().Foreground(v.theme.Base0B)
-		liveStyle := lipgloss.NewStyle().Foreground(v.theme.Base0B)
+		statusText, statusColor = engineStatus(item.Info, v.theme)
+	}
+	statusStr := lipgloss.NewStyle().Foreground(statusColor).Render(statusText)
+	if item.Running && item.Info.State != engine.EngineStarting && item.Info.State != engine.EngineStopping {
 		pollStr := fmt.Sprintf("(%d)", item.Info.PollCount)
-		pollStyle := lipgloss.NewStyle().Foreground(v.theme.Base04)
-		statusStr = fmt.Sprintf(
-			"%s %s  %s",
-			dotStyle.Render("*"),
-			liveStyle.Render("LIVE"),
-			pollStyle.Render(pollStr),
-		)
-	} else {
-		dotStyle := lipgloss.NewStyle().Foreground(v.theme.Base03)
-		stoppedStyle := lipgloss.NewStyle().Foreground(v.theme.Base03)
-		statusStr = fmt.Sprintf(
-			"%s %s",
-			dotStyle.Render("o"),
-			stoppedStyle.Render("stopped"),
-		)
+		statusText += "  " + pollStr
+		statusStr += "  " + lipgloss.NewStyle().Foreground(v.theme.Base04).Render(pollStr)
 	}
 
 	// Build the line: cursor + name + padding + status
@@ -272,13 +267,7 @@ func (v SwitcherView) renderItem(item SwitcherItem, selected bool, width int) st
 
 	// Calculate padding to right-align the status
 	nameLen := len(cursor) + len(item.Name)
-	// Approximate status plain text length
-	statusPlainLen := 0
-	if item.Running {
-		statusPlainLen = len(fmt.Sprintf("* LIVE  (%d)", item.Info.PollCount))
-	} else {
-		statusPlainLen = len("o stopped")
-	}
+	statusPlainLen := len(statusText)
 
 	padLen := width - nameLen - statusPlainLen
 	if padLen < 2 {
@@ -286,5 +275,28 @@ func (v SwitcherView) renderItem(item SwitcherItem, selected bool, width int) st
 	}
 	padding := strings.Repeat(" ", padLen)
 
-	return cursorText + nameText + padding + statusStr
+	line := cursorText + nameText + padding + statusStr
+	if selected && item.Running && item.Info.Reason != "" {
+		// Say why the selected engine is degraded or in error
+		reasonStyle := lipgloss.NewStyle().Foreground(v.theme.Base04)
+		line += "\n" + reasonStyle.Render("    "+truncate(item.Info.Reason, width-4))
+	}
+	return line
+}
+
+// engineStatus returns the switcher's status label for a running engine
+// and the color to show it in.
+func engineStatus(info engine.EngineInfo, theme styles.Theme) (string, lipgloss.Color) {
+	switch info.State {
+	case engine.EngineStarting:
+		return "* STARTING", theme.Base0A
+	case engine.EngineDegraded:
+		return "* DEGRADED", theme.Base0A
+	case engine.EngineError:
+		return "* ERROR", theme.Base08
+	case engine.EngineStopping:
+		return "o STOPPING", theme.Base03
+	default:
+		return "* LIVE", theme.Base0B
+	}
 }